@@ -0,0 +1,95 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/snapshot"
+)
+
+func main() {
+	var file, root, dir string
+
+	flag.StringVar(&file, "f", "device-snapshot.tar.gz", "Snapshot file, - for stdout/stdin")
+	flag.StringVar(&root, "root", "/", "Root of the captured sysfs and devfs")
+	flag.StringVar(&dir, "d", "", "Directory to restore the snapshot to")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal("Please provide command: capture or restore")
+	}
+
+	var err error
+
+	switch flag.Arg(0) {
+	case "capture":
+		err = capture(file, root)
+	case "restore":
+		err = restore(file, dir)
+	default:
+		err = errors.Errorf("unknown command %q", flag.Arg(0))
+	}
+
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+}
+
+func capture(file, root string) (err error) {
+	var w io.Writer = os.Stdout
+
+	if file != "-" {
+		f, createErr := os.Create(file)
+		if createErr != nil {
+			return errors.WithStack(createErr)
+		}
+
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = errors.WithStack(closeErr)
+			}
+		}()
+
+		w = f
+	}
+
+	return snapshot.Capture(w, root, snapshot.DefaultPaths)
+}
+
+func restore(file, dir string) error {
+	if dir == "" {
+		return errors.New("restore directory not given, use -d")
+	}
+
+	var r io.Reader = os.Stdin
+
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	return snapshot.Restore(r, dir)
+}
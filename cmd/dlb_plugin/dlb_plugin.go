@@ -15,15 +15,21 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
+	"syscall"
 	"time"
 
 	"k8s.io/klog/v2"
 
 	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/pluginutils"
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/uevent"
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
@@ -33,29 +39,26 @@ const (
 	deviceTypePF        = "pf"
 	deviceTypeVF        = "vf"
 	sysfsDir            = "/sys/class/dlb2"
-	// Period of device scans.
-	scanPeriod = 5 * time.Second
+	// Period of device rescans when no dlb2 uevents arrive.
+	scanPeriod = 30 * time.Second
 )
 
 type DevicePlugin struct {
-	scanTicker *time.Ticker
-	scanDone   chan bool
-
 	dlbDeviceFilePathReg string
 	sysfsDir             string
+	allocation.Allocator
 }
 
 func NewDevicePlugin(dlbDeviceFilePathReg string, sysfsDir string) *DevicePlugin {
 	return &DevicePlugin{
 		dlbDeviceFilePathReg: dlbDeviceFilePathReg,
 		sysfsDir:             sysfsDir,
-		scanTicker:           time.NewTicker(scanPeriod),
-		scanDone:             make(chan bool, 1), // buffered as we may send to it before Scan starts receiving from it
 	}
 }
 
-func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *DevicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
+	trigger := uevent.NewTrigger(scanPeriod, "dlb2")
+	defer trigger.Stop()
 
 	var prevDevTree dpapi.DeviceTree
 
@@ -70,9 +73,9 @@ func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
 		notifier.Notify(devTree)
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-trigger.C:
 		}
 	}
 }
@@ -93,6 +96,8 @@ func (dp *DevicePlugin) scan() dpapi.DeviceTree {
 		sysfsDev := filepath.Join(dp.sysfsDir, filepath.Base(file))
 		sriovNumVFs := pluginutils.GetSriovNumVFs(sysfsDev)
 
+		setDeviceAttributes(&deviceInfo, sysfsDev)
+
 		switch sriovNumVFs {
 		case "0":
 			devTree.AddDevice(deviceTypePF, file, deviceInfo)
@@ -106,11 +111,40 @@ func (dp *DevicePlugin) scan() dpapi.DeviceTree {
 	return devTree
 }
 
+// setDeviceAttributes sets the PCI address and, for VFs, the physical
+// function of a DLB device to deviceInfo.
+func setDeviceAttributes(deviceInfo *dpapi.DeviceInfo, sysfsDev string) {
+	pciDev, err := filepath.EvalSymlinks(filepath.Join(sysfsDev, "device"))
+	if err != nil {
+		return
+	}
+
+	deviceInfo.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute(filepath.Base(pciDev)))
+
+	if pfDev, err := filepath.EvalSymlinks(filepath.Join(pciDev, "physfn")); err == nil {
+		deviceInfo.SetAttribute(dpapi.AttributePhysicalFunction, dpapi.StringAttribute(filepath.Base(pfDev)))
+	}
+}
+
 func main() {
-	flag.Parse()
+	prefix := flag.String("prefix", "", "Prefix for devfs & sysfs paths")
+	policy := flag.String(allocation.FlagName, allocation.None, allocation.Usage())
+	dpapi.ParseFlags()
 	klog.V(1).Infof("DLB device plugin started")
 
-	plugin := NewDevicePlugin(dlbDeviceFilePathRE, sysfsDir)
+	plugin := NewDevicePlugin(*prefix+dlbDeviceFilePathRE, *prefix+sysfsDir)
+	if err := plugin.SetPolicy(*policy); err != nil {
+		klog.Error(err)
+		os.Exit(1)
+	}
 	manager := dpapi.NewManager(namespace, plugin)
-	manager.Run()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
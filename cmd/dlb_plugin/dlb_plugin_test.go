@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path"
@@ -22,6 +23,7 @@ import (
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
 	"github.com/pkg/errors"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
 func init() {
@@ -30,14 +32,14 @@ func init() {
 
 // mockNotifier implements Notifier interface.
 type mockNotifier struct {
-	scanDone chan bool
+	cancel   context.CancelFunc
 	devCount int
 }
 
 // Notify stops plugin Scan.
 func (n *mockNotifier) Notify(newDeviceTree dpapi.DeviceTree) {
 	n.devCount = len(newDeviceTree[deviceTypePF]) + len(newDeviceTree[deviceTypeVF])
-	n.scanDone <- true
+	n.cancel()
 }
 
 func createTestFiles(devfs string, devfsdirs []string, sysfs string, pfDevs []string, sriovnumvfs []string) error {
@@ -131,11 +133,13 @@ func TestScan(t *testing.T) {
 			devfs = path.Join(devfs, "dlb*")
 			plugin := NewDevicePlugin(devfs, sysfs)
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			notifier := &mockNotifier{
-				scanDone: plugin.scanDone,
+				cancel: cancel,
 			}
 
-			err = plugin.Scan(notifier)
+			err = plugin.Scan(ctx, notifier)
 			// Scans in DLB plugin never fail
 			if err != nil {
 				t.Errorf("unexpected error: %+v", err)
@@ -147,3 +151,40 @@ func TestScan(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDeviceAttributes(t *testing.T) {
+	root := t.TempDir()
+	pf := path.Join(root, "devices", "0000:6d:00.0")
+	vf := path.Join(root, "devices", "0000:6d:00.1")
+
+	for _, dir := range []string{pf, vf, path.Join(root, "dlb0"), path.Join(root, "dlb1")} {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for link, target := range map[string]string{
+		path.Join(root, "dlb0", "device"): pf,
+		path.Join(root, "dlb1", "device"): vf,
+		path.Join(vf, "physfn"):           pf,
+	} {
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for dev, expected := range map[string][2]string{
+		"dlb0": {"0000:6d:00.0", ""},
+		"dlb1": {"0000:6d:00.1", "0000:6d:00.0"},
+	} {
+		info := dpapi.NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil)
+		setDeviceAttributes(&info, path.Join(root, dev))
+
+		addr, _ := info.Attributes().StringValue(dpapi.AttributePCIAddress)
+		pf, _ := info.Attributes().StringValue(dpapi.AttributePhysicalFunction)
+
+		if addr != expected[0] || pf != expected[1] {
+			t.Errorf("%s: expected %v, got %s and %s", dev, expected, addr, pf)
+		}
+	}
+}
@@ -15,10 +15,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
 	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/idxd"
 	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/vfio"
 
@@ -29,7 +33,8 @@ const (
 	// Device plugin settings.
 	namespace = "dsa.intel.com"
 	// Device directories.
-	devDir = "/dev/dsa"
+	devDir     = "/dev/dsa"
+	charDevDir = "/dev/char"
 	// Glob pattern for the state sysfs entry.
 	statePattern = "/sys/bus/dsa/devices/dsa*/wq*/state"
 
@@ -38,13 +43,18 @@ const (
 
 func main() {
 	var (
+		prefix       string
 		sharedDevNum int
 		plugin       dpapi.Scanner
+		allocator    *allocation.Allocator
 	)
 
+	flag.StringVar(&prefix, "prefix", "", "Prefix for devfs & sysfs paths")
+
 	flag.IntVar(&sharedDevNum, "shared-dev-num", 1, "number of containers sharing the same work queue")
 	dsaDriver := flag.String("driver", "idxd", "Device driver used for the DSA devices")
-	flag.Parse()
+	policy := flag.String(allocation.FlagName, allocation.None, allocation.Usage())
+	dpapi.ParseFlags()
 
 	if sharedDevNum < 1 {
 		klog.Warning("The number of containers sharing the same work queue must be greater than zero.")
@@ -53,7 +63,9 @@ func main() {
 
 	switch *dsaDriver {
 	case "idxd":
-		plugin = idxd.NewDevicePlugin(statePattern, devDir, sharedDevNum)
+		idxdPlugin := idxd.NewDevicePlugin(prefix+statePattern, prefix+devDir, prefix+charDevDir, sharedDevNum)
+		allocator = &idxdPlugin.Allocator
+		plugin = idxdPlugin
 	case "vfio-pci":
 		dsaDeviceIDs := vfio.DeviceIDSet{
 			"0x0b25": {},
@@ -63,13 +75,27 @@ func main() {
 		if sharedDevNum > 1 {
 			klog.Warning("shared-dev-num setting ignored when using -driver=vfio-pci.")
 		}
-		plugin = vfio.NewDevicePlugin(pciDevicesDir, dsaDeviceIDs)
+		vfioPlugin := vfio.NewDevicePlugin(prefix+pciDevicesDir, dsaDeviceIDs)
+		allocator = &vfioPlugin.Allocator
+		plugin = vfioPlugin
 	default:
 		klog.Warningf("Unsupported DSA driver: %s. Use either idxd or vfio-pci.", *dsaDriver)
 		os.Exit(1)
 	}
 
+	if err := allocator.SetPolicy(*policy); err != nil {
+		klog.Warning(err)
+		os.Exit(1)
+	}
+
 	manager := dpapi.NewManager(namespace, plugin)
 
-	manager.Run()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
@@ -15,11 +15,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
 	"regexp"
+	"syscall"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -206,9 +209,6 @@ type devicePlugin struct {
 	getDevTree getDevTreeFunc
 	newPort    newPortFunc
 
-	scanTicker *time.Ticker
-	scanDone   chan bool
-
 	annotationValue string
 }
 
@@ -238,8 +238,6 @@ func newDevicePlugin(mode string, rootPath string) (*devicePlugin, error) {
 	}
 
 	dp.newPort = fpga.NewPort
-	dp.scanTicker = time.NewTicker(scanPeriod)
-	dp.scanDone = make(chan bool, 1) // buffered as we may send to it before Scan starts receiving from it
 
 	return dp, nil
 }
@@ -258,8 +256,9 @@ func (dp *devicePlugin) PostAllocate(response *pluginapi.AllocateResponse) error
 }
 
 // Scan starts scanning FPGA devices on the host.
-func (dp *devicePlugin) Scan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *devicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
+	ticker := time.NewTicker(scanPeriod)
+	defer ticker.Stop()
 
 	for {
 		devTree, err := dp.scanFPGAs()
@@ -270,9 +269,9 @@ func (dp *devicePlugin) Scan(notifier dpapi.Notifier) error {
 		notifier.Notify(devTree)
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-ticker.C:
 		}
 	}
 }
@@ -376,14 +375,16 @@ func main() {
 		kubeconfig string
 		master     string
 		nodename   string
+		prefix     string
 	)
 
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "absolute path to the kubeconfig file")
 	flag.StringVar(&master, "master", "", "master url")
+	flag.StringVar(&prefix, "prefix", "", "Prefix for devfs & sysfs paths")
 	flag.StringVar(&nodename, "node-name", os.Getenv("NODE_NAME"), "node name in the cluster to query mode annotation from")
 	flag.StringVar(&mode, "mode", string(afMode),
 		fmt.Sprintf("device plugin mode: '%s' (default), '%s' or '%s'", afMode, regionMode, regionDevelMode))
-	flag.Parse()
+	dpapi.ParseFlags()
 
 	nodeMode, err := getModeOverrideFromCluster(nodename, kubeconfig, master, mode)
 	if err != nil {
@@ -398,12 +399,20 @@ func main() {
 		modeMessage = ""
 	}
 
-	plugin, err := newDevicePlugin(mode, "")
+	plugin, err := newDevicePlugin(mode, prefix)
 	if err != nil {
 		klog.Fatalf("%+v", err)
 	}
 
 	klog.V(1).Infof("FPGA device plugin (%s) started in %s mode%s", plugin.name, mode, modeMessage)
 	manager := dpapi.NewManager(namespace, plugin)
-	manager.Run()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path"
@@ -177,12 +178,12 @@ func TestNewDevicePlugin(t *testing.T) {
 
 // fakeNotifier implements Notifier interface.
 type fakeNotifier struct {
-	scanDone chan bool
+	cancel context.CancelFunc
 }
 
 // Notify stops plugin Scan.
 func (n *fakeNotifier) Notify(newDeviceTree dpapi.DeviceTree) {
-	n.scanDone <- true
+	n.cancel()
 }
 
 func TestScan(t *testing.T) {
@@ -249,7 +250,9 @@ func TestScan(t *testing.T) {
 
 			plugin.newPort = tc.newPort
 
-			err = plugin.Scan(&fakeNotifier{plugin.scanDone})
+			ctx, cancel := context.WithCancel(context.Background())
+
+			err = plugin.Scan(ctx, &fakeNotifier{cancel})
 
 			if err != nil {
 				t.Errorf("unexpected error: %+v", err)
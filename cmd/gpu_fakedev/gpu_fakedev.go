@@ -24,6 +24,12 @@
 // sys/class/drm/cardX/device/drm/cardX/
 // sys/class/drm/cardX/device/drm/renderD1XX/
 // sys/class/drm/cardX/device/numa_node (Numa node index[1], number)
+// sys/class/drm/cardX/device/hwmon/hwmonX/temp1_input (temperature, millidegrees Celsius)
+// sys/class/drm/cardX/device/hwmon/hwmonX/temp1_label (pkg)
+// sys/class/drm/cardX/gt/gtY/error_counter/correctable_eu_grf (RAS error count, 0)
+// sys/class/drm/cardX/gt/gtY/error_counter/fatal_eu_grf (RAS error count, 0)
+// sys/kernel/debug/dri/X/i915_capabilities (capabilities of the config)
+// sys/kernel/debug/dri/X/i915_wedged (0)
 // [1] indexing these: /sys/devices/system/node/nodeX/
 //---------------------------------------------------------------
 // devfs SPECIFICATION
@@ -44,9 +50,10 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 
 	"golang.org/x/sys/unix"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/gpufake"
 )
 
 const (
@@ -63,8 +70,7 @@ const (
 	devNullMinor = 3
 	devNullType  = unix.S_IFCHR
 	// GPU connectivity.
-	maxK8sLabelSize = 63
-	fullyConnected  = "FULL"
+	fullyConnected = "FULL"
 )
 
 var verbose bool
@@ -76,6 +82,7 @@ type genOptions struct {
 	TilesPerDev  int               // per-device tile count
 	DevMemSize   int               // available per-device device-local memory, in bytes
 	DevsPerNode  int               // How many devices per Numa node
+	DevTemp      int               // device temperature in hwmon, in degrees Celsius
 	VfsPerPf     int               // How many SR-IOV VFs per PF
 	// fields for counting what was generated
 	files int
@@ -143,12 +150,21 @@ func addSysfsDriTree(root string, opts *genOptions, i int) error {
 		opts.files++
 	}
 
-	for tile := 0; tile < opts.TilesPerDev; tile++ {
-		path := filepath.Join(base, "gt", fmt.Sprintf("gt%d", tile))
-		if err := os.MkdirAll(path, dirMode); err != nil {
+	return nil
+}
+
+// addHealthFiles adds the sysfs and debugfs health files of the card.
+func addHealthFiles(root string, opts *genOptions, i int) error {
+	for name, value := range gpufake.HealthFiles(cardBase+i, opts.TilesPerDev, opts.DevTemp) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
 			return err
 		}
-		opts.dirs++
+
+		if err := os.WriteFile(path, []byte(value), fileMode); err != nil {
+			return err
+		}
+		opts.files++
 	}
 
 	return nil
@@ -284,6 +300,10 @@ func generateDriFiles(opts genOptions) {
 			log.Fatalf("ERROR: dev-%d debugfs tree generation failed: %v", i, err)
 		}
 
+		if err := addHealthFiles(sysfsPath, &opts, i); err != nil {
+			log.Fatalf("ERROR: dev-%d health files generation failed: %v", i, err)
+		}
+
 		if err := addDevfsDriTree(devfsPath, &opts, i); err != nil {
 			log.Fatalf("ERROR: dev-%d devfs tree generation failed: %v", i, err)
 		}
@@ -303,75 +323,28 @@ func makeXelinkSideCar(opts genOptions) {
 	tiles := opts.TilesPerDev
 	connections := opts.Capabilities["connections"]
 
-	if topology != fullyConnected {
-		saveSideCarFile(connections)
-	} else {
-		saveSideCarFile(buildConnectionList(gpus, tiles))
-	}
-
-	log.Printf("XELINK: generated xelink sidecar label file, using (GPUs: %d, Tiles: %d, Topology: %s)", gpus, tiles, topology)
-}
-
-func buildConnectionList(gpus, tiles int) string {
-	var nodes = make([]string, 0, tiles*gpus)
-
-	for mm := range gpus {
-		for nn := range tiles {
-			nodes = append(nodes, fmt.Sprintf("%d.%d", mm, nn))
-		}
+	if topology == fullyConnected {
+		connections = gpufake.FullyConnected(gpus, tiles)
 	}
 
-	var links = make(map[string]bool, 0)
-
-	var smap = make([]string, 0)
-
-	for _, from := range nodes {
-		for _, to := range nodes {
-			// no self links, TODO ignore in-gpu xelinks
-			if to == from {
-				continue
-			}
+	saveSideCarFile(gpufake.SideCarLabels(connections, gpus))
 
-			link := fmt.Sprintf("%s-%s", to, from)
-
-			reverselink := fmt.Sprintf("%s-%s", from, to)
-			if _, exists := links[reverselink]; !exists {
-				links[link] = true
-
-				smap = append(smap, link)
-			}
-		}
-	}
-
-	return strings.Join(smap, "_")
+	log.Printf("XELINK: generated xelink sidecar label file, using (GPUs: %d, Tiles: %d, Topology: %s)", gpus, tiles, topology)
 }
 
-func saveSideCarFile(connections string) {
+func saveSideCarFile(lines []string) {
 	f, err := os.Create("xpum-sidecar-labels.txt")
 	if err != nil {
 		panic(err)
 	}
 	defer f.Close()
 
-	// Write first line without Z prefix
-	line := fmt.Sprintf("xpumanager.intel.com/xe-links=%s", connections[:min(len(connections), maxK8sLabelSize)])
-	fmt.Println(line)
-
-	if _, err := f.WriteString(line + "\n"); err != nil {
-		panic(err)
-	}
-
-	index := 2
-
-	// Write next lines with Z prefix
-	for i := maxK8sLabelSize; i < len(connections); i += (maxK8sLabelSize - 1) {
-		line := fmt.Sprintf("xpumanager.intel.com/xe-links%d=Z%s", index, connections[i:min(len(connections), i+maxK8sLabelSize-1)])
+	for _, line := range lines {
 		fmt.Println(line)
 
 		if _, err := f.WriteString(line + "\n"); err != nil {
 			panic(err)
 		}
-		index++
 	}
 }
 
@@ -20,6 +20,8 @@ import (
 
 	"k8s.io/klog/v2"
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
 )
 
 type preferredAllocationPolicyFunc func(*pluginapi.ContainerPreferredAllocationRequest) []string
@@ -149,3 +151,25 @@ func packedPolicy(req *pluginapi.ContainerPreferredAllocationRequest) []string {
 
 	return deviceIds
 }
+
+// devicePolicy adapts the policy to the shared allocation policies, which
+// choose the devices by their topology.
+func devicePolicy(policy preferredAllocationPolicyFunc) allocation.Policy {
+	return func(req *allocation.Request) []string {
+		return policy(&pluginapi.ContainerPreferredAllocationRequest{
+			AvailableDeviceIDs:   deviceIDs(req.Available),
+			MustIncludeDeviceIDs: deviceIDs(req.MustInclude),
+			AllocationSize:       int32(req.Size),
+		})
+	}
+}
+
+func deviceIDs(devices []allocation.Device) []string {
+	ids := make([]string, 0, len(devices))
+
+	for i := range devices {
+		ids = append(ids, devices[i].ID)
+	}
+
+	return ids
+}
@@ -17,9 +17,12 @@ package main
 import (
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
 )
 
 func TestGetPreferredAllocation(t *testing.T) {
@@ -51,7 +54,7 @@ func TestGetPreferredAllocation(t *testing.T) {
 	}
 
 	plugin := newDevicePlugin("", "", cliOptions{sharedDevNum: 5, preferredAllocationPolicy: "none"})
-	response, _ := plugin.GetPreferredAllocation(rqt)
+	response, _ := plugin.GetPreferredDeviceAllocation(rqt, nil)
 
 	sort.Strings(response.ContainerResponses[0].DeviceIDs)
 
@@ -60,28 +63,28 @@ func TestGetPreferredAllocation(t *testing.T) {
 	}
 
 	plugin = newDevicePlugin("", "", cliOptions{sharedDevNum: 5, preferredAllocationPolicy: "balanced"})
-	response, _ = plugin.GetPreferredAllocation(rqt)
+	response, _ = plugin.GetPreferredDeviceAllocation(rqt, nil)
 
 	if !reflect.DeepEqual(response.ContainerResponses[0].DeviceIDs, []string{"card0-0", "card1-0", "card2-0", "card3-0"}) {
 		t.Error("Unexpected return value for balanced preferred allocation", response.ContainerResponses[0].DeviceIDs)
 	}
 
 	plugin = newDevicePlugin("", "", cliOptions{sharedDevNum: 5, preferredAllocationPolicy: "packed"})
-	response, _ = plugin.GetPreferredAllocation(rqt)
+	response, _ = plugin.GetPreferredDeviceAllocation(rqt, nil)
 
 	if !reflect.DeepEqual(response.ContainerResponses[0].DeviceIDs, []string{"card0-0", "card0-1", "card0-2", "card0-3"}) {
 		t.Error("Unexpected return value for packed preferred allocation", response.ContainerResponses[0].DeviceIDs)
 	}
 
 	plugin = newDevicePlugin("", "", cliOptions{sharedDevNum: 5, preferredAllocationPolicy: "none"})
-	response, _ = plugin.GetPreferredAllocation(rqtErr)
+	response, _ = plugin.GetPreferredDeviceAllocation(rqtErr, nil)
 
 	if response != nil {
 		t.Error("Fail to handle the input error that req.AllocationSize is greater than len(req.AvailableDeviceIDs).")
 	}
 
 	plugin = newDevicePlugin("", "", cliOptions{sharedDevNum: 5, preferredAllocationPolicy: "none"})
-	response, _ = plugin.GetPreferredAllocation(rqtNotEnough)
+	response, _ = plugin.GetPreferredDeviceAllocation(rqtNotEnough, nil)
 
 	sort.Strings(response.ContainerResponses[0].DeviceIDs)
 
@@ -90,3 +93,87 @@ func TestGetPreferredAllocation(t *testing.T) {
 			response.ContainerResponses[0].DeviceIDs)
 	}
 }
+
+func TestNumaAlignedPolicy(t *testing.T) {
+	available := []string{"card0-0", "card0-1", "card1-0", "card1-1", "card2-0", "card2-1", "card3-0", "card3-1", "card4-0", "card4-1"}
+
+	// card0 and card1 in NUMA node 0, card2, card3 and card4 in node 1.
+	devices := make(map[string]dpapi.DeviceInfo)
+
+	for _, id := range available {
+		node := int64(1)
+		if strings.HasPrefix(id, "card0") || strings.HasPrefix(id, "card1") {
+			node = 0
+		}
+
+		devices[id] = dpapi.NewDeviceInfoWithTopologyHints(v1beta1.Healthy, nil, nil, nil, nil,
+			&v1beta1.TopologyInfo{Nodes: []*v1beta1.NUMANode{{ID: node}}}, nil)
+	}
+
+	tcases := []struct {
+		name        string
+		policy      string
+		available   []string
+		mustInclude []string
+		expected    []string
+		size        int32
+	}{
+		{
+			name:      "smallest node that fits",
+			policy:    "packed",
+			available: available,
+			size:      3,
+			expected:  []string{"card0-0", "card0-1", "card1-0"},
+		},
+		{
+			name:      "balanced in the node",
+			policy:    "balanced",
+			available: available,
+			size:      2,
+			expected:  []string{"card0-0", "card1-0"},
+		},
+		{
+			name:      "node with enough devices",
+			policy:    "balanced",
+			available: available,
+			size:      5,
+			expected:  []string{"card2-0", "card3-0", "card4-0", "card2-1", "card3-1"},
+		},
+		{
+			name:        "node of the devices to include",
+			policy:      "packed",
+			available:   available,
+			mustInclude: []string{"card4-1"},
+			size:        2,
+			expected:    []string{"card4-1", "card2-0"},
+		},
+		{
+			name:      "spill over from the largest node",
+			policy:    "packed",
+			available: []string{"card0-0", "card1-0", "card2-0", "card3-0", "card4-0"},
+			size:      4,
+			expected:  []string{"card2-0", "card3-0", "card4-0", "card0-0"},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := newDevicePlugin("", "", cliOptions{sharedDevNum: 2, preferredAllocationPolicy: tc.policy, numaAligned: true})
+
+			response, err := plugin.GetPreferredDeviceAllocation(&v1beta1.PreferredAllocationRequest{
+				ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{{
+					AvailableDeviceIDs:   tc.available,
+					MustIncludeDeviceIDs: tc.mustInclude,
+					AllocationSize:       tc.size,
+				}},
+			}, devices)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ids := response.ContainerResponses[0].DeviceIDs; !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/labeler"
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+)
+
+const (
+	// Fractional resources sharing the GPUs.
+	memoryResource    = "memory.max"
+	millicoreResource = "millicores"
+
+	millicoresPerGPU     = 1000
+	defaultMemoryUnitMiB = 256
+	defaultMillicoreUnit = 100
+
+	// Time an allocation is kept before it's released for not being listed
+	// by the PodResources API.
+	podResourcesGrace = time.Minute
+)
+
+// fractionalCard is a GPU shared by the fractional resources.
+type fractionalCard struct {
+	// Resource -> units of the card.
+	capacity map[string]int
+	health   string
+	devSpecs []pluginapi.DeviceSpec
+	mounts   []pluginapi.Mount
+	info     dpapi.DeviceInfo
+}
+
+// deviceOwners tells which containers hold the devices of a resource.
+type deviceOwners interface {
+	Refresh(ctx context.Context) error
+	Owners(ctx context.Context, resourceName string) (map[string]dpapi.DeviceOwner, error)
+}
+
+// fractionalAllocation is the share of a card bound to a container.
+type fractionalAllocation struct {
+	allocated time.Time
+	card      string
+	resource  string
+	ids       []string
+	paired    bool
+}
+
+// fractionalResources shares the GPUs by their local memory and millicores.
+// Each unit of a card is a device of the resource, but the share of a
+// container is bound in Allocate to a single card with enough units left,
+// whichever cards the devices chosen by kubelet are from. The shares are
+// accounted until kubelet hands the same devices out again or they are no
+// longer listed by the PodResources API.
+type fractionalResources struct {
+	now           func() time.Time
+	owners        deviceOwners
+	cards         map[string]*fractionalCard
+	allocations   []*fractionalAllocation
+	memoryUnit    uint64
+	millicoreUnit int
+	mutex         sync.Mutex
+}
+
+func newFractionalResources(memoryUnit uint64, millicoreUnit int) *fractionalResources {
+	return &fractionalResources{
+		now:           time.Now,
+		cards:         make(map[string]*fractionalCard),
+		memoryUnit:    memoryUnit,
+		millicoreUnit: millicoreUnit,
+	}
+}
+
+func fractionalID(card, resource string, unit int) string {
+	return fmt.Sprintf("%s-%s-%d", card, resource, unit)
+}
+
+// parseFractionalID returns the card and the resource of a fractional
+// resource device.
+func parseFractionalID(id string) (card, resource string, ok bool) {
+	parts := strings.Split(id, "-")
+	if len(parts) != 3 || (parts[1] != memoryResource && parts[1] != millicoreResource) {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// newCard reads the local memory of the card at cardPath for its units of
+// the fractional resources. The devices of the resources get info.
+func (f *fractionalResources) newCard(cardPath, health string, devSpecs []pluginapi.DeviceSpec, mounts []pluginapi.Mount, info dpapi.DeviceInfo) *fractionalCard {
+	card := &fractionalCard{
+		capacity: map[string]int{millicoreResource: millicoresPerGPU / f.millicoreUnit},
+		health:   health,
+		devSpecs: devSpecs,
+		mounts:   mounts,
+		info:     info,
+	}
+
+	name := path.Base(cardPath)
+	memory := labeler.GetLocalMemoryAmount(path.Dir(cardPath), name, labeler.GetTileCount(cardPath))
+
+	if memory < math.MaxInt64 && memory/f.memoryUnit > 0 {
+		card.capacity[memoryResource] = int(memory / f.memoryUnit)
+	} else {
+		klog.V(2).Infof("No local memory found for %s, %s not available", name, memoryResource)
+	}
+
+	return card
+}
+
+// setCards replaces the cards with the scanned ones and adds a device per
+// unit of the cards to devTree.
+func (f *fractionalResources) setCards(devTree dpapi.DeviceTree, cards map[string]*fractionalCard) {
+	for name, card := range cards {
+		for resource, units := range card.capacity {
+			for unit := range units {
+				devTree.AddDevice(resource, fractionalID(name, resource, unit), card.info)
+			}
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.cards = cards
+}
+
+func (f *fractionalResources) setOwners(owners deviceOwners) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.owners = owners
+}
+
+// ownedDevices returns the owners of the fractional resource devices held
+// by containers according to the PodResources API. The owners are read
+// again, as the devices of the other resource of the container being
+// allocated were only just allocated. It's nil if the API isn't used.
+func (f *fractionalResources) ownedDevices(ctx context.Context) map[string]dpapi.DeviceOwner {
+	f.mutex.Lock()
+	owners := f.owners
+	f.mutex.Unlock()
+
+	if owners == nil {
+		return nil
+	}
+
+	if err := owners.Refresh(ctx); err != nil {
+		klog.Warningf("Failed to refresh the owners of the fractional resources: %+v", err)
+
+		return nil
+	}
+
+	owned := make(map[string]dpapi.DeviceOwner)
+
+	for _, resource := range []string{memoryResource, millicoreResource} {
+		devices, err := owners.Owners(ctx, namespace+"/"+resource)
+		if err != nil {
+			klog.Warningf("Failed to get the owners of %s devices: %+v", resource, err)
+
+			return nil
+		}
+
+		maps.Copy(owned, devices)
+	}
+
+	return owned
+}
+
+// release forgets the allocations having any of the given devices, as
+// kubelet only hands out devices not held by containers.
+func (f *fractionalResources) release(ids []string) {
+	f.allocations = slices.DeleteFunc(f.allocations, func(a *fractionalAllocation) bool {
+		for _, id := range ids {
+			if slices.Contains(a.ids, id) {
+				klog.V(4).Infof("Releasing %d %s of %s", len(a.ids), a.resource, a.card)
+
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// releaseUnowned forgets the allocations not listed in owned after a grace
+// period covering the time before kubelet lists new allocations.
+func (f *fractionalResources) releaseUnowned(owned map[string]dpapi.DeviceOwner) {
+	if owned == nil {
+		return
+	}
+
+	now := f.now()
+
+	f.allocations = slices.DeleteFunc(f.allocations, func(a *fractionalAllocation) bool {
+		if now.Sub(a.allocated) < podResourcesGrace {
+			return false
+		}
+
+		for _, id := range a.ids {
+			if _, found := owned[id]; found {
+				return false
+			}
+		}
+
+		klog.V(4).Infof("Releasing %d %s of %s not held by any container", len(a.ids), a.resource, a.card)
+
+		return true
+	})
+}
+
+// free returns the units of the resource left in the card.
+func (f *fractionalResources) free(name, resource string) int {
+	card, found := f.cards[name]
+	if !found {
+		return 0
+	}
+
+	free := card.capacity[resource]
+
+	for _, a := range f.allocations {
+		if a.card == name && a.resource == resource {
+			free -= len(a.ids)
+		}
+	}
+
+	return free
+}
+
+// pairedAllocation returns the allocation of the other fractional resource
+// of the container being allocated. Kubelet allocates the resources of a
+// container one after another and lists the container in the PodResources
+// API with the devices allocated so far. The container's allocation is
+// thus the latest unpaired one held by a container without devices of the
+// resource. Without the PodResources API, the resources are not paired.
+func (f *fractionalResources) pairedAllocation(resource string, owned map[string]dpapi.DeviceOwner) *fractionalAllocation {
+	if owned == nil {
+		return nil
+	}
+
+	allocated := make(map[dpapi.DeviceOwner]bool)
+
+	for id, owner := range owned {
+		if _, r, _ := parseFractionalID(id); r == resource {
+			allocated[owner] = true
+		}
+	}
+
+	for _, a := range slices.Backward(f.allocations) {
+		if a.resource == resource || a.paired {
+			continue
+		}
+
+		if owner, found := owned[a.ids[0]]; found && !allocated[owner] {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// cardOf returns the card of the devices if they are all from the same card.
+func cardOf(ids []string) string {
+	card := ""
+
+	for _, id := range ids {
+		c, _, _ := parseFractionalID(id)
+		if card != "" && c != card {
+			return ""
+		}
+
+		card = c
+	}
+
+	return card
+}
+
+// bind binds the devices of the resource to the card with enough units
+// left. The card of the other resource of the same container is preferred,
+// then the card of the devices and then the card with the fewest units left.
+func (f *fractionalResources) bind(resource string, ids []string, owned map[string]dpapi.DeviceOwner) (string, error) {
+	need := len(ids)
+	candidates := []string{}
+
+	for name, card := range f.cards {
+		if card.health == pluginapi.Healthy && f.free(name, resource) >= need {
+			candidates = append(candidates, name)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return "", errors.Errorf("no healthy GPU has %d %s left", need, resource)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		fi, fj := f.free(candidates[i], resource), f.free(candidates[j], resource)
+		if fi != fj {
+			return fi < fj
+		}
+
+		return candidates[i] < candidates[j]
+	})
+
+	allocation := &fractionalAllocation{
+		allocated: f.now(),
+		card:      candidates[0],
+		resource:  resource,
+		ids:       slices.Clone(ids),
+	}
+
+	if pair := f.pairedAllocation(resource, owned); pair != nil && slices.Contains(candidates, pair.card) {
+		allocation.card = pair.card
+		allocation.paired = true
+		pair.paired = true
+	} else if card := cardOf(ids); slices.Contains(candidates, card) {
+		allocation.card = card
+	}
+
+	f.allocations = append(f.allocations, allocation)
+
+	klog.V(2).Infof("Bound %d %s to %s", need, resource, allocation.card)
+
+	return allocation.card, nil
+}
+
+// handles tells if the devices are of the fractional resources.
+func (f *fractionalResources) handles(ids []string) bool {
+	if len(ids) == 0 {
+		return false
+	}
+
+	_, _, ok := parseFractionalID(ids[0])
+
+	return ok
+}
+
+// allocate binds the share of each container to a card. The container
+// requests are changed to a device of the bound card, which the default
+// allocation then gives like a whole card: the device nodes, mounts, CDI
+// devices and the rest of the card's device info.
+func (f *fractionalResources) allocate(rqt *pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
+	owned := f.ownedDevices(context.Background())
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.releaseUnowned(owned)
+
+	for _, crqt := range rqt.ContainerRequests {
+		_, resource, _ := parseFractionalID(crqt.DevicesIds[0])
+
+		f.release(crqt.DevicesIds)
+
+		card, err := f.bind(resource, crqt.DevicesIds, owned)
+		if err != nil {
+			return nil, err
+		}
+
+		crqt.DevicesIds = []string{fractionalID(card, resource, 0)}
+	}
+
+	return nil, &dpapi.UseDefaultMethodError{}
+}
+
+// preferred returns the devices of a single card with enough units left.
+// The card of the other resource of the same container is preferred, then
+// the card with the fewest units left.
+func (f *fractionalResources) preferred(req *pluginapi.ContainerPreferredAllocationRequest) []string {
+	owned := f.ownedDevices(context.Background())
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.release(req.AvailableDeviceIDs)
+
+	size := int(req.AllocationSize)
+	_, resource, _ := parseFractionalID(req.AvailableDeviceIDs[0])
+
+	available := make(map[string][]string)
+
+	for _, id := range req.AvailableDeviceIDs {
+		if slices.Contains(req.MustIncludeDeviceIDs, id) {
+			continue
+		}
+
+		card, _, _ := parseFractionalID(id)
+		available[card] = append(available[card], id)
+	}
+
+	candidates := []string{}
+
+	for card, ids := range available {
+		if len(ids)+len(req.MustIncludeDeviceIDs) >= size &&
+			(len(req.MustIncludeDeviceIDs) == 0 || cardOf(req.MustIncludeDeviceIDs) == card) {
+			candidates = append(candidates, card)
+		}
+	}
+
+	if len(candidates) == 0 {
+		klog.V(2).Infof("No single GPU has %d %s available", size, resource)
+
+		return packedPolicy(req)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		fi, fj := f.free(candidates[i], resource), f.free(candidates[j], resource)
+		if (fi >= size) != (fj >= size) {
+			return fi >= size
+		}
+
+		if fi != fj {
+			return fi < fj
+		}
+
+		return candidates[i] < candidates[j]
+	})
+
+	card := candidates[0]
+
+	if pair := f.pairedAllocation(resource, owned); pair != nil && slices.Contains(candidates, pair.card) &&
+		f.free(pair.card, resource) >= size {
+		card = pair.card
+	}
+
+	ids := available[card]
+	sort.Strings(ids)
+
+	deviceIDs := append(slices.Clone(req.MustIncludeDeviceIDs), ids[:size-len(req.MustIncludeDeviceIDs)]...)
+
+	klog.V(2).Infof("Allocate deviceIds: %q", deviceIDs)
+
+	return deviceIDs
+}
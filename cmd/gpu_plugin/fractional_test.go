@@ -0,0 +1,364 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+)
+
+func fractionalIDs(card, resource string, units ...int) []string {
+	ids := []string{}
+	for _, unit := range units {
+		ids = append(ids, fractionalID(card, resource, unit))
+	}
+
+	return ids
+}
+
+// fakeDeviceOwners lists the owners of the fractional resource devices
+// like the PodResources API.
+type fakeDeviceOwners struct {
+	owned map[string]dpapi.DeviceOwner
+}
+
+func (o *fakeDeviceOwners) Refresh(ctx context.Context) error {
+	return nil
+}
+
+func (o *fakeDeviceOwners) Owners(ctx context.Context, resourceName string) (map[string]dpapi.DeviceOwner, error) {
+	owners := make(map[string]dpapi.DeviceOwner)
+
+	for id, owner := range o.owned {
+		if _, resource, _ := parseFractionalID(id); namespace+"/"+resource == resourceName {
+			owners[id] = owner
+		}
+	}
+
+	return owners, nil
+}
+
+// ownedBy returns the devices owned by the container.
+func ownedBy(container string, ids ...[]string) map[string]dpapi.DeviceOwner {
+	owned := make(map[string]dpapi.DeviceOwner)
+
+	for _, list := range ids {
+		for _, id := range list {
+			owned[id] = dpapi.DeviceOwner{Namespace: "default", Pod: container, Container: container}
+		}
+	}
+
+	return owned
+}
+
+// merge merges the devices owned by containers.
+func merge(owned ...map[string]dpapi.DeviceOwner) map[string]dpapi.DeviceOwner {
+	merged := make(map[string]dpapi.DeviceOwner)
+	for _, o := range owned {
+		maps.Copy(merged, o)
+	}
+
+	return merged
+}
+
+// allocateFractional allocates the devices to a container and returns the
+// device of the bound card the request was changed to.
+func allocateFractional(plugin *devicePlugin, ids []string) (string, error) {
+	rqt := &v1beta1.AllocateRequest{
+		ContainerRequests: []*v1beta1.ContainerAllocateRequest{{DevicesIds: ids}},
+	}
+
+	if _, err := plugin.Allocate(rqt); err != nil {
+		if _, ok := err.(*dpapi.UseDefaultMethodError); !ok {
+			return "", err
+		}
+	}
+
+	return rqt.ContainerRequests[0].DevicesIds[0], nil
+}
+
+func newTestFractionalResources(now *time.Time) *fractionalResources {
+	f := newFractionalResources(defaultMemoryUnitMiB<<20, defaultMillicoreUnit)
+	f.now = func() time.Time { return *now }
+
+	for _, name := range []string{"card0", "card1"} {
+		f.cards[name] = &fractionalCard{
+			capacity: map[string]int{memoryResource: 4, millicoreResource: millicoresPerGPU / defaultMillicoreUnit},
+			health:   v1beta1.Healthy,
+			devSpecs: []v1beta1.DeviceSpec{{HostPath: "/dev/dri/" + name, ContainerPath: "/dev/dri/" + name, Permissions: "rw"}},
+		}
+	}
+
+	return f
+}
+
+func TestParseFractionalID(t *testing.T) {
+	tcases := []struct {
+		id       string
+		card     string
+		resource string
+		ok       bool
+	}{
+		{id: "card0-memory.max-12", card: "card0", resource: memoryResource, ok: true},
+		{id: "card1-millicores-9", card: "card1", resource: millicoreResource, ok: true},
+		{id: "card0-1"},
+		{id: "card0-tiles-1"},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.id, func(t *testing.T) {
+			card, resource, ok := parseFractionalID(tc.id)
+			if card != tc.card || resource != tc.resource || ok != tc.ok {
+				t.Errorf("expected %q %q %v, got %q %q %v", tc.card, tc.resource, tc.ok, card, resource, ok)
+			}
+		})
+	}
+}
+
+func TestFractionalScan(t *testing.T) {
+	tc := TestCaseDetails{
+		sysfsdirs: []string{"card0/device/drm/card0", "card1/device/drm/card1"},
+		sysfsfiles: map[string][]byte{
+			"card0/device/vendor":    []byte("0x8086"),
+			"card0/lmem_total_bytes": []byte(strconv.Itoa(1 << 30)),
+			"card1/device/vendor":    []byte("0x8086"),
+		},
+		devfsdirs: []string{"card0", "card1"},
+	}
+
+	sysfs, devfs, err := createTestFiles(t.TempDir(), tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plugin := newDevicePlugin(sysfs, devfs, cliOptions{
+		sharedDevNum:        1,
+		fractionalResources: true,
+		memoryUnitMiB:       defaultMemoryUnitMiB,
+		millicoreUnit:       defaultMillicoreUnit,
+	})
+
+	tree, err := plugin.scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := tree.DeviceTypeCount(memoryResource); count != 4 {
+		t.Errorf("expected 4 %s devices, got %d", memoryResource, count)
+	}
+
+	if count := tree.DeviceTypeCount(millicoreResource); count != 2*millicoresPerGPU/defaultMillicoreUnit {
+		t.Errorf("expected %d %s devices, got %d", 2*millicoresPerGPU/defaultMillicoreUnit, millicoreResource, count)
+	}
+
+	if count := tree.DeviceTypeCount(deviceTypeI915); count != 2 {
+		t.Errorf("expected 2 %s devices, got %d", deviceTypeI915, count)
+	}
+
+	if _, found := tree[memoryResource][fractionalID("card0", memoryResource, 3)]; !found {
+		t.Errorf("%s devices not from card0: %v", memoryResource, tree[memoryResource])
+	}
+
+	if _, found := plugin.fractional.cards["card1"]; !found {
+		t.Error("card1 not shared by the fractional resources")
+	}
+}
+
+func TestFractionalAllocate(t *testing.T) {
+	now := time.Now()
+	f := newTestFractionalResources(&now)
+	owners := &fakeDeviceOwners{}
+	f.setOwners(owners)
+	plugin := &devicePlugin{fractional: f}
+
+	type allocation struct {
+		owned        map[string]dpapi.DeviceOwner
+		name         string
+		expectedCard string
+		expectedErr  string
+		ids          []string
+		advance      time.Duration
+	}
+
+	memoryA := fractionalIDs("card1", memoryResource, 0, 1, 2)
+	millicoresA := fractionalIDs("card0", millicoreResource, 0, 1)
+	memoryB := append(fractionalIDs("card1", memoryResource, 3), fractionalIDs("card0", memoryResource, 0)...)
+
+	allocations := []allocation{
+		{
+			name:         "devices of a single card",
+			ids:          memoryA,
+			expectedCard: "card1",
+		},
+		{
+			name:         "other resource of the same container",
+			ids:          millicoresA,
+			owned:        ownedBy("a", memoryA),
+			expectedCard: "card1",
+		},
+		{
+			name:         "devices of a card without enough memory left",
+			ids:          memoryB,
+			owned:        ownedBy("a", memoryA, millicoresA),
+			expectedCard: "card0",
+		},
+		{
+			name:         "other resource of a container allocated before",
+			ids:          fractionalIDs("card1", millicoreResource, 5),
+			owned:        merge(ownedBy("a", memoryA, millicoresA), ownedBy("b", memoryB)),
+			expectedCard: "card0",
+		},
+		{
+			name:         "last units of a card",
+			ids:          fractionalIDs("card0", memoryResource, 1, 2),
+			expectedCard: "card0",
+		},
+		{
+			name:        "not enough memory left",
+			ids:         fractionalIDs("card0", memoryResource, 3, 4, 5),
+			expectedErr: "no healthy GPU has 3 memory.max left",
+		},
+		{
+			name:         "devices handed out again",
+			ids:          memoryA,
+			expectedCard: "card1",
+		},
+		{
+			name:         "devices released from pods",
+			ids:          fractionalIDs("card0", memoryResource, 3, 4, 5),
+			advance:      podResourcesGrace,
+			owned:        ownedBy("d", fractionalIDs("card1", memoryResource, 0)),
+			expectedCard: "card0",
+		},
+	}
+
+	for _, a := range allocations {
+		now = now.Add(a.advance)
+		owners.owned = a.owned
+
+		device, err := allocateFractional(plugin, a.ids)
+
+		if a.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), a.expectedErr) {
+				t.Errorf("%s: expected error %q, got %v", a.name, a.expectedErr, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %+v", a.name, err)
+
+			continue
+		}
+
+		_, resource, _ := parseFractionalID(a.ids[0])
+
+		if expected := fractionalID(a.expectedCard, resource, 0); device != expected {
+			t.Errorf("%s: expected %s, got %s", a.name, expected, device)
+		}
+	}
+
+	expectedFree := map[string]int{"card0": 1, "card1": 1}
+	for card, expected := range expectedFree {
+		if free := f.free(card, memoryResource); free != expected {
+			t.Errorf("expected %d %s left in %s, got %d", expected, memoryResource, card, free)
+		}
+	}
+
+	if free := f.free("card1", millicoreResource); free != millicoresPerGPU/defaultMillicoreUnit {
+		t.Errorf("expected the %s of card1 to be released, got %d left", millicoreResource, free)
+	}
+}
+
+func TestFractionalPreferredAllocation(t *testing.T) {
+	tcases := []struct {
+		owned       map[string]dpapi.DeviceOwner
+		name        string
+		allocated   []string
+		available   []string
+		mustInclude []string
+		expected    []string
+		size        int32
+	}{
+		{
+			name:      "card with the fewest units left",
+			allocated: fractionalIDs("card1", memoryResource, 0),
+			available: append(fractionalIDs("card0", memoryResource, 0, 1, 2, 3), fractionalIDs("card1", memoryResource, 1, 2, 3)...),
+			size:      2,
+			expected:  fractionalIDs("card1", memoryResource, 1, 2),
+		},
+		{
+			name:      "card of the other resource of the same container",
+			allocated: fractionalIDs("card1", millicoreResource, 0),
+			owned:     ownedBy("a", fractionalIDs("card1", millicoreResource, 0)),
+			available: append(fractionalIDs("card0", memoryResource, 0, 1, 2, 3), fractionalIDs("card1", memoryResource, 0, 1, 2)...),
+			size:      2,
+			expected:  fractionalIDs("card1", memoryResource, 0, 1),
+		},
+		{
+			name:        "card of the devices to include",
+			available:   append(fractionalIDs("card0", memoryResource, 0, 1, 2, 3), fractionalIDs("card1", memoryResource, 0, 1, 2)...),
+			mustInclude: fractionalIDs("card0", memoryResource, 3),
+			size:        2,
+			expected:    fractionalIDs("card0", memoryResource, 3, 0),
+		},
+		{
+			name:      "no single card with enough units",
+			available: append(fractionalIDs("card0", memoryResource, 0, 1), fractionalIDs("card1", memoryResource, 0, 1)...),
+			size:      3,
+			expected:  append(fractionalIDs("card0", memoryResource, 0, 1), fractionalIDs("card1", memoryResource, 0)...),
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			f := newTestFractionalResources(&now)
+			f.setOwners(&fakeDeviceOwners{owned: tc.owned})
+			plugin := &devicePlugin{fractional: f}
+
+			if len(tc.allocated) > 0 {
+				if _, err := allocateFractional(plugin, tc.allocated); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			resp, err := plugin.GetPreferredDeviceAllocation(&v1beta1.PreferredAllocationRequest{
+				ContainerRequests: []*v1beta1.ContainerPreferredAllocationRequest{{
+					AvailableDeviceIDs:   tc.available,
+					MustIncludeDeviceIDs: tc.mustInclude,
+					AllocationSize:       tc.size,
+				}},
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ids := resp.ContainerResponses[0].DeviceIDs; !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, ids)
+			}
+		})
+	}
+}
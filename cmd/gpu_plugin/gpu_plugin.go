@@ -20,11 +20,14 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
@@ -34,8 +37,11 @@ import (
 
 	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/gpu_plugin/levelzeroservice"
 	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/gpu_plugin/xpumdservice"
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/labeler"
 	gpulevelzero "github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/levelzero"
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/uevent"
 	cdispec "tags.cncf.io/container-device-interface/specs-go"
 )
 
@@ -71,7 +77,8 @@ const (
 
 	levelzeroAffinityMaskEnvVar = "ZE_AFFINITY_MASK"
 
-	// Period of device scans.
+	// Period of device rescans in addition to the ones triggered by drm uevents.
+	// Kept short as the scans also refresh the device health.
 	scanPeriod = 5 * time.Second
 
 	// Default limit for temperatures.
@@ -85,13 +92,23 @@ type cliOptions struct {
 	bypathMount               string
 	monitoringMode            string
 	xpumdEndpoint             string
+	resourceGranularity       string
+	xeLinkFile                string
 	sharedDevNum              int
 	globalTempLimit           int
 	memoryTempLimit           int
 	gpuTempLimit              int
+	memoryUnitMiB             int
+	millicoreUnit             int
+	sysfsTempLimit            int
+	sysfsMemoryTempLimit      int
+	correctableErrorLimit     int
 	enableMonitoring          bool
 	wslScan                   bool
 	healthManagement          bool
+	fractionalResources       bool
+	sysfsHealth               bool
+	numaAligned               bool
 }
 
 type argError struct {
@@ -150,6 +167,29 @@ func (dp *devicePlugin) pciAddressForCard(cardPath, cardName string) (string, er
 	return pciAddress, nil
 }
 
+// setDeviceAttributes sets the attributes of the GPU of a card to deviceInfo.
+func (dp *devicePlugin) setDeviceAttributes(deviceInfo *dpapi.DeviceInfo, cardPath, cardName, driver string) {
+	deviceInfo.SetAttribute(dpapi.AttributeDriver, dpapi.StringAttribute(driver))
+
+	if pciAddr, err := dp.pciAddressForCard(cardPath, cardName); err == nil {
+		deviceInfo.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute(pciAddr))
+	}
+
+	if pciID, err := pciDeviceIDForCard(cardPath); err == nil {
+		deviceInfo.SetAttribute(dpapi.AttributePCIDeviceID, dpapi.StringAttribute(pciID))
+	}
+
+	if pf, err := filepath.EvalSymlinks(filepath.Join(cardPath, "device", "physfn")); err == nil {
+		deviceInfo.SetAttribute(dpapi.AttributePhysicalFunction, dpapi.StringAttribute(filepath.Base(pf)))
+	}
+
+	if data, err := os.ReadFile(filepath.Join(cardPath, "lmem_total_bytes")); err == nil {
+		if memory, parseErr := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); parseErr == nil {
+			deviceInfo.SetAttribute(dpapi.AttributeMemory, dpapi.IntAttribute(memory))
+		}
+	}
+}
+
 func pciDeviceIDForCard(cardPath string) (string, error) {
 	idPath := filepath.Join(cardPath, "device", "device")
 
@@ -206,29 +246,38 @@ type devicePlugin struct {
 	controlDeviceReg *regexp.Regexp
 	pciAddressReg    *regexp.Regexp
 
-	scanTicker    *time.Ticker
-	scanDone      chan bool
 	scanResources chan bool
+	// rescan triggers a scan after a reconfiguration.
+	rescan chan struct{}
 
 	levelzeroService levelzeroservice.LevelzeroService
 	xpumdService     xpumdservice.XpumdService
 
+	// fractional shares the GPUs by memory and millicores when enabled.
+	fractional *fractionalResources
+	xeLinks    *xeLinkPolicy
+
 	sysfsDrmDir    string
+	debugfsDriDir  string
 	devFsRoot      string
 	devDriDir      string
 	bypathDir      string
 	healthStatuses map[string]string
 
-	// Note: If restarting the plugin with a new policy, the allocations for existing pods remain with old policy.
-	policy  preferredAllocationPolicyFunc
+	// Note: If changing the policy, the allocations for existing pods remain with old policy.
+	policy  allocation.Policy
 	options cliOptions
 
+	// mutex protects the options and the policy changed by Reconfigure.
+	mutex sync.RWMutex
+
 	bypathFound bool
 }
 
 func newDevicePlugin(sysfsDir, devFsDir string, options cliOptions) *devicePlugin {
 	dp := &devicePlugin{
 		sysfsDrmDir:      path.Join(sysfsDir, "class", "drm"),
+		debugfsDriDir:    path.Join(sysfsDir, "kernel", "debug", "dri"),
 		devFsRoot:        devFsDir,
 		devDriDir:        path.Join(devFsDir, "dri"),
 		bypathDir:        path.Join(devFsDir, "dri", "by-path"),
@@ -236,20 +285,23 @@ func newDevicePlugin(sysfsDir, devFsDir string, options cliOptions) *devicePlugi
 		gpuDeviceReg:     regexp.MustCompile(gpuDeviceRE),
 		controlDeviceReg: regexp.MustCompile(controlDeviceRE),
 		pciAddressReg:    regexp.MustCompile(pciAddressRE),
-		scanTicker:       time.NewTicker(scanPeriod),
-		scanDone:         make(chan bool, 1), // buffered as we may send to it before Scan starts receiving from it
 		bypathFound:      true,
 		scanResources:    make(chan bool, 1),
+		rescan:           make(chan struct{}, 1),
 		healthStatuses:   make(map[string]string),
 	}
 
-	switch options.preferredAllocationPolicy {
-	case "balanced":
-		dp.policy = balancedPolicy
-	case "packed":
-		dp.policy = packedPolicy
-	default:
-		dp.policy = nonePolicy
+	dp.xeLinks = &xeLinkPolicy{
+		links: xeLinkLabelFile{path: options.xeLinkFile},
+		numaNode: func(card int) int {
+			return labeler.GetNumaNode(dp.sysfsDrmDir, "card"+strconv.Itoa(card))
+		},
+	}
+
+	dp.policy = dp.allocationPolicy(options)
+
+	if options.fractionalResources {
+		dp.fractional = newFractionalResources(uint64(options.memoryUnitMiB)<<20, options.millicoreUnit)
 	}
 
 	if !options.wslScan {
@@ -263,6 +315,76 @@ func newDevicePlugin(sysfsDir, devFsDir string, options cliOptions) *devicePlugi
 	return dp
 }
 
+// allocationPolicy returns the allocation policy of the options. The NUMA
+// aligned policy takes the NUMA nodes from the topology of the devices.
+func (dp *devicePlugin) allocationPolicy(opts cliOptions) allocation.Policy {
+	var policy preferredAllocationPolicyFunc
+
+	switch opts.preferredAllocationPolicy {
+	case "balanced":
+		policy = balancedPolicy
+	case "packed":
+		policy = packedPolicy
+	case "xelink":
+		policy = dp.xeLinks.allocate
+	default:
+		policy = nonePolicy
+	}
+
+	if opts.numaAligned {
+		return allocation.NUMAAlignedPolicy(devicePolicy(policy))
+	}
+
+	return devicePolicy(policy)
+}
+
+// Reconfigure implements the Reconfigurer interface. The share count, the
+// allow and deny lists and the allocation policy are changed in place and
+// the devices are scanned again. Other options need a restart.
+func (dp *devicePlugin) Reconfigure(options map[string]string) error {
+	dp.mutex.Lock()
+	defer dp.mutex.Unlock()
+
+	opts := dp.options
+
+	for name, value := range options {
+		var err error
+
+		switch name {
+		case "shared-dev-num":
+			opts.sharedDevNum, err = strconv.Atoi(value)
+		case "allocation-policy":
+			opts.preferredAllocationPolicy = value
+		case "numa-aligned":
+			opts.numaAligned, err = strconv.ParseBool(value)
+		case "allow-ids":
+			opts.allowIDs = value
+		case "deny-ids":
+			opts.denyIDs = value
+		default:
+			return errors.Errorf("changing option %q requires a restart", name)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for %q", name)
+		}
+	}
+
+	if err := checkBasics(opts); err != nil {
+		return err
+	}
+
+	dp.options = opts
+	dp.policy = dp.allocationPolicy(opts)
+
+	select {
+	case dp.rescan <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
 func logHealthStatusChange(card, newStatus string, statuses map[string]string) {
 	prevState, found := statuses[card]
 	if !found {
@@ -289,95 +411,60 @@ func bdfForCard(cardPath string) (string, bool) {
 	return filepath.Base(link), true
 }
 
-func (dp *devicePlugin) healthStatusForCard(cardPath string) string {
-	if dp.xpumdService != nil {
-		return dp.healthStatusForCardXpumd(cardPath)
-	} else if dp.levelzeroService != nil {
-		return dp.healthStatusForCardLZ(cardPath)
+// healthStatusForCard returns the health of the card and the reason it's
+// unhealthy.
+func (dp *devicePlugin) healthStatusForCard(cardPath string) (health, reason string) {
+	source := dp.healthSource()
+	if source == nil {
+		return pluginapi.Healthy, ""
 	}
 
-	return pluginapi.Healthy
-}
-
-// healthStatusForCardLZ checks device health using the Level-Zero service.
-func (dp *devicePlugin) healthStatusForCardLZ(cardPath string) string {
-	bdfAddr, ok := bdfForCard(cardPath)
-	if !ok {
-		return pluginapi.Healthy
-	}
-
-	health := pluginapi.Healthy
-
-	// Check status changes after the function exits
-	defer func() { logHealthStatusChange(cardPath, health, dp.healthStatuses) }()
+	health = pluginapi.Healthy
 
-	dh, err := dp.levelzeroService.GetDeviceHealth(bdfAddr)
+	// The sources tell whether a card with failed health checks is
+	// healthy: the sidecars keep it healthy when they can't be reached.
+	reason, err := source.cardHealth(cardPath)
 	if err != nil {
-		klog.Warningf("Device health retrieval failed: %v", err)
-
-		return health
+		klog.Warningf("Health check of %s failed: %v", cardPath, err)
 	}
 
-	// Direct Health indicators
-	klog.V(4).Infof("Health indicators: Memory=%t, Bus=%t, SoC=%t", dh.Memory, dh.Bus, dh.SoC)
-
-	if !dh.Memory || !dh.Bus || !dh.SoC {
+	if reason != "" {
 		health = pluginapi.Unhealthy
-
-		return health
 	}
 
-	deviceTemps, err := dp.levelzeroService.GetDeviceTemperature(bdfAddr)
-	// In case of any errors, return the current health status
-	if err != nil {
-		klog.Warningf("Device temperature retrieval failed: %v", err)
+	logHealthStatusChange(cardPath, health, dp.healthStatuses)
 
-		return health
-	}
-
-	// Temperatures for different areas
-	klog.V(4).Infof("Temperatures: Memory=%dC, GPU=%dC, Global=%dC",
-		deviceTemps.Memory, deviceTemps.GPU, deviceTemps.Global)
-
-	if deviceTemps.GPU > dp.options.gpuTempLimit ||
-		deviceTemps.Global > dp.options.globalTempLimit ||
-		deviceTemps.Memory > dp.options.memoryTempLimit {
-		health = pluginapi.Unhealthy
-	}
-
-	return health
+	return health, reason
 }
 
-// healthStatusForCardXpumd checks device health using the xpumd service.
-func (dp *devicePlugin) healthStatusForCardXpumd(cardPath string) string {
-	bdfAddr, ok := bdfForCard(cardPath)
-	if !ok {
-		return pluginapi.Healthy
-	}
-
-	health := pluginapi.Healthy
-
-	// Check status changes after the function exits
-	defer func() { logHealthStatusChange(cardPath, health, dp.healthStatuses) }()
-
-	healthy, err := dp.xpumdService.GetDeviceHealth(bdfAddr)
-	if err != nil {
-		klog.Warningf("xpumd device health retrieval failed: %v", err)
-
-		return health
-	}
-
-	klog.V(4).Infof("xpumd health for %s: Healthy=%t", bdfAddr, healthy)
-
-	if !healthy {
-		health = pluginapi.Unhealthy
+// healthSource returns the source of the card health, or nil without one.
+func (dp *devicePlugin) healthSource() healthSource {
+	switch {
+	case dp.xpumdService != nil:
+		return xpumdHealth{service: dp.xpumdService}
+	case dp.levelzeroService != nil:
+		return levelzeroHealth{
+			service: dp.levelzeroService,
+			limits: tempLimits{
+				gpu:    dp.options.gpuTempLimit,
+				memory: dp.options.memoryTempLimit,
+				global: dp.options.globalTempLimit,
+			},
+		}
+	case dp.options.sysfsHealth:
+		return sysfsHealth{
+			debugfsDriDir:         dp.debugfsDriDir,
+			tempLimit:             dp.options.sysfsTempLimit,
+			memoryTempLimit:       dp.options.sysfsMemoryTempLimit,
+			correctableErrorLimit: dp.options.correctableErrorLimit,
+		}
 	}
 
-	return health
+	return nil
 }
 
-// Implement the PreferredAllocator interface.
-func (dp *devicePlugin) GetPreferredAllocation(rqt *pluginapi.PreferredAllocationRequest) (*pluginapi.PreferredAllocationResponse, error) {
+// GetPreferredDeviceAllocation implements the DevicePreferredAllocator interface.
+func (dp *devicePlugin) GetPreferredDeviceAllocation(rqt *pluginapi.PreferredAllocationRequest, devices map[string]dpapi.DeviceInfo) (*pluginapi.PreferredAllocationResponse, error) {
 	response := &pluginapi.PreferredAllocationResponse{}
 
 	for _, req := range rqt.ContainerRequests {
@@ -394,7 +481,22 @@ func (dp *devicePlugin) GetPreferredAllocation(rqt *pluginapi.PreferredAllocatio
 			return nil, err
 		}
 
-		IDs := dp.policy(req)
+		var IDs []string
+
+		if dp.fractional != nil && dp.fractional.handles(req.AvailableDeviceIDs) {
+			IDs = dp.fractional.preferred(req)
+		} else {
+			dp.mutex.RLock()
+			policy := dp.policy
+			dp.mutex.RUnlock()
+
+			areq, err := allocation.NewRequest(req, devices)
+			if err != nil {
+				return nil, err
+			}
+
+			IDs = policy(areq)
+		}
 
 		resp := &pluginapi.ContainerPreferredAllocationResponse{
 			DeviceIDs: IDs,
@@ -406,16 +508,17 @@ func (dp *devicePlugin) GetPreferredAllocation(rqt *pluginapi.PreferredAllocatio
 	return response, nil
 }
 
-func (dp *devicePlugin) Scan(notifier dpapi.Notifier) error {
+func (dp *devicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
 	if dp.options.wslScan {
-		return dp.wslGpuScan(notifier)
+		return dp.wslGpuScan(ctx, notifier)
 	} else {
-		return dp.sysFsGpuScan(notifier)
+		return dp.sysFsGpuScan(ctx, notifier)
 	}
 }
 
-func (dp *devicePlugin) wslGpuScan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *devicePlugin) wslGpuScan(ctx context.Context, notifier dpapi.Notifier) error {
+	trigger := uevent.NewTrigger(scanPeriod, "drm")
+	defer trigger.Stop()
 
 	klog.V(1).Infof("GPU (%s) resource share count = %d", deviceTypeDxg, dp.options.sharedDevNum)
 
@@ -442,6 +545,8 @@ func (dp *devicePlugin) wslGpuScan(notifier dpapi.Notifier) error {
 
 			devTree := dpapi.NewDeviceTree()
 
+			dp.mutex.RLock()
+
 			for _, index := range indices {
 				envs := map[string]string{
 					levelzeroAffinityMaskEnvVar: strconv.Itoa(int(index)),
@@ -455,21 +560,25 @@ func (dp *devicePlugin) wslGpuScan(notifier dpapi.Notifier) error {
 				}
 			}
 
+			dp.mutex.RUnlock()
+
 			notifier.Notify(devTree)
 		} else {
 			klog.Warning("Failed to get Intel indices from Level-Zero")
 		}
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-trigger.C:
+		case <-dp.rescan:
 		}
 	}
 }
 
-func (dp *devicePlugin) sysFsGpuScan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *devicePlugin) sysFsGpuScan(ctx context.Context, notifier dpapi.Notifier) error {
+	trigger := uevent.NewTrigger(scanPeriod, "drm")
+	defer trigger.Stop()
 
 	klog.V(1).Infof("GPU (%s/%s) resource share count = %d", deviceTypeI915, deviceTypeXe, dp.options.sharedDevNum)
 
@@ -480,7 +589,10 @@ func (dp *devicePlugin) sysFsGpuScan(notifier dpapi.Notifier) error {
 		monitorResourceCombined:        0}
 
 	for {
+		dp.mutex.RLock()
 		devTree, err := dp.scan()
+		dp.mutex.RUnlock()
+
 		if err != nil {
 			klog.Warning("Failed to scan: ", err)
 		}
@@ -506,9 +618,10 @@ func (dp *devicePlugin) sysFsGpuScan(notifier dpapi.Notifier) error {
 		}
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-trigger.C:
+		case <-dp.rescan:
 		}
 	}
 }
@@ -708,6 +821,7 @@ func (dp *devicePlugin) scan() (dpapi.DeviceTree, error) {
 	}
 
 	monitor := make(map[string][]pluginapi.DeviceSpec, 0)
+	fractionalCards := make(map[string]*fractionalCard)
 
 	devTree := dpapi.NewDeviceTree()
 	devProps := newDeviceProperties()
@@ -730,13 +844,23 @@ func (dp *devicePlugin) scan() (dpapi.DeviceTree, error) {
 
 		mounts, cdiDevices := dp.createMountsAndCDIDevices(cardPath, name, devSpecs)
 
-		health := dp.healthStatusForCard(cardPath)
+		health, reason := dp.healthStatusForCard(cardPath)
 
 		deviceInfo := dpapi.NewDeviceInfo(health, devSpecs, mounts, nil, nil, cdiDevices)
+		deviceInfo.SetHealthReason(reason)
+		dp.setDeviceAttributes(&deviceInfo, cardPath, name, devProps.driver())
+
+		if dp.options.resourceGranularity == granularityTile {
+			dp.addTileDevices(devTree, cardPath, name, devProps.driver(), health, reason, devSpecs)
+		} else {
+			for i := 0; i < dp.options.sharedDevNum; i++ {
+				devID := fmt.Sprintf("%s-%d", name, i)
+				devTree.AddDevice(devProps.driver(), devID, deviceInfo)
+			}
+		}
 
-		for i := 0; i < dp.options.sharedDevNum; i++ {
-			devID := fmt.Sprintf("%s-%d", name, i)
-			devTree.AddDevice(devProps.driver(), devID, deviceInfo)
+		if dp.fractional != nil {
+			fractionalCards[name] = dp.fractional.newCard(cardPath, health, devSpecs, mounts, deviceInfo)
 		}
 
 		if dp.options.enableMonitoring {
@@ -756,6 +880,10 @@ func (dp *devicePlugin) scan() (dpapi.DeviceTree, error) {
 		}
 	}
 
+	if dp.fractional != nil {
+		dp.fractional.setCards(devTree, fractionalCards)
+	}
+
 	// all Intel GPUs are under single monitoring resource per KMD
 	if len(monitor) > 0 {
 		for resourceName, devices := range monitor {
@@ -769,17 +897,30 @@ func (dp *devicePlugin) scan() (dpapi.DeviceTree, error) {
 }
 
 func (dp *devicePlugin) Allocate(request *pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
+	if dp.fractional != nil && len(request.ContainerRequests) > 0 &&
+		dp.fractional.handles(request.ContainerRequests[0].DevicesIds) {
+		return dp.fractional.allocate(request)
+	}
+
 	return nil, &dpapi.UseDefaultMethodError{}
 }
 
+// SetDeviceOwners implements the DeviceOwnersUser interface. The owners
+// release the fractional resources of removed containers.
+func (dp *devicePlugin) SetDeviceOwners(owners *dpapi.DeviceOwners) {
+	if dp.fractional != nil {
+		dp.fractional.setOwners(owners)
+	}
+}
+
 func checkBasics(opts cliOptions) error {
 	if opts.sharedDevNum < 1 {
 		return newArgError("the number of containers sharing the same GPU must greater than zero")
 	}
 
 	var str = opts.preferredAllocationPolicy
-	if !(str == "balanced" || str == "packed" || str == "none") {
-		return newArgError("invalid value for preferredAllocationPolicy, the valid values: balanced, packed, none")
+	if !(str == "balanced" || str == "packed" || str == "xelink" || str == "none") {
+		return newArgError("invalid value for preferredAllocationPolicy, the valid values: balanced, packed, xelink, none")
 	}
 
 	if len(opts.allowIDs) > 0 && len(opts.denyIDs) > 0 {
@@ -805,25 +946,54 @@ func checkBasics(opts cliOptions) error {
 	return nil
 }
 
-func checkArgs(opts cliOptions) error {
-	if err := checkBasics(opts); err != nil {
-		return fmt.Errorf("%w", err)
+func checkGranularity(opts cliOptions) error {
+	switch opts.resourceGranularity {
+	case granularityCard:
+	case granularityTile:
+		if opts.wslScan {
+			return newArgError("tile resources are not supported within WSL.")
+		}
+	default:
+		return newArgError(fmt.Sprintf("invalid value for resource-granularity, valid values: %s, %s",
+			granularityCard, granularityTile))
+	}
+
+	return nil
+}
+
+func checkFractional(opts cliOptions) error {
+	if !opts.fractionalResources {
+		return nil
 	}
 
 	if opts.wslScan {
-		if opts.enableMonitoring {
-			return newArgError("monitoring is not supported within WSL.")
-		}
+		return newArgError("fractional resources are not supported within WSL.")
+	}
 
-		if opts.healthManagement || opts.xpumdEndpoint != "" {
-			return newArgError("health management is not supported within WSL.")
-		}
+	if opts.memoryUnitMiB < 1 {
+		return newArgError("the memory unit of fractional resources must be greater than zero")
 	}
 
+	if opts.millicoreUnit < 1 || millicoresPerGPU%opts.millicoreUnit != 0 {
+		return newArgError(fmt.Sprintf("the millicore unit of fractional resources must divide %d", millicoresPerGPU))
+	}
+
+	return nil
+}
+
+func checkHealth(opts cliOptions) error {
 	if opts.healthManagement && opts.xpumdEndpoint != "" {
 		return newArgError("cannot use both Level-Zero sidecar and xpumd for health management.")
 	}
 
+	if opts.sysfsHealth && (opts.healthManagement || opts.xpumdEndpoint != "") {
+		return newArgError("cannot use sysfs health management with the Level-Zero sidecar or xpumd.")
+	}
+
+	if opts.sysfsTempLimit < 0 || opts.sysfsMemoryTempLimit < 0 || opts.correctableErrorLimit < 0 {
+		return newArgError("sysfs health limits cannot be negative")
+	}
+
 	if opts.xpumdEndpoint != "" {
 		if opts.globalTempLimit != defaultTempLimit ||
 			opts.gpuTempLimit != defaultTempLimit ||
@@ -835,6 +1005,36 @@ func checkArgs(opts cliOptions) error {
 	return nil
 }
 
+func checkArgs(opts cliOptions) error {
+	if err := checkBasics(opts); err != nil {
+		return fmt.Errorf("%w", err)
+	}
+
+	if opts.wslScan {
+		if opts.enableMonitoring {
+			return newArgError("monitoring is not supported within WSL.")
+		}
+
+		if opts.healthManagement || opts.xpumdEndpoint != "" || opts.sysfsHealth {
+			return newArgError("health management is not supported within WSL.")
+		}
+	}
+
+	if err := checkGranularity(opts); err != nil {
+		return err
+	}
+
+	if err := checkFractional(opts); err != nil {
+		return err
+	}
+
+	if err := checkHealth(opts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func main() {
 	var (
 		prefix string
@@ -846,17 +1046,27 @@ func main() {
 	flag.StringVar(&opts.monitoringMode, "monitoring-mode", monitoringModeSingle, "monitoring resource mode when --enable-monitoring is set: single (combined gpu.intel.com/monitoring resource) or split (per-driver i915_monitoring/xe_monitoring resources)")
 	flag.BoolVar(&opts.healthManagement, "health-management", false, "enable Level-Zero sidecar based GPU health management")
 	flag.StringVar(&opts.xpumdEndpoint, "xpumd-endpoint", "", "enable xpumd based health management. Argument is unix socket path for the xpumd health service (e.g. /run/xpumd/intelxpuinfo.sock). When set, health data is retrieved from xpumd")
+	flag.BoolVar(&opts.sysfsHealth, "sysfs-health", false, "enable GPU health management based on sysfs and debugfs, without a sidecar")
+	flag.IntVar(&opts.sysfsTempLimit, "sysfs-temp-limit", defaultTempLimit, "hwmon temperature limit at which device is marked unhealthy. Use with sysfs-health.")
+	flag.IntVar(&opts.sysfsMemoryTempLimit, "sysfs-memory-temp-limit", defaultTempLimit, "hwmon memory temperature limit at which device is marked unhealthy. Use with sysfs-health.")
+	flag.IntVar(&opts.correctableErrorLimit, "correctable-error-limit", 0, "number of correctable RAS errors at which device is marked unhealthy, 0 for no limit. Use with sysfs-health.")
 	flag.StringVar(&opts.bypathMount, "bypath", bypathOptionSingle, "DRI device 'by-path/' directory mounting options: single, none, all. Default: single")
 	flag.BoolVar(&opts.wslScan, "wsl", false, "scan for / use WSL devices")
 	flag.IntVar(&opts.sharedDevNum, "shared-dev-num", 1, "number of containers sharing the same GPU device.")
 	flag.IntVar(&opts.globalTempLimit, "temp-limit", defaultTempLimit, "Global temperature limit at which device is marked unhealthy. Use with health-managmement.")
 	flag.IntVar(&opts.gpuTempLimit, "gpu-temp-limit", defaultTempLimit, "GPU temperature limit at which device is marked unhealthy. Use with health-managmement.")
 	flag.IntVar(&opts.memoryTempLimit, "memory-temp-limit", defaultTempLimit, "Memory temperature limit at which device is marked unhealthy. Use with health-managmement.")
-	flag.StringVar(&opts.preferredAllocationPolicy, "allocation-policy", "none", "modes of allocating GPU devices: balanced, packed and none")
+	flag.StringVar(&opts.preferredAllocationPolicy, "allocation-policy", "none", "modes of allocating GPU devices: balanced, packed, xelink and none")
+	flag.BoolVar(&opts.numaAligned, "numa-aligned", false, "whether to allocate GPU devices from a single NUMA node when possible, combined with allocation-policy")
+	flag.StringVar(&opts.xeLinkFile, "xe-link-file", defaultXeLinkFile, "label file of the XPU Manager sidecar with the Xe Links for the xelink allocation policy")
 	flag.StringVar(&opts.allowIDs, "allow-ids", "", "comma-separated list of device IDs to allow (e.g. 0x49c5,0x49c6)")
+	flag.StringVar(&opts.resourceGranularity, "resource-granularity", granularityCard, "granularity of the GPU resources: card (i915/xe resources) or tile (i915_tile/xe_tile resources with a device per GPU tile)")
+	flag.BoolVar(&opts.fractionalResources, "fractional-resources", false, "whether to enable the memory.max and millicores resources for sharing GPUs by their local memory and compute")
+	flag.IntVar(&opts.memoryUnitMiB, "memory-unit-mib", defaultMemoryUnitMiB, "MiB of GPU local memory in one unit of the memory.max resource")
+	flag.IntVar(&opts.millicoreUnit, "millicore-unit", defaultMillicoreUnit, "millicores of a GPU in one unit of the millicores resource, dividing 1000")
 	flag.StringVar(&opts.denyIDs, "deny-ids", "", "comma-separated list of device IDs to deny (e.g. 0x49c5,0x49c6)")
 
-	flag.Parse()
+	dpapi.ParseFlags()
 
 	klog.V(1).Infof("GPU device plugin started with %s preferred allocation policy", opts.preferredAllocationPolicy)
 
@@ -872,7 +1082,15 @@ func main() {
 	setupLevelZeroService(plugin)
 
 	manager := dpapi.NewManager(namespace, plugin)
-	manager.Run()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
 
 func setupLevelZeroService(plugin *devicePlugin) {
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path"
@@ -38,7 +39,7 @@ func init() {
 
 // mockNotifier implements Notifier interface.
 type mockNotifier struct {
-	scanDone         chan bool
+	cancel           context.CancelFunc
 	i915Count        int
 	xeCount          int
 	dxgCount         int
@@ -56,7 +57,7 @@ func (n *mockNotifier) Notify(newDeviceTree dpapi.DeviceTree) {
 	n.i915monitorCount = len(newDeviceTree[deviceTypeDefault+monitorSuffix])
 	n.gpuMonitorCount = len(newDeviceTree[monitorResourceCombined])
 
-	n.scanDone <- true
+	n.cancel()
 }
 
 type mockL0Service struct {
@@ -206,6 +207,92 @@ func TestAllocate(t *testing.T) {
 	}
 }
 
+func TestReconfigure(t *testing.T) {
+	tcases := []struct {
+		options        map[string]string
+		name           string
+		expected       cliOptions
+		expectedErr    bool
+		expectedRescan bool
+	}{
+		{
+			name:           "share count and allow list",
+			options:        map[string]string{"shared-dev-num": "3", "allow-ids": "0x56a0,0x56a1"},
+			expected:       cliOptions{sharedDevNum: 3, allowIDs: "0x56a0,0x56a1", preferredAllocationPolicy: "none", monitoringMode: monitoringModeSingle},
+			expectedRescan: true,
+		},
+		{
+			name:           "allocation policy",
+			options:        map[string]string{"allocation-policy": "packed"},
+			expected:       cliOptions{sharedDevNum: 2, preferredAllocationPolicy: "packed", monitoringMode: monitoringModeSingle},
+			expectedRescan: true,
+		},
+		{
+			name:           "NUMA aligned allocation",
+			options:        map[string]string{"numa-aligned": "true"},
+			expected:       cliOptions{sharedDevNum: 2, preferredAllocationPolicy: "none", monitoringMode: monitoringModeSingle, numaAligned: true},
+			expectedRescan: true,
+		},
+		{
+			name:        "invalid NUMA alignment",
+			options:     map[string]string{"numa-aligned": "maybe"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid share count",
+			options:     map[string]string{"shared-dev-num": "0"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid allocation policy",
+			options:     map[string]string{"shared-dev-num": "3", "allocation-policy": "random"},
+			expectedErr: true,
+		},
+		{
+			name:        "both allow and deny lists",
+			options:     map[string]string{"allow-ids": "0x56a0", "deny-ids": "0x56a1"},
+			expectedErr: true,
+		},
+		{
+			name:        "restart needed",
+			options:     map[string]string{"enable-monitoring": "true"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			initial := cliOptions{sharedDevNum: 2, preferredAllocationPolicy: "none", monitoringMode: monitoringModeSingle}
+			plugin := newDevicePlugin("", "", initial)
+
+			err := plugin.Reconfigure(tc.options)
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				if plugin.options != initial {
+					t.Errorf("options changed on error: %+v", plugin.options)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if plugin.options != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, plugin.options)
+			}
+
+			if rescan := len(plugin.rescan) == 1; rescan != tc.expectedRescan {
+				t.Errorf("expected rescan %v, got %v", tc.expectedRescan, rescan)
+			}
+		})
+	}
+}
+
 func TestScan(t *testing.T) {
 	tcases := []TestCaseDetails{
 		{
@@ -532,11 +619,13 @@ func TestScan(t *testing.T) {
 
 			plugin := newDevicePlugin(sysfs, devfs, tc.options)
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			notifier := &mockNotifier{
-				scanDone: plugin.scanDone,
+				cancel: cancel,
 			}
 
-			err = plugin.Scan(notifier)
+			err = plugin.Scan(ctx, notifier)
 			// Scans in GPU plugin never fail
 			if err != nil {
 				t.Errorf("Unexpected error: %+v", err)
@@ -674,11 +763,13 @@ func TestScanWithHealth(t *testing.T) {
 
 			plugin.levelzeroService = tc.l0mock
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			notifier := &mockNotifier{
-				scanDone: plugin.scanDone,
+				cancel: cancel,
 			}
 
-			err = plugin.Scan(notifier)
+			err = plugin.Scan(ctx, notifier)
 			// Scans in GPU plugin never fail
 			if err != nil {
 				t.Errorf("unexpected error: %+v", err)
@@ -736,11 +827,13 @@ func TestScanWsl(t *testing.T) {
 			plugin.options.wslScan = true
 			plugin.levelzeroService = tc.l0mock
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			notifier := &mockNotifier{
-				scanDone: plugin.scanDone,
+				cancel: cancel,
 			}
 
-			err = plugin.Scan(notifier)
+			err = plugin.Scan(ctx, notifier)
 			// Scans in GPU plugin never fail
 			if err != nil {
 				t.Errorf("unexpected error: %+v", err)
@@ -1059,8 +1152,7 @@ func TestCDIDeviceInclusion(t *testing.T) {
 		t.Error("Failed to get device id for card")
 	}
 
-	refTree := dpapi.NewDeviceTree()
-	refTree.AddDevice("i915", "card0-0", dpapi.NewDeviceInfo("Healthy", []v1beta1.DeviceSpec{
+	i915Info := dpapi.NewDeviceInfo("Healthy", []v1beta1.DeviceSpec{
 		{ContainerPath: devfs + "/dri/card0", HostPath: devfs + "/dri/card0", Permissions: "rw"},
 		{ContainerPath: devfs + "/dri/renderD128", HostPath: devfs + "/dri/renderD128", Permissions: "rw"},
 	}, []v1beta1.Mount{
@@ -1094,8 +1186,12 @@ func TestCDIDeviceInclusion(t *testing.T) {
 				},
 			},
 		},
-	}))
-	refTree.AddDevice("xe", "card1-0", dpapi.NewDeviceInfo("Healthy", []v1beta1.DeviceSpec{
+	})
+	i915Info.SetAttribute(dpapi.AttributeDriver, dpapi.StringAttribute("i915"))
+	i915Info.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute("0042:01:02.0"))
+	i915Info.SetAttribute(dpapi.AttributePCIDeviceID, dpapi.StringAttribute("0x9a49"))
+
+	xeInfo := dpapi.NewDeviceInfo("Healthy", []v1beta1.DeviceSpec{
 		{ContainerPath: devfs + "/dri/card1", HostPath: devfs + "/dri/card1", Permissions: "rw"},
 		{ContainerPath: devfs + "/dri/renderD129", HostPath: devfs + "/dri/renderD129", Permissions: "rw"},
 	}, []v1beta1.Mount{
@@ -1129,7 +1225,14 @@ func TestCDIDeviceInclusion(t *testing.T) {
 				},
 			},
 		},
-	}))
+	})
+	xeInfo.SetAttribute(dpapi.AttributeDriver, dpapi.StringAttribute("xe"))
+	xeInfo.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute("0042:01:05.0"))
+	xeInfo.SetAttribute(dpapi.AttributePCIDeviceID, dpapi.StringAttribute("0x9a48"))
+
+	refTree := dpapi.NewDeviceTree()
+	refTree.AddDevice("i915", "card0-0", i915Info)
+	refTree.AddDevice("xe", "card1-0", xeInfo)
 
 	if !reflect.DeepEqual(tree, refTree) {
 		t.Error("Received device tree isn't expected\n", tree, "\n", refTree)
@@ -1438,6 +1541,40 @@ func TestCheckArgs(t *testing.T) {
 			},
 			expectErrStr: "temperature limits do not work with xpumd health source",
 		},
+		{
+			name: "sysfs health",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				sysfsHealth:               true,
+				sysfsTempLimit:            90,
+				correctableErrorLimit:     10,
+			},
+			expectErrStr: "",
+		},
+		{
+			name: "sysfs health and xpumd at the same time",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				sysfsHealth:               true,
+				xpumdEndpoint:             "/foo/bar/socket.sock",
+			},
+			expectErrStr: "cannot use sysfs health management with the Level-Zero sidecar or xpumd",
+		},
+		{
+			name: "sysfs health error with negative limit",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				sysfsHealth:               true,
+				correctableErrorLimit:     -1,
+			},
+			expectErrStr: "sysfs health limits cannot be negative",
+		},
 		{
 			name: "wsl",
 			options: cliOptions{
@@ -1489,9 +1626,80 @@ func TestCheckArgs(t *testing.T) {
 			},
 			expectErrStr: "invalid value for monitoring-mode, valid values",
 		},
+		{
+			name: "invalid resource granularity",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				resourceGranularity:       "foobar",
+			},
+			expectErrStr: "invalid value for resource-granularity, valid values",
+		},
+		{
+			name: "tile resources in WSL",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				resourceGranularity:       granularityTile,
+				wslScan:                   true,
+			},
+			expectErrStr: "tile resources are not supported within WSL",
+		},
+		{
+			name: "fractional resources in WSL",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				fractionalResources:       true,
+				memoryUnitMiB:             defaultMemoryUnitMiB,
+				wslScan:                   true,
+			},
+			expectErrStr: "fractional resources are not supported within WSL",
+		},
+		{
+			name: "bad memory unit",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				fractionalResources:       true,
+			},
+			expectErrStr: "the memory unit of fractional resources must be greater than zero",
+		},
+		{
+			name: "bad millicore unit",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				fractionalResources:       true,
+				memoryUnitMiB:             defaultMemoryUnitMiB,
+				millicoreUnit:             300,
+			},
+			expectErrStr: "the millicore unit of fractional resources must divide 1000",
+		},
+		{
+			name: "fractional resources",
+			options: cliOptions{
+				sharedDevNum:              1,
+				preferredAllocationPolicy: "none",
+				monitoringMode:            "single",
+				fractionalResources:       true,
+				memoryUnitMiB:             defaultMemoryUnitMiB,
+				millicoreUnit:             defaultMillicoreUnit,
+			},
+			expectErrStr: "",
+		},
 	}
 
 	for _, tc := range tcases {
+		if tc.options.resourceGranularity == "" {
+			tc.options.resourceGranularity = granularityCard
+		}
+
 		t.Run(tc.name, func(t *testing.T) {
 			ret := checkArgs(tc.options)
 			if tc.expectErrStr == "" && ret != nil {
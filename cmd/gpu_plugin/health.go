@@ -0,0 +1,263 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/gpu_plugin/levelzeroservice"
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/gpu_plugin/xpumdservice"
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/pluginutils"
+)
+
+const (
+	// hwmon temperatures are in millidegrees Celsius.
+	milliCelsius = 1000
+	// Prefix of the RAS error counters of correctable errors, the other
+	// counters are of uncorrectable errors.
+	correctableErrorPrefix = "correctable"
+)
+
+// The reasons of unhealthy cards.
+const (
+	reasonHardwareFailure     = "HardwareFailure"
+	reasonTemperatureCritical = "TemperatureCritical"
+	reasonXpumdUnhealthy      = "XpumdUnhealthy"
+	reasonWedged              = "Wedged"
+	reasonUncorrectableErrors = "UncorrectableErrors"
+	reasonCorrectableErrors   = "CorrectableErrors"
+	reasonHealthDataMissing   = "HealthDataMissing"
+)
+
+// healthSource tells why a card is unhealthy, an empty reason for healthy
+// cards. Each source has its own limits for the health data. A card with no
+// health data is healthy.
+type healthSource interface {
+	cardHealth(cardPath string) (string, error)
+}
+
+// levelzeroHealth checks the card health with the Level-Zero sidecar.
+type levelzeroHealth struct {
+	service levelzeroservice.LevelzeroService
+	limits  tempLimits
+}
+
+type tempLimits struct {
+	gpu, memory, global int
+}
+
+func (h levelzeroHealth) cardHealth(cardPath string) (string, error) {
+	bdfAddr, ok := bdfForCard(cardPath)
+	if !ok {
+		return "", nil
+	}
+
+	dh, err := h.service.GetDeviceHealth(bdfAddr)
+	if err != nil {
+		return "", errors.Wrap(err, "device health retrieval failed")
+	}
+
+	// Direct Health indicators
+	klog.V(4).Infof("Health indicators: Memory=%t, Bus=%t, SoC=%t", dh.Memory, dh.Bus, dh.SoC)
+
+	if !dh.Memory || !dh.Bus || !dh.SoC {
+		return reasonHardwareFailure, nil
+	}
+
+	deviceTemps, err := h.service.GetDeviceTemperature(bdfAddr)
+	if err != nil {
+		return "", errors.Wrap(err, "device temperature retrieval failed")
+	}
+
+	// Temperatures for different areas
+	klog.V(4).Infof("Temperatures: Memory=%dC, GPU=%dC, Global=%dC",
+		deviceTemps.Memory, deviceTemps.GPU, deviceTemps.Global)
+
+	if deviceTemps.GPU > h.limits.gpu ||
+		deviceTemps.Global > h.limits.global ||
+		deviceTemps.Memory > h.limits.memory {
+		return reasonTemperatureCritical, nil
+	}
+
+	return "", nil
+}
+
+// xpumdHealth checks the card health with xpumd. The limits are in the
+// xpumd configuration.
+type xpumdHealth struct {
+	service xpumdservice.XpumdService
+}
+
+func (h xpumdHealth) cardHealth(cardPath string) (string, error) {
+	bdfAddr, ok := bdfForCard(cardPath)
+	if !ok {
+		return "", nil
+	}
+
+	healthy, err := h.service.GetDeviceHealth(bdfAddr)
+	if err != nil {
+		return "", errors.Wrap(err, "xpumd device health retrieval failed")
+	}
+
+	klog.V(4).Infof("xpumd health for %s: Healthy=%t", bdfAddr, healthy)
+
+	if !healthy {
+		return reasonXpumdUnhealthy, nil
+	}
+
+	return "", nil
+}
+
+// sysfsHealth checks the card health from sysfs and debugfs without any
+// sidecar. A card is unhealthy when it is wedged, when a hwmon temperature
+// is over its limit or when it has uncorrectable RAS errors or more
+// correctable ones than the limit. The health data that can't be read
+// makes the card unhealthy. A -health-failure-threshold over 1, as in the
+// sysfs-health overlay, keeps a single failed read from taking the card away.
+type sysfsHealth struct {
+	debugfsDriDir         string
+	tempLimit             int
+	memoryTempLimit       int
+	correctableErrorLimit int
+}
+
+func (h sysfsHealth) cardHealth(cardPath string) (string, error) {
+	// The xe driver has no wedged state and has the GTs under the tiles.
+	gts := filepath.Join(cardPath, "device", "tile*", "gt*")
+
+	if driver, _ := pluginutils.ReadDeviceDriver(cardPath); driver != deviceTypeXe {
+		wedged, err := h.wedged(filepath.Base(cardPath))
+		if err != nil {
+			return reasonHealthDataMissing, err
+		}
+
+		if wedged {
+			klog.V(4).Infof("%s is wedged", cardPath)
+
+			return reasonWedged, nil
+		}
+
+		gts = filepath.Join(cardPath, "gt", "gt*")
+	}
+
+	if reason, err := h.temperatures(cardPath); err != nil || reason != "" {
+		return reason, err
+	}
+
+	return h.rasErrors(cardPath, gts)
+}
+
+// wedged tells whether the i915 driver has given up resetting the card.
+// The state is in debugfs, which is named by the card minor number. The
+// state is not checked when debugfs is not available.
+func (h sysfsHealth) wedged(card string) (bool, error) {
+	value, err := readValue(filepath.Join(h.debugfsDriDir, strings.TrimPrefix(card, "card"), "i915_wedged"))
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		return false, nil
+	}
+
+	return value != 0, err
+}
+
+// temperatures checks the temperatures of the hwmon sensors of the card.
+// The sensors labeled as memory have their own limit.
+func (h sysfsHealth) temperatures(cardPath string) (string, error) {
+	inputs, err := filepath.Glob(filepath.Join(cardPath, "device", "hwmon", "hwmon*", "temp*_input"))
+	if err != nil {
+		return reasonHealthDataMissing, errors.Wrap(err, "invalid hwmon path")
+	}
+
+	for _, input := range inputs {
+		temp, err := readValue(input)
+		if err != nil {
+			return reasonHealthDataMissing, err
+		}
+
+		limit := h.tempLimit
+		label := "temp"
+
+		if data, err := os.ReadFile(strings.TrimSuffix(input, "_input") + "_label"); err == nil {
+			label = strings.TrimSpace(string(data))
+		}
+
+		if label == "vram" || strings.HasPrefix(label, "mem") {
+			limit = h.memoryTempLimit
+		}
+
+		klog.V(4).Infof("Temperature %s of %s: %dC", label, cardPath, temp/milliCelsius)
+
+		if temp > int64(limit)*milliCelsius {
+			return reasonTemperatureCritical, nil
+		}
+	}
+
+	return "", nil
+}
+
+// rasErrors checks the RAS error counters of the GTs of the card.
+func (h sysfsHealth) rasErrors(cardPath, gts string) (string, error) {
+	counters, err := filepath.Glob(filepath.Join(gts, "error_counter", "*"))
+	if err != nil {
+		return reasonHealthDataMissing, errors.Wrap(err, "invalid error counter path")
+	}
+
+	var correctable, uncorrectable int64
+
+	for _, counter := range counters {
+		count, err := readValue(counter)
+		if err != nil {
+			return reasonHealthDataMissing, err
+		}
+
+		if strings.HasPrefix(filepath.Base(counter), correctableErrorPrefix) {
+			correctable += count
+		} else {
+			uncorrectable += count
+		}
+	}
+
+	klog.V(4).Infof("RAS errors of %s: correctable=%d, uncorrectable=%d", cardPath, correctable, uncorrectable)
+
+	if uncorrectable > 0 {
+		return reasonUncorrectableErrors, nil
+	}
+
+	if h.correctableErrorLimit > 0 && correctable > int64(h.correctableErrorLimit) {
+		return reasonCorrectableErrors, nil
+	}
+
+	return "", nil
+}
+
+func readValue(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value in %s", path)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/gpufake"
+)
+
+// healthFiles returns the health files of card1 generated by gpu_fakedev
+// with a few more sensors and counters, relative to sysfs.
+func healthFiles() map[string]string {
+	files := gpufake.HealthFiles(1, 2, 0)
+
+	maps.Copy(files, map[string]string{
+		"class/drm/card1/device/hwmon/hwmon1/temp2_input":             "45000",
+		"class/drm/card1/device/hwmon/hwmon1/temp2_label":             "vram",
+		"class/drm/card1/device/hwmon/hwmon1/temp3_input":             "-5000",
+		"class/drm/card1/device/hwmon/hwmon1/temp3_label":             "pcie",
+		"class/drm/card1/device/hwmon/hwmon1/power1_input":            "50000000",
+		"class/drm/card1/gt/gt0/error_counter/correctable_l3_sng":     "0",
+		"class/drm/card1/gt/gt1/error_counter/soc_nonfatal_psf_csc_0": "0",
+	})
+
+	return files
+}
+
+// xeHealthFiles returns the health files of an xe card1 with two tiles,
+// relative to sysfs.
+func xeHealthFiles() map[string]string {
+	return map[string]string{
+		"class/drm/card1/device/hwmon/hwmon1/temp1_input":                       "40000",
+		"class/drm/card1/device/hwmon/hwmon1/temp1_label":                       "pkg",
+		"class/drm/card1/device/hwmon/hwmon1/temp2_input":                       "45000",
+		"class/drm/card1/device/hwmon/hwmon1/temp2_label":                       "vram",
+		"class/drm/card1/device/tile0/gt0/error_counter/correctable_eu_grf":     "0",
+		"class/drm/card1/device/tile0/gt0/error_counter/fatal_eu_grf":           "0",
+		"class/drm/card1/device/tile1/gt1/error_counter/correctable_eu_grf":     "0",
+		"class/drm/card1/device/tile1/gt1/error_counter/soc_nonfatal_psf_csc_0": "0",
+	}
+}
+
+func TestSysfsHealth(t *testing.T) {
+	tcases := []struct {
+		files          map[string]string
+		name           string
+		driver         string
+		expectedReason string
+		errorLimit     int
+		expectedErr    bool
+	}{
+		{
+			name: "healthy card",
+		},
+		{
+			name:           "wedged card",
+			files:          map[string]string{"kernel/debug/dri/1/i915_wedged": "1"},
+			expectedReason: reasonWedged,
+		},
+		{
+			name:           "hot card",
+			files:          map[string]string{"class/drm/card1/device/hwmon/hwmon1/temp1_input": "95000"},
+			expectedReason: reasonTemperatureCritical,
+		},
+		{
+			name:  "hot memory under its limit",
+			files: map[string]string{"class/drm/card1/device/hwmon/hwmon1/temp2_input": "95000"},
+		},
+		{
+			name:           "hot memory",
+			files:          map[string]string{"class/drm/card1/device/hwmon/hwmon1/temp2_input": "101000"},
+			expectedReason: reasonTemperatureCritical,
+		},
+		{
+			name:           "uncorrectable errors",
+			files:          map[string]string{"class/drm/card1/gt/gt1/error_counter/soc_nonfatal_psf_csc_0": "1"},
+			expectedReason: reasonUncorrectableErrors,
+		},
+		{
+			name:  "correctable errors without limit",
+			files: map[string]string{"class/drm/card1/gt/gt0/error_counter/correctable_eu_grf": "100"},
+		},
+		{
+			name: "correctable errors under the limit",
+			files: map[string]string{
+				"class/drm/card1/gt/gt0/error_counter/correctable_eu_grf": "5",
+				"class/drm/card1/gt/gt1/error_counter/correctable_eu_grf": "5",
+			},
+			errorLimit: 10,
+		},
+		{
+			name: "correctable errors over the limit",
+			files: map[string]string{
+				"class/drm/card1/gt/gt0/error_counter/correctable_eu_grf": "5",
+				"class/drm/card1/gt/gt0/error_counter/correctable_l3_sng": "6",
+			},
+			errorLimit:     10,
+			expectedReason: reasonCorrectableErrors,
+		},
+		{
+			name:           "invalid counter",
+			files:          map[string]string{"class/drm/card1/gt/gt0/error_counter/fatal_eu_grf": "many"},
+			expectedReason: reasonHealthDataMissing,
+			expectedErr:    true,
+		},
+		{
+			name:           "invalid temperature",
+			files:          map[string]string{"class/drm/card1/device/hwmon/hwmon1/temp2_input": ""},
+			expectedReason: reasonHealthDataMissing,
+			expectedErr:    true,
+		},
+		{
+			name:   "healthy xe card",
+			driver: deviceTypeXe,
+		},
+		{
+			name:   "xe card without wedged state",
+			driver: deviceTypeXe,
+			files:  map[string]string{"kernel/debug/dri/1/i915_wedged": "1"},
+		},
+		{
+			name:           "hot xe memory",
+			driver:         deviceTypeXe,
+			files:          map[string]string{"class/drm/card1/device/hwmon/hwmon1/temp2_input": "101000"},
+			expectedReason: reasonTemperatureCritical,
+		},
+		{
+			name:           "xe uncorrectable errors",
+			driver:         deviceTypeXe,
+			files:          map[string]string{"class/drm/card1/device/tile1/gt1/error_counter/soc_nonfatal_psf_csc_0": "2"},
+			expectedReason: reasonUncorrectableErrors,
+		},
+		{
+			name:   "xe correctable errors over the limit",
+			driver: deviceTypeXe,
+			files: map[string]string{
+				"class/drm/card1/device/tile0/gt0/error_counter/correctable_eu_grf": "6",
+				"class/drm/card1/device/tile1/gt1/error_counter/correctable_eu_grf": "6",
+			},
+			errorLimit:     10,
+			expectedReason: reasonCorrectableErrors,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sysfs := t.TempDir()
+
+			files := healthFiles()
+			if tc.driver == deviceTypeXe {
+				files = xeHealthFiles()
+			}
+
+			maps.Copy(files, tc.files)
+
+			for name, value := range files {
+				path := filepath.Join(sysfs, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(path, []byte(value), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tc.driver != "" {
+				if err := os.Symlink(filepath.Join("..", "..", "..", "bus", "pci", "drivers", tc.driver), filepath.Join(sysfs, "class/drm/card1/device/driver")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			plugin := newDevicePlugin(sysfs, "", cliOptions{
+				sharedDevNum:          1,
+				sysfsHealth:           true,
+				sysfsTempLimit:        90,
+				sysfsMemoryTempLimit:  100,
+				correctableErrorLimit: tc.errorLimit,
+			})
+
+			cardPath := filepath.Join(plugin.sysfsDrmDir, "card1")
+
+			reason, err := plugin.healthSource().cardHealth(cardPath)
+			if (err != nil) != tc.expectedErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+
+			expectedStatus := v1beta1.Healthy
+			if tc.expectedReason != "" {
+				expectedStatus = v1beta1.Unhealthy
+			}
+
+			if status, _ := plugin.healthStatusForCard(cardPath); status != expectedStatus {
+				t.Errorf("expected %s, got %s", expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestSysfsHealthWithoutHealthData(t *testing.T) {
+	plugin := newDevicePlugin(t.TempDir(), "", cliOptions{sharedDevNum: 1, sysfsHealth: true})
+
+	reason, err := plugin.healthSource().cardHealth(filepath.Join(plugin.sysfsDrmDir, "card0"))
+	if err != nil || reason != "" {
+		t.Errorf("expected a healthy card, got %q, %v", reason, err)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/kubeletstub"
+)
+
+func TestKubeletRegistration(t *testing.T) {
+	// Socket paths have to be short.
+	root, err := os.MkdirTemp("/tmp", "gpukubelet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	sysfs, devfs, err := createTestFiles(root, TestCaseDetails{
+		sysfsdirs: []string{
+			"card0/device/drm/card0", "card0/device/drm/renderD128",
+			"card1/device/drm/card1", "card1/device/drm/renderD129",
+		},
+		sysfsfiles: map[string][]byte{
+			"card0/device/vendor": []byte("0x8086"),
+			"card1/device/vendor": []byte("0x8086"),
+		},
+		devfsdirs: []string{"card0", "renderD128", "card1", "renderD129"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kubeletDir := filepath.Join(root, "kubelet")
+	if err = os.Mkdir(kubeletDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	kubelet := kubeletstub.New(kubeletDir)
+	if err = kubelet.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer kubelet.Stop()
+
+	plugin := newDevicePlugin(sysfs, devfs, cliOptions{sharedDevNum: 1, preferredAllocationPolicy: "none"})
+
+	manager := dpapi.NewManager(namespace, plugin)
+	manager.SetDevicePluginPath(kubeletDir)
+	manager.SetCDIDir(filepath.Join(root, "cdi"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	runErr := make(chan error, 1)
+
+	go func() {
+		runErr <- manager.Run(ctx)
+	}()
+
+	resourceName := namespace + "/" + deviceTypeI915
+
+	if _, err = kubelet.WaitForDevices(ctx, resourceName, kubeletstub.HealthyDevices(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := kubelet.Registrations(resourceName); n != 1 {
+		t.Errorf("expected 1 registration of %s, got %d", resourceName, n)
+	}
+
+	if options, _ := kubelet.Options(resourceName); options == nil || !options.GetPreferredAllocationAvailable {
+		t.Errorf("expected the preferred allocation to be available, got %+v", options)
+	}
+
+	resp, err := kubelet.Allocate(ctx, resourceName, []string{"card1-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		filepath.Join(devfs, "dri", "card1"):      true,
+		filepath.Join(devfs, "dri", "renderD129"): true,
+	}
+
+	devices := resp.ContainerResponses[0].Devices
+	if len(devices) != len(expected) {
+		t.Errorf("expected %d device nodes, got %+v", len(expected), devices)
+	}
+
+	for _, dev := range devices {
+		if !expected[dev.HostPath] {
+			t.Errorf("unexpected device node allocated: %s", dev.HostPath)
+		}
+	}
+
+	if _, err = kubelet.Allocate(ctx, resourceName, []string{"card2-0"}); err == nil {
+		t.Error("unknown GPU allocated")
+	}
+
+	cancel()
+
+	if err = <-runErr; err != nil {
+		t.Errorf("manager failed: %+v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/labeler"
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+)
+
+const (
+	// Resource granularity options.
+	granularityCard = "card"
+	granularityTile = "tile"
+
+	tileSuffix = "_tile"
+
+	// Prefix of the envs of the allocated tiles, which are combined to
+	// ZE_AFFINITY_MASK in PostAllocate.
+	tileEnvPrefix = levelzeroAffinityMaskEnvVar + "_"
+)
+
+func tileID(card string, tile uint64) string {
+	return fmt.Sprintf("%s-tile%d", card, tile)
+}
+
+// renderNodes returns the render nodes among the device nodes of a card.
+func renderNodes(devSpecs []pluginapi.DeviceSpec) []pluginapi.DeviceSpec {
+	specs := []pluginapi.DeviceSpec{}
+
+	for idx := range devSpecs {
+		if strings.HasPrefix(filepath.Base(devSpecs[idx].HostPath), "renderD") {
+			specs = append(specs, pluginapi.DeviceSpec{
+				HostPath:      devSpecs[idx].HostPath,
+				ContainerPath: devSpecs[idx].ContainerPath,
+				Permissions:   devSpecs[idx].Permissions,
+			})
+		}
+	}
+
+	return specs
+}
+
+// addTileDevices adds a device per tile of the card with the render node
+// of the card to devTree. The tiles are of the <driver>_tile resource.
+func (dp *devicePlugin) addTileDevices(devTree dpapi.DeviceTree, cardPath, name, driver, health, reason string, devSpecs []pluginapi.DeviceSpec) {
+	render := renderNodes(devSpecs)
+	if len(render) == 0 {
+		klog.Warningf("No render node found for %s, tiles not available", name)
+
+		return
+	}
+
+	mounts, cdiDevices := dp.createMountsAndCDIDevices(cardPath, name, render)
+	tiles := labeler.GetTileCount(cardPath)
+
+	for tile := range tiles {
+		envs := map[string]string{
+			fmt.Sprintf("%s%s_%d", tileEnvPrefix, name, tile): fmt.Sprintf("%s.%d", strings.TrimPrefix(name, "card"), tile),
+		}
+
+		deviceInfo := dpapi.NewDeviceInfo(health, render, mounts, envs, nil, cdiDevices)
+		deviceInfo.SetHealthReason(reason)
+		dp.setDeviceAttributes(&deviceInfo, cardPath, name, driver)
+
+		devTree.AddDevice(driver+tileSuffix, tileID(name, tile), deviceInfo)
+	}
+}
+
+// PostAllocate implements the PostAllocator interface. The envs of the
+// allocated tiles are combined to ZE_AFFINITY_MASK with the cards renumbered
+// in the order Level-Zero enumerates them in the container. The render nodes
+// shared by the tiles of a card are given only once.
+func (dp *devicePlugin) PostAllocate(response *pluginapi.AllocateResponse) error {
+	for _, cresp := range response.GetContainerResponses() {
+		type cardTile struct {
+			card, tile int
+		}
+
+		tiles := []cardTile{}
+
+		for key, value := range cresp.Envs {
+			if !strings.HasPrefix(key, tileEnvPrefix) {
+				continue
+			}
+
+			delete(cresp.Envs, key)
+
+			var t cardTile
+			if _, err := fmt.Sscanf(value, "%d.%d", &t.card, &t.tile); err != nil {
+				klog.Warningf("Invalid tile %q: %v", value, err)
+
+				continue
+			}
+
+			tiles = append(tiles, t)
+		}
+
+		if len(tiles) == 0 {
+			continue
+		}
+
+		slices.SortFunc(tiles, func(a, b cardTile) int {
+			if a.card != b.card {
+				return a.card - b.card
+			}
+
+			return a.tile - b.tile
+		})
+
+		mask := []string{}
+		index := -1
+
+		for i, t := range tiles {
+			if i == 0 || t.card != tiles[i-1].card {
+				index++
+			}
+
+			mask = append(mask, strconv.Itoa(index)+"."+strconv.Itoa(t.tile))
+		}
+
+		cresp.Envs[levelzeroAffinityMaskEnvVar] = strings.Join(mask, ",")
+
+		devices := make(map[string]bool)
+		cresp.Devices = slices.DeleteFunc(cresp.Devices, func(spec *pluginapi.DeviceSpec) bool {
+			found := devices[spec.HostPath]
+			devices[spec.HostPath] = true
+
+			return found
+		})
+
+		mounts := make(map[string]bool)
+		cresp.Mounts = slices.DeleteFunc(cresp.Mounts, func(mount *pluginapi.Mount) bool {
+			found := mounts[mount.ContainerPath]
+			mounts[mount.ContainerPath] = true
+
+			return found
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestTileScan(t *testing.T) {
+	tc := TestCaseDetails{
+		sysfsdirs: []string{
+			"card0/device/drm/card0", "card0/device/drm/renderD128", "card0/gt/gt0", "card0/gt/gt1",
+			"card1/device/drm/card1", "card1/device/drm/renderD129",
+			"card2/device/drm/card2",
+		},
+		sysfsfiles: map[string][]byte{
+			"card0/device/vendor": []byte("0x8086"),
+			"card1/device/vendor": []byte("0x8086"),
+			"card2/device/vendor": []byte("0x8086"),
+		},
+		devfsdirs: []string{"card0", "renderD128", "card1", "renderD129", "card2"},
+	}
+
+	sysfs, devfs, err := createTestFiles(t.TempDir(), tc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plugin := newDevicePlugin(sysfs, devfs, cliOptions{
+		sharedDevNum:        1,
+		resourceGranularity: granularityTile,
+	})
+
+	tree, err := plugin.scan()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := tree.DeviceTypeCount(deviceTypeI915); count != 0 {
+		t.Errorf("expected no %s devices, got %d", deviceTypeI915, count)
+	}
+
+	tiles := tree[deviceTypeI915+tileSuffix]
+
+	expected := map[string]string{
+		tileID("card0", 0): devfs + "/dri/renderD128",
+		tileID("card0", 1): devfs + "/dri/renderD128",
+		tileID("card1", 0): devfs + "/dri/renderD129",
+	}
+	if len(tiles) != len(expected) {
+		t.Errorf("expected tiles %v, got %v", expected, tiles)
+	}
+
+	for id, renderNode := range expected {
+		tile, found := tiles[id]
+		if !found {
+			t.Errorf("tile %s not found", id)
+
+			continue
+		}
+
+		if nodes := tile.DeviceNodes(); len(nodes) != 1 || nodes[0].HostPath != renderNode {
+			t.Errorf("expected %s for %s, got %v", renderNode, id, nodes)
+		}
+	}
+}
+
+func TestPostAllocate(t *testing.T) {
+	render := func(card string) *v1beta1.DeviceSpec {
+		return &v1beta1.DeviceSpec{HostPath: "/dev/dri/renderD" + card, ContainerPath: "/dev/dri/renderD" + card}
+	}
+
+	tcases := []struct {
+		envs            map[string]string
+		expectedEnvs    map[string]string
+		name            string
+		devices         []*v1beta1.DeviceSpec
+		expectedDevices []*v1beta1.DeviceSpec
+	}{
+		{
+			name:            "no tiles",
+			envs:            map[string]string{"FOO": "bar"},
+			devices:         []*v1beta1.DeviceSpec{render("128")},
+			expectedEnvs:    map[string]string{"FOO": "bar"},
+			expectedDevices: []*v1beta1.DeviceSpec{render("128")},
+		},
+		{
+			name: "tiles of one card",
+			envs: map[string]string{
+				tileEnvPrefix + "card3_1": "3.1",
+				tileEnvPrefix + "card3_0": "3.0",
+			},
+			devices:         []*v1beta1.DeviceSpec{render("131"), render("131")},
+			expectedEnvs:    map[string]string{levelzeroAffinityMaskEnvVar: "0.0,0.1"},
+			expectedDevices: []*v1beta1.DeviceSpec{render("131")},
+		},
+		{
+			name: "tiles of many cards",
+			envs: map[string]string{
+				tileEnvPrefix + "card10_0": "10.0",
+				tileEnvPrefix + "card2_1":  "2.1",
+				tileEnvPrefix + "card9_1":  "9.1",
+				tileEnvPrefix + "card10_1": "10.1",
+				"FOO":                      "bar",
+			},
+			devices:         []*v1beta1.DeviceSpec{render("138"), render("130"), render("137"), render("138")},
+			expectedEnvs:    map[string]string{levelzeroAffinityMaskEnvVar: "0.1,1.1,2.0,2.1", "FOO": "bar"},
+			expectedDevices: []*v1beta1.DeviceSpec{render("138"), render("130"), render("137")},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := newDevicePlugin("", "", cliOptions{sharedDevNum: 1})
+			response := &v1beta1.AllocateResponse{
+				ContainerResponses: []*v1beta1.ContainerAllocateResponse{{Envs: tc.envs, Devices: tc.devices}},
+			}
+
+			if err := plugin.PostAllocate(response); err != nil {
+				t.Fatal(err)
+			}
+
+			cresp := response.ContainerResponses[0]
+
+			if !reflect.DeepEqual(cresp.Envs, tc.expectedEnvs) {
+				t.Errorf("expected envs %v, got %v", tc.expectedEnvs, cresp.Envs)
+			}
+
+			if len(cresp.Devices) != len(tc.expectedDevices) {
+				t.Fatalf("expected devices %v, got %v", tc.expectedDevices, cresp.Devices)
+			}
+
+			for i := range cresp.Devices {
+				if cresp.Devices[i].HostPath != tc.expectedDevices[i].HostPath {
+					t.Errorf("expected devices %v, got %v", tc.expectedDevices, cresp.Devices)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,462 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	// Label file written by the XPU Manager sidecar.
+	defaultXeLinkFile    = "/etc/kubernetes/node-feature-discovery/features.d/xpum-sidecar-labels.txt"
+	xeLinkLabelName      = "xe-links"
+	xeLinkCardsLabelName = "xe-link-cards"
+	labelControlChar     = "Z"
+
+	// Lanes of a link between the tiles of a card, which are connected
+	// inside the package faster than with any Xe Link.
+	sameCardLanes = 1 << 16
+)
+
+// gpuTile is a tile of a card. The tile is -1 for the whole card.
+type gpuTile struct {
+	card, tile int
+}
+
+// xeLinks has the lane counts of the Xe Links between the tiles of the cards.
+type xeLinks map[gpuTile]map[gpuTile]int
+
+func (l xeLinks) add(a, b gpuTile, lanes int) {
+	for _, end := range [][2]gpuTile{{a, b}, {b, a}} {
+		if _, found := l[end[0]]; !found {
+			l[end[0]] = make(map[gpuTile]int)
+		}
+
+		l[end[0]][end[1]] += lanes
+	}
+}
+
+// lanes returns the lanes between two tiles or cards. The lanes between
+// cards are the lanes between all their tiles.
+func (l xeLinks) lanes(a, b gpuTile) int {
+	if a.card == b.card {
+		return sameCardLanes
+	}
+
+	lanes := 0
+
+	for from, links := range l {
+		if from.card != a.card || (a.tile >= 0 && from.tile != a.tile) {
+			continue
+		}
+
+		for to, count := range links {
+			if to.card == b.card && (b.tile < 0 || to.tile == b.tile) {
+				lanes += count
+			}
+		}
+	}
+
+	return lanes
+}
+
+// xeLinkProvider provides the Xe Links between the GPUs.
+type xeLinkProvider interface {
+	XeLinks() (xeLinks, error)
+}
+
+// xeLinkLabelFile reads the Xe Links from the label file of the XPU Manager
+// sidecar. The links in the labels don't have lane counts, each of them
+// counts as one lane. The links are between XPU Manager devices, which are
+// mapped to the cards with the xe-link-cards label.
+type xeLinkLabelFile struct {
+	path string
+}
+
+// splitLabels returns the labels of a label file by their names without
+// the namespace. The values split to name, name2, name3... are joined.
+func splitLabels(data []byte) map[string]string {
+	chunks := make(map[string]map[int]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		name, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found {
+			continue
+		}
+
+		_, name, _ = strings.Cut(name, "/")
+
+		index := 1
+		base := strings.TrimRight(name, "0123456789")
+
+		if base != name {
+			var err error
+			if index, err = strconv.Atoi(name[len(base):]); err != nil {
+				continue
+			}
+
+			value = strings.TrimPrefix(value, labelControlChar)
+		}
+
+		if _, found := chunks[base]; !found {
+			chunks[base] = make(map[int]string)
+		}
+
+		chunks[base][index] = value
+	}
+
+	labels := make(map[string]string)
+
+	for name, values := range chunks {
+		var value strings.Builder
+
+		for i := 1; i <= len(values); i++ {
+			value.WriteString(values[i])
+		}
+
+		labels[name] = value.String()
+	}
+
+	return labels
+}
+
+// XeLinks implements the xeLinkProvider interface.
+func (f xeLinkLabelFile) XeLinks() (xeLinks, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read Xe Link labels")
+	}
+
+	labels := splitLabels(data)
+
+	cards := make(map[int]int)
+
+	for deviceCard := range strings.SplitSeq(labels[xeLinkCardsLabelName], "_") {
+		if deviceCard == "" {
+			continue
+		}
+
+		var device, card int
+		if _, err := fmt.Sscanf(deviceCard, "%d.%d", &device, &card); err != nil {
+			return nil, errors.Wrapf(err, "invalid Xe Link device card %q", deviceCard)
+		}
+
+		cards[device] = card
+	}
+
+	links := make(xeLinks)
+
+	for link := range strings.SplitSeq(labels[xeLinkLabelName], "_") {
+		if link == "" {
+			continue
+		}
+
+		var a, b gpuTile
+		if _, err := fmt.Sscanf(link, "%d.%d-%d.%d", &a.card, &a.tile, &b.card, &b.tile); err != nil {
+			return nil, errors.Wrapf(err, "invalid Xe Link %q", link)
+		}
+
+		cardA, foundA := cards[a.card]
+		cardB, foundB := cards[b.card]
+
+		if !foundA || !foundB {
+			klog.Warningf("Skipping Xe Link %q: no card for XPU Manager device", link)
+
+			continue
+		}
+
+		a.card, b.card = cardA, cardB
+
+		links.add(a, b, 1)
+	}
+
+	return links, nil
+}
+
+// allocationUnit is a card or a tile with its available device IDs.
+type allocationUnit struct {
+	name string
+	ids  []string
+	gpuTile
+}
+
+// allocationUnits groups the device IDs by their cards or tiles.
+func allocationUnits(deviceIDs []string) []*allocationUnit {
+	units := make(map[string]*allocationUnit)
+
+	for _, id := range deviceIDs {
+		parts := strings.Split(id, "-")
+		name := parts[0]
+		t := gpuTile{tile: -1}
+
+		if _, err := fmt.Sscanf(parts[0], "card%d", &t.card); err != nil {
+			klog.Warningf("Invalid device ID %q: %v", id, err)
+
+			continue
+		}
+
+		if len(parts) > 1 && strings.HasPrefix(parts[1], "tile") {
+			name = parts[0] + "-" + parts[1]
+
+			if _, err := fmt.Sscanf(parts[1], "tile%d", &t.tile); err != nil {
+				klog.Warningf("Invalid device ID %q: %v", id, err)
+
+				continue
+			}
+		}
+
+		if _, found := units[name]; !found {
+			units[name] = &allocationUnit{name: name, gpuTile: t}
+		}
+
+		units[name].ids = append(units[name].ids, id)
+	}
+
+	sorted := make([]*allocationUnit, 0, len(units))
+	for _, unit := range units {
+		sort.Strings(unit.ids)
+		sorted = append(sorted, unit)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].card != sorted[j].card {
+			return sorted[i].card < sorted[j].card
+		}
+
+		return sorted[i].tile < sorted[j].tile
+	})
+
+	return sorted
+}
+
+// xeLinkPolicy prefers cards or tiles which are all connected to each
+// other with the most Xe Link lanes. Without such, the cards or tiles are
+// taken from a single NUMA node if possible.
+type xeLinkPolicy struct {
+	links    xeLinkProvider
+	numaNode func(card int) int
+}
+
+func (p *xeLinkPolicy) allocate(req *pluginapi.ContainerPreferredAllocationRequest) []string {
+	klog.V(2).Info("Select xeLinkPolicy for GPU device allocation")
+
+	units := allocationUnits(req.AvailableDeviceIDs)
+	size := int(req.AllocationSize)
+
+	links, err := p.links.XeLinks()
+	if err != nil {
+		klog.Warningf("No Xe Links for allocation: %+v", err)
+	} else if deviceIDs := connectedSet(units, links, req.MustIncludeDeviceIDs, size); deviceIDs != nil {
+		klog.V(2).Infof("Allocate Xe Link connected deviceIds: %q", deviceIDs)
+
+		return deviceIDs
+	}
+
+	if deviceIDs := p.numaLocalSet(units, req.MustIncludeDeviceIDs, size); deviceIDs != nil {
+		klog.V(2).Infof("Allocate NUMA local deviceIds: %q", deviceIDs)
+
+		return deviceIDs
+	}
+
+	return nonePolicy(req)
+}
+
+// selection collects the device IDs of the selected units, a device ID per
+// unit besides the ones that must be included.
+type selection struct {
+	units     []*allocationUnit
+	deviceIDs []string
+}
+
+func newSelection(units []*allocationUnit, mustInclude []string) *selection {
+	s := &selection{deviceIDs: slices.Clone(mustInclude)}
+
+	for _, unit := range units {
+		for _, id := range unit.ids {
+			if slices.Contains(mustInclude, id) && !slices.Contains(s.units, unit) {
+				s.units = append(s.units, unit)
+			}
+		}
+	}
+
+	return s
+}
+
+func (s *selection) add(unit *allocationUnit) {
+	s.units = append(s.units, unit)
+	s.deviceIDs = append(s.deviceIDs, unit.ids[0])
+}
+
+// connectedSet returns size device IDs of the cards or tiles connected to
+// each other with the most lanes, or nil if there are no such cards or tiles.
+func connectedSet(units []*allocationUnit, links xeLinks, mustInclude []string, size int) []string {
+	var (
+		best      []string
+		bestLanes = -1
+	)
+
+	for _, seed := range seeds(units, mustInclude) {
+		s := newSelection(units, mustInclude)
+		for _, unit := range seed {
+			s.add(unit)
+		}
+
+		s.expand(units, links, size)
+
+		if len(s.deviceIDs) < size {
+			continue
+		}
+
+		if lanes := s.lanes(links); lanes > bestLanes {
+			best, bestLanes = s.deviceIDs, lanes
+		}
+	}
+
+	return best
+}
+
+// seeds returns the units to start the selections from: none when some
+// devices must be included, otherwise each unit in turn.
+func seeds(units []*allocationUnit, mustInclude []string) [][]*allocationUnit {
+	if len(mustInclude) > 0 {
+		return [][]*allocationUnit{nil}
+	}
+
+	single := make([][]*allocationUnit, 0, len(units))
+	for _, unit := range units {
+		single = append(single, []*allocationUnit{unit})
+	}
+
+	return single
+}
+
+// expand adds the unit with the most lanes to the selected units until
+// size device IDs are selected or no unit is linked to all selected units.
+func (s *selection) expand(units []*allocationUnit, links xeLinks, size int) {
+	for len(s.deviceIDs) < size {
+		next, nextLanes := (*allocationUnit)(nil), -1
+
+		for _, unit := range units {
+			if slices.Contains(s.units, unit) {
+				continue
+			}
+
+			if lanes := s.lanesTo(unit, links); lanes > nextLanes {
+				next, nextLanes = unit, lanes
+			}
+		}
+
+		if next == nil {
+			return
+		}
+
+		s.add(next)
+	}
+}
+
+// lanesTo returns the lanes between unit and the selected units, or -1 if
+// unit is not linked to all of them.
+func (s *selection) lanesTo(unit *allocationUnit, links xeLinks) int {
+	lanes := 0
+
+	for _, selected := range s.units {
+		l := links.lanes(unit.gpuTile, selected.gpuTile)
+		if l == 0 {
+			return -1
+		}
+
+		lanes += l
+	}
+
+	return lanes
+}
+
+// lanes returns the lanes between all the selected units.
+func (s *selection) lanes(links xeLinks) int {
+	lanes := 0
+
+	for i, a := range s.units {
+		for _, b := range s.units[i+1:] {
+			lanes += links.lanes(a.gpuTile, b.gpuTile)
+		}
+	}
+
+	return lanes
+}
+
+// numaLocalSet returns size device IDs of the cards or tiles in the same
+// NUMA node, or nil if no NUMA node has enough of them.
+func (p *xeLinkPolicy) numaLocalSet(units []*allocationUnit, mustInclude []string, size int) []string {
+	nodes := make(map[int][]*allocationUnit)
+	for _, unit := range units {
+		node := p.numaNode(unit.card)
+		nodes[node] = append(nodes[node], unit)
+	}
+
+	ids := make([]int, 0, len(nodes))
+	for node := range nodes {
+		ids = append(ids, node)
+	}
+
+	sort.Ints(ids)
+
+	for _, node := range ids {
+		if node < 0 {
+			continue
+		}
+
+		s := newSelection(units, mustInclude)
+
+		for _, unit := range s.units {
+			if !slices.Contains(nodes[node], unit) {
+				s = nil
+
+				break
+			}
+		}
+
+		if s == nil {
+			continue
+		}
+
+		for _, unit := range nodes[node] {
+			if len(s.deviceIDs) >= size {
+				break
+			}
+
+			if !slices.Contains(s.units, unit) {
+				s.add(unit)
+			}
+		}
+
+		if len(s.deviceIDs) >= size {
+			return s.deviceIDs
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/internal/gpufake"
+)
+
+func writeSideCarLabels(t *testing.T, lines []string) string {
+	path := filepath.Join(t.TempDir(), "xpum-sidecar-labels.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(append([]string{"xpumanager.intel.com/other=value"}, lines...), "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+type fakeXeLinkProvider struct {
+	links xeLinks
+}
+
+func (p fakeXeLinkProvider) XeLinks() (xeLinks, error) {
+	if p.links == nil {
+		return nil, errors.New("no links")
+	}
+
+	return p.links, nil
+}
+
+func TestXeLinkLabelFile(t *testing.T) {
+	tcases := []struct {
+		expected map[[2]gpuTile]int
+		name     string
+		labels   []string
+	}{
+		{
+			name:   "8x2 fully connected",
+			labels: gpufake.SideCarLabels(gpufake.FullyConnected(8, 2), 8),
+			expected: map[[2]gpuTile]int{
+				{{0, 0}, {7, 1}}:   1,
+				{{3, -1}, {4, -1}}: 4,
+				{{5, 1}, {2, -1}}:  2,
+			},
+		},
+		{
+			name:   "2x4 fully connected",
+			labels: gpufake.SideCarLabels(gpufake.FullyConnected(2, 4), 2),
+			expected: map[[2]gpuTile]int{
+				{{0, 3}, {1, 2}}:   1,
+				{{0, -1}, {1, -1}}: 16,
+			},
+		},
+		{
+			name:   "partially connected",
+			labels: gpufake.SideCarLabels("1.0-0.0_3.0-2.0", 4),
+			expected: map[[2]gpuTile]int{
+				{{0, -1}, {1, -1}}: 1,
+				{{1, 0}, {2, 0}}:   0,
+				{{2, -1}, {3, 0}}:  1,
+			},
+		},
+		{
+			name: "devices mapped to cards",
+			labels: []string{
+				"xpumanager.intel.com/xe-links=0.0-1.0_0.1-1.1",
+				"xpumanager.intel.com/xe-link-cards=0.1_1.2",
+			},
+			expected: map[[2]gpuTile]int{
+				{{1, -1}, {2, -1}}: 2,
+				{{1, 1}, {2, 1}}:   1,
+				{{0, -1}, {1, -1}}: 0,
+			},
+		},
+		{
+			name:   "devices without cards",
+			labels: []string{"xpumanager.intel.com/xe-links=0.0-1.0_0.1-1.1"},
+			expected: map[[2]gpuTile]int{
+				{{0, -1}, {1, -1}}: 0,
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			links, err := xeLinkLabelFile{path: writeSideCarLabels(t, tc.labels)}.XeLinks()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for ends, expected := range tc.expected {
+				if lanes := links.lanes(ends[0], ends[1]); lanes != expected {
+					t.Errorf("expected %d lanes between %v and %v, got %d", expected, ends[0], ends[1], lanes)
+				}
+			}
+		})
+	}
+
+	if _, err := (xeLinkLabelFile{path: writeSideCarLabels(t, gpufake.SideCarLabels("0.0-1", 2))}).XeLinks(); err == nil {
+		t.Error("expected an error for an invalid link")
+	}
+}
+
+func TestXeLinkPolicy(t *testing.T) {
+	labels := func(connections string, gpus int) xeLinks {
+		links, err := xeLinkLabelFile{path: writeSideCarLabels(t, gpufake.SideCarLabels(connections, gpus))}.XeLinks()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return links
+	}
+
+	weighted := make(xeLinks)
+	weighted.add(gpuTile{0, 0}, gpuTile{1, 0}, 2)
+	weighted.add(gpuTile{0, 0}, gpuTile{2, 0}, 4)
+	weighted.add(gpuTile{1, 0}, gpuTile{2, 0}, 1)
+
+	tcases := []struct {
+		links       xeLinks
+		name        string
+		available   []string
+		mustInclude []string
+		expected    []string
+		size        int32
+	}{
+		{
+			name:      "connected pair",
+			links:     labels("1.0-0.0_3.0-2.0_2.0-1.0", 4),
+			available: []string{"card0-0", "card2-0", "card3-0"},
+			size:      2,
+			expected:  []string{"card2-0", "card3-0"},
+		},
+		{
+			name:      "pair with the most lanes",
+			links:     weighted,
+			available: []string{"card0-0", "card1-0", "card2-0"},
+			size:      2,
+			expected:  []string{"card0-0", "card2-0"},
+		},
+		{
+			name:        "connected to the devices to include",
+			links:       weighted,
+			available:   []string{"card0-0", "card1-0", "card2-0"},
+			mustInclude: []string{"card1-0"},
+			size:        2,
+			expected:    []string{"card1-0", "card0-0"},
+		},
+		{
+			name:      "tiles of the same card",
+			links:     labels(gpufake.FullyConnected(2, 2), 2),
+			available: []string{"card0-tile1", "card1-tile0", "card1-tile1"},
+			size:      2,
+			expected:  []string{"card1-tile0", "card1-tile1"},
+		},
+		{
+			name:        "tiles connected to the tile to include",
+			links:       labels(gpufake.FullyConnected(2, 2), 2),
+			available:   []string{"card0-tile0", "card0-tile1", "card1-tile0", "card1-tile1"},
+			mustInclude: []string{"card1-tile1"},
+			size:        3,
+			expected:    []string{"card1-tile1", "card1-tile0", "card0-tile0"},
+		},
+		{
+			name:      "NUMA local cards without links",
+			available: []string{"card0-0", "card1-0", "card2-0", "card3-0"},
+			size:      2,
+			expected:  []string{"card1-0", "card3-0"},
+		},
+		{
+			name:      "NUMA local cards without fully connected ones",
+			links:     labels("2.0-0.0", 4),
+			available: []string{"card0-0", "card1-0", "card3-0"},
+			size:      2,
+			expected:  []string{"card1-0", "card3-0"},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := &xeLinkPolicy{
+				links: fakeXeLinkProvider{links: tc.links},
+				// Odd cards in NUMA node 0, even ones in 1.
+				numaNode: func(card int) int { return 1 - card%2 },
+			}
+
+			ids := policy.allocate(&v1beta1.ContainerPreferredAllocationRequest{
+				AvailableDeviceIDs:   tc.available,
+				MustIncludeDeviceIDs: tc.mustInclude,
+				AllocationSize:       tc.size,
+			})
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, ids)
+			}
+		})
+	}
+}
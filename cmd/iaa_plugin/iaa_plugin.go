@@ -15,10 +15,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
 	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/idxd"
 
 	"k8s.io/klog/v2"
@@ -28,28 +32,46 @@ const (
 	// Device plugin settings.
 	namespace = "iaa.intel.com"
 	// Device directories.
-	devDir = "/dev/iax"
+	devDir     = "/dev/iax"
+	charDevDir = "/dev/char"
 	// Glob pattern for the state sysfs entry.
 	statePattern = "/sys/bus/dsa/devices/iax*/wq*/state"
 )
 
 func main() {
-	var sharedDevNum int
+	var (
+		prefix       string
+		sharedDevNum int
+	)
 
+	flag.StringVar(&prefix, "prefix", "", "Prefix for devfs & sysfs paths")
 	flag.IntVar(&sharedDevNum, "shared-dev-num", 1, "number of containers sharing the same work queue")
-	flag.Parse()
+	policy := flag.String(allocation.FlagName, allocation.None, allocation.Usage())
+	dpapi.ParseFlags()
 
 	if sharedDevNum < 1 {
 		klog.Warning("The number of containers sharing the same work queue must be greater than zero")
 		os.Exit(1)
 	}
 
-	plugin := idxd.NewDevicePlugin(statePattern, devDir, sharedDevNum)
+	plugin := idxd.NewDevicePlugin(prefix+statePattern, prefix+devDir, prefix+charDevDir, sharedDevNum)
 	if plugin == nil {
 		klog.Fatal("Cannot create device plugin, please check above error messages.")
 	}
 
+	if err := plugin.SetPolicy(*policy); err != nil {
+		klog.Warning(err)
+		os.Exit(1)
+	}
+
 	manager := dpapi.NewManager(namespace, plugin)
 
-	manager.Run()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,130 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gpufake generates the fake GPU data of gpu_fakedev, so that the
+// GPU plugin tests can use the same data.
+package gpufake
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxK8sLabelSize = 63
+	labelNamespace  = "xpumanager.intel.com"
+	// Label control character of the continuation labels.
+	labelControlChar = "Z"
+	// Xe Link labels of the XPU Manager sidecar.
+	XeLinkLabelName      = "xe-links"
+	XeLinkCardsLabelName = "xe-link-cards"
+	// Default hwmon temperature of the cards, in degrees Celsius.
+	DefaultTemp = 40
+)
+
+// HealthFiles returns the health files of an i915 card with tiles tiles,
+// relative to sysfs: the hwmon temperature, the RAS error counters of the
+// tiles and the wedged state in debugfs. The temperature is in degrees
+// Celsius, 0 gives DefaultTemp.
+func HealthFiles(card, tiles, temp int) map[string]string {
+	if temp == 0 {
+		temp = DefaultTemp
+	}
+
+	base := filepath.Join("class", "drm", fmt.Sprintf("card%d", card))
+	hwmon := filepath.Join(base, "device", "hwmon", fmt.Sprintf("hwmon%d", card))
+
+	files := map[string]string{
+		filepath.Join(hwmon, "temp1_input"):                                        strconv.Itoa(temp * 1000),
+		filepath.Join(hwmon, "temp1_label"):                                        "pkg",
+		filepath.Join("kernel", "debug", "dri", strconv.Itoa(card), "i915_wedged"): "0",
+	}
+
+	for tile := range tiles {
+		for _, name := range []string{"correctable_eu_grf", "fatal_eu_grf"} {
+			files[filepath.Join(base, "gt", fmt.Sprintf("gt%d", tile), "error_counter", name)] = "0"
+		}
+	}
+
+	return files
+}
+
+// FullyConnected returns the Xe Links of GPUs with all their tiles
+// connected to each other.
+func FullyConnected(gpus, tiles int) string {
+	var nodes = make([]string, 0, tiles*gpus)
+
+	for mm := range gpus {
+		for nn := range tiles {
+			nodes = append(nodes, fmt.Sprintf("%d.%d", mm, nn))
+		}
+	}
+
+	var links = make(map[string]bool, 0)
+
+	var smap = make([]string, 0)
+
+	for _, from := range nodes {
+		for _, to := range nodes {
+			// no self links, TODO ignore in-gpu xelinks
+			if to == from {
+				continue
+			}
+
+			link := fmt.Sprintf("%s-%s", to, from)
+
+			reverselink := fmt.Sprintf("%s-%s", from, to)
+			if _, exists := links[reverselink]; !exists {
+				links[link] = true
+
+				smap = append(smap, link)
+			}
+		}
+	}
+
+	return strings.Join(smap, "_")
+}
+
+// SideCarLabels returns the lines of the XPU Manager sidecar label file
+// for the connections of gpus cards. The XPU Manager devices are numbered
+// as the cards.
+func SideCarLabels(connections string, gpus int) []string {
+	cards := make([]string, 0, gpus)
+	for i := range gpus {
+		cards = append(cards, fmt.Sprintf("%d.%d", i, i))
+	}
+
+	lines := splitLabel(XeLinkLabelName, connections)
+
+	return append(lines, splitLabel(XeLinkCardsLabelName, strings.Join(cards, "_"))...)
+}
+
+// splitLabel splits a label value to labels fitting the k8s label size.
+// The first label is named by name and the next ones by name2, name3...
+func splitLabel(name, value string) []string {
+	// First line without Z prefix
+	lines := []string{fmt.Sprintf("%s/%s=%s", labelNamespace, name, value[:min(len(value), maxK8sLabelSize)])}
+
+	index := 2
+
+	// Next lines with Z prefix
+	for i := maxK8sLabelSize; i < len(value); i += (maxK8sLabelSize - 1) {
+		lines = append(lines, fmt.Sprintf("%s/%s%d=%s%s", labelNamespace, name, index, labelControlChar, value[i:min(len(value), i+maxK8sLabelSize-1)]))
+		index++
+	}
+
+	return lines
+}
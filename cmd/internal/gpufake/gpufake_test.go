@@ -0,0 +1,58 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gpufake
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSideCarLabels(t *testing.T) {
+	tcases := []struct {
+		name        string
+		connections string
+		expected    []string
+		gpus        int
+	}{
+		{
+			name:        "2x2 fully connected",
+			connections: FullyConnected(2, 2),
+			gpus:        2,
+			expected: []string{
+				"xpumanager.intel.com/xe-links=0.1-0.0_1.0-0.0_1.1-0.0_1.0-0.1_1.1-0.1_1.1-1.0",
+				"xpumanager.intel.com/xe-link-cards=0.0_1.1",
+			},
+		},
+		{
+			name:        "split links",
+			connections: strings.Repeat("1.0-0.0_", 8) + "1.0-0.0",
+			gpus:        1,
+			expected: []string{
+				"xpumanager.intel.com/xe-links=1.0-0.0_1.0-0.0_1.0-0.0_1.0-0.0_1.0-0.0_1.0-0.0_1.0-0.0_1.0-0.0",
+				"xpumanager.intel.com/xe-links2=Z_1.0-0.0",
+				"xpumanager.intel.com/xe-link-cards=0.0",
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if labels := SideCarLabels(tc.connections, tc.gpus); !reflect.DeepEqual(labels, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, labels)
+			}
+		})
+	}
+}
@@ -186,6 +186,13 @@ func legacyFallback(sysfsDrmDir, gpuName string, numTiles uint64) uint64 {
 	return totalPerTile*numTiles - reserved
 }
 
+// GetLocalMemoryAmount reads the local memory amount of the GPU from sysfs
+// without Level-Zero. GPU_MEMORY_OVERRIDE is used for GPUs without local
+// memory and GPU_MEMORY_RESERVED is subtracted from the amount.
+func GetLocalMemoryAmount(sysfsDrmDir, gpuName string, numTiles uint64) uint64 {
+	return legacyFallback(sysfsDrmDir, gpuName, numTiles)
+}
+
 func (l *labeler) GetMemoryAmount(sysfsDrmDir, gpuName string, numTiles uint64) uint64 {
 	link, err := os.Readlink(filepath.Join(sysfsDrmDir, gpuName, "device"))
 	if err != nil {
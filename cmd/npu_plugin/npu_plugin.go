@@ -15,13 +15,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
 	"regexp"
 	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
@@ -30,6 +33,8 @@ import (
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/uevent"
 )
 
 const (
@@ -42,8 +47,8 @@ const (
 	namespace     = "npu.intel.com"
 	deviceTypeNpu = "accel"
 
-	// Period of device scans.
-	scanPeriod = 5 * time.Second
+	// Period of device rescans when no accel uevents arrive.
+	scanPeriod = 30 * time.Second
 )
 
 var npuIDs = []string{
@@ -63,12 +68,11 @@ type cliOptions struct {
 type devicePlugin struct {
 	npuDeviceReg *regexp.Regexp
 
-	scanTicker *time.Ticker
-	scanDone   chan bool
-
 	sysfsDir string
 	devfsDir string
 
+	allocation.Allocator
+
 	options cliOptions
 }
 
@@ -78,15 +82,14 @@ func newDevicePlugin(sysfsDir, devfsDir string, options cliOptions) *devicePlugi
 		devfsDir:     devfsDir,
 		options:      options,
 		npuDeviceReg: regexp.MustCompile(npuDeviceRE),
-		scanTicker:   time.NewTicker(scanPeriod),
-		scanDone:     make(chan bool, 1), // buffered as we may send to it before Scan starts receiving from it
 	}
 
 	return dp
 }
 
-func (dp *devicePlugin) Scan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *devicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
+	trigger := uevent.NewTrigger(scanPeriod, "accel")
+	defer trigger.Stop()
 
 	klog.V(1).Infof("NPU (%s) resource share count = %d", deviceTypeNpu, dp.options.sharedDevNum)
 
@@ -110,9 +113,9 @@ func (dp *devicePlugin) Scan(notifier dpapi.Notifier) error {
 		notifier.Notify(devTree)
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-trigger.C:
 		}
 	}
 }
@@ -200,7 +203,8 @@ func main() {
 
 	flag.StringVar(&prefix, "prefix", "", "Prefix for devfs & sysfs paths")
 	flag.IntVar(&opts.sharedDevNum, "shared-dev-num", 1, "number of containers sharing the same NPU device")
-	flag.Parse()
+	policy := flag.String(allocation.FlagName, allocation.None, allocation.Usage())
+	dpapi.ParseFlags()
 
 	if opts.sharedDevNum < 1 {
 		klog.Error("The number of containers sharing the same NPU must greater than zero")
@@ -210,7 +214,19 @@ func main() {
 	klog.V(1).Infof("NPU device plugin started")
 
 	plugin := newDevicePlugin(prefix+sysfAccelDirectory, prefix+devfsAccelDirectory, opts)
+	if err := plugin.SetPolicy(*policy); err != nil {
+		klog.Error(err)
+		os.Exit(1)
+	}
 
 	manager := dpapi.NewManager(namespace, plugin)
-	manager.Run()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path"
@@ -28,7 +29,7 @@ import (
 
 // mockNotifier implements Notifier interface for NPU.
 type mockNotifier struct {
-	scanDone chan bool
+	cancel   context.CancelFunc
 	npuCount int
 }
 
@@ -36,7 +37,7 @@ type mockNotifier struct {
 func (n *mockNotifier) Notify(newDeviceTree dpapi.DeviceTree) {
 	n.npuCount = len(newDeviceTree[deviceTypeNpu])
 
-	n.scanDone <- true
+	n.cancel()
 }
 
 func init() {
@@ -210,11 +211,13 @@ func TestScan(t *testing.T) {
 
 			plugin := newDevicePlugin(sysfs, devfs, tc.options)
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			notifier := &mockNotifier{
-				scanDone: plugin.scanDone,
+				cancel: cancel,
 			}
 
-			err = plugin.Scan(notifier)
+			err = plugin.Scan(ctx, notifier)
 			// Scans in NPU plugin never fail
 			if err != nil {
 				t.Errorf("Unexpected error: %+v", err)
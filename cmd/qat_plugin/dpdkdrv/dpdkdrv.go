@@ -17,6 +17,7 @@ package dpdkdrv
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -34,6 +35,7 @@ import (
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/uevent"
 )
 
 const (
@@ -50,9 +52,13 @@ const (
 	igbUio  = "igb_uio"
 	vfioPci = "vfio-pci"
 
-	// Period of device scans.
+	// Period of device rescans in addition to the ones triggered by pci uevents.
+	// Kept short as the scans also refresh the heartbeat health of the devices.
 	scanPeriod = 5 * time.Second
 
+	// Health reason of the VFs of a PF whose heartbeat has failed.
+	heartbeatFailedReason = "HeartbeatFailed"
+
 	// Resource name to use when device capabilities are not available.
 	defaultCapabilities = "generic"
 )
@@ -114,9 +120,6 @@ func packedPolicy(req *pluginapi.ContainerPreferredAllocationRequest) []string {
 
 // DevicePlugin represents vfio based QAT plugin.
 type DevicePlugin struct {
-	scanTicker *time.Ticker
-	scanDone   chan bool
-
 	// Note: If restarting the plugin with a new policy, the allocations for existing pods remain with old policy.
 	policy preferredAllocationPolicyFunc
 
@@ -127,8 +130,9 @@ type DevicePlugin struct {
 	maxDevices      int
 }
 
-// NewDevicePlugin returns new instance of vfio based QAT plugin.
-func NewDevicePlugin(maxDevices int, kernelVfDrivers string, dpdkDriver string, preferredAllocationPolicy string) (*DevicePlugin, error) {
+// NewDevicePlugin returns new instance of vfio based QAT plugin. The sysfs
+// paths are prefixed with prefix, which is normally empty.
+func NewDevicePlugin(prefix string, maxDevices int, kernelVfDrivers string, dpdkDriver string, preferredAllocationPolicy string) (*DevicePlugin, error) {
 	if !isValidDpdkDeviceDriver(dpdkDriver) {
 		return nil, errors.Errorf("wrong DPDK device driver: %s", dpdkDriver)
 	}
@@ -145,7 +149,7 @@ func NewDevicePlugin(maxDevices int, kernelVfDrivers string, dpdkDriver string,
 		return nil, errors.Errorf("wrong allocation policy: %s", preferredAllocationPolicy)
 	}
 
-	return newDevicePlugin(pciDriverDirectory, pciDeviceDirectory, maxDevices, kernelDrivers, dpdkDriver, allocationPolicyFunc), nil
+	return newDevicePlugin(prefix+pciDriverDirectory, prefix+pciDeviceDirectory, maxDevices, kernelDrivers, dpdkDriver, allocationPolicyFunc), nil
 }
 
 // getAllocationPolicy returns a func that fits the policy given as a parameter. It returns nonePolicy when the flag is not set, and it returns nil when the policy is not valid value.
@@ -182,15 +186,14 @@ func newDevicePlugin(pciDriverDir, pciDeviceDir string, maxDevices int, kernelVf
 		pciDeviceDir:    pciDeviceDir,
 		kernelVfDrivers: kernelVfDrivers,
 		dpdkDriver:      dpdkDriver,
-		scanTicker:      time.NewTicker(scanPeriod),
-		scanDone:        make(chan bool, 1),
 		policy:          preferredAllocationPolicyFunc,
 	}
 }
 
 // Scan implements Scanner interface for vfio based QAT plugin.
-func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *DevicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
+	trigger := uevent.NewTrigger(scanPeriod, "pci")
+	defer trigger.Stop()
 
 	for {
 		devTree, err := dp.scan()
@@ -201,9 +204,9 @@ func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
 		notifier.Notify(devTree)
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-trigger.C:
 		}
 	}
 }
@@ -453,6 +456,20 @@ func getDeviceID(device string) (string, error) {
 	return strings.TrimPrefix(string(bytes.TrimSpace(devID)), "0x"), nil
 }
 
+// setDeviceAttributes sets the attributes of a VF device to devinfo.
+func (dp *DevicePlugin) setDeviceAttributes(devinfo *dpapi.DeviceInfo, vfDevice string) {
+	devinfo.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute(filepath.Base(vfDevice)))
+	devinfo.SetAttribute(dpapi.AttributeDriver, dpapi.StringAttribute(dp.dpdkDriver))
+
+	if devID, err := getDeviceID(vfDevice); err == nil {
+		devinfo.SetAttribute(dpapi.AttributePCIDeviceID, dpapi.StringAttribute("0x"+devID))
+	}
+
+	if pfDev, err := filepath.EvalSymlinks(filepath.Join(vfDevice, "physfn")); err == nil {
+		devinfo.SetAttribute(dpapi.AttributePhysicalFunction, dpapi.StringAttribute(filepath.Base(pfDev)))
+	}
+}
+
 func writeToDriver(path, value string) error {
 	if err := os.WriteFile(path, []byte(value), 0600); err != nil {
 		return errors.Wrapf(err, "write to driver failed: %s", value)
@@ -634,6 +651,11 @@ func (dp *DevicePlugin) scan() (dpapi.DeviceTree, error) {
 		}
 
 		devinfo := dpapi.NewDeviceInfo(healthiness, dp.getDpdkDeviceSpecs(dpdkDeviceName), dp.getDpdkMounts(dpdkDeviceName), envs, nil, nil)
+		dp.setDeviceAttributes(&devinfo, vfDevice)
+
+		if healthiness == pluginapi.Unhealthy {
+			devinfo.SetHealthReason(heartbeatFailedReason)
+		}
 
 		devTree.AddDevice(cap, vfBdf, devinfo)
 	}
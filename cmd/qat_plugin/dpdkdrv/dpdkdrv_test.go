@@ -15,6 +15,7 @@
 package dpdkdrv
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -98,7 +99,7 @@ func TestNewDevicePlugin(t *testing.T) {
 	}
 	for _, tt := range tcases {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := NewDevicePlugin(1, tt.kernelVfDrivers, tt.dpdkDriver, "")
+			_, err := NewDevicePlugin("", 1, tt.kernelVfDrivers, tt.dpdkDriver, "")
 
 			if tt.expectedErr && err == nil {
 				t.Errorf("Test case '%s': expected error", tt.name)
@@ -112,14 +113,14 @@ func TestNewDevicePlugin(t *testing.T) {
 
 // fakeNotifier implements Notifier interface.
 type fakeNotifier struct {
-	scanDone chan bool
-	tree     dpapi.DeviceTree
+	cancel context.CancelFunc
+	tree   dpapi.DeviceTree
 }
 
 // Notify stops plugin Scan.
 func (n *fakeNotifier) Notify(newDeviceTree dpapi.DeviceTree) {
 	n.tree = newDeviceTree
-	n.scanDone <- true
+	n.cancel()
 }
 
 func TestGetPreferredAllocation(t *testing.T) {
@@ -683,11 +684,13 @@ func TestScan(t *testing.T) {
 				nil,
 			)
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			fN := fakeNotifier{
-				scanDone: dp.scanDone,
+				cancel: cancel,
 			}
 
-			err = dp.Scan(&fN)
+			err = dp.Scan(ctx, &fN)
 
 			if tt.expectedErr && err == nil {
 				t.Errorf("expected error, but got success")
@@ -15,9 +15,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/intel/intel-device-plugins-for-kubernetes/cmd/qat_plugin/dpdkdrv"
 	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
@@ -29,13 +32,14 @@ const (
 )
 
 func main() {
+	prefix := flag.String("prefix", "", "Prefix for devfs & sysfs paths")
 	dpdkDriver := flag.String("dpdk-driver", "vfio-pci", "DPDK Device driver for configuring the QAT device")
 	kernelVfDrivers := flag.String("kernel-vf-drivers", "4xxxvf,420xxvf", "Comma separated VF Device Driver of the QuickAssist Devices in the system. Devices supported: DH895xCC, C62x, C3xxx, C4xxx, 4xxx, 420xxx, 6xxx, and D15xx")
 	preferredAllocationPolicy := flag.String("allocation-policy", "", "Modes of allocating QAT devices: balanced and packed")
 	maxNumDevices := flag.Int("max-num-devices", 64, "maximum number of QAT devices to be provided to the QuickAssist device plugin")
-	flag.Parse()
+	deviceplugin.ParseFlags()
 
-	plugin, err := dpdkdrv.NewDevicePlugin(*maxNumDevices, *kernelVfDrivers, *dpdkDriver, *preferredAllocationPolicy)
+	plugin, err := dpdkdrv.NewDevicePlugin(*prefix, *maxNumDevices, *kernelVfDrivers, *dpdkDriver, *preferredAllocationPolicy)
 	if err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
@@ -45,5 +49,12 @@ func main() {
 
 	manager := deviceplugin.NewManager(namespace, plugin)
 
-	manager.Run()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
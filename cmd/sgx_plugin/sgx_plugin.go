@@ -15,12 +15,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path"
 	"runtime"
 	"strconv"
+	"syscall"
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
 	"k8s.io/klog/v2"
@@ -39,7 +42,6 @@ const (
 )
 
 type devicePlugin struct {
-	scanDone           chan bool
 	devfsDir           string
 	nEnclave           uint
 	nProvision         uint
@@ -52,11 +54,10 @@ func newDevicePlugin(devfsDir string, nEnclave, nProvision uint, dcapInfraResour
 		nEnclave:           nEnclave,
 		nProvision:         nProvision,
 		dcapInfraResources: dcapInfraResources,
-		scanDone:           make(chan bool, 1),
 	}
 }
 
-func (dp *devicePlugin) Scan(notifier dpapi.Notifier) error {
+func (dp *devicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
 	devTree, err := dp.scan()
 	if err != nil {
 		return err
@@ -64,8 +65,8 @@ func (dp *devicePlugin) Scan(notifier dpapi.Notifier) error {
 
 	notifier.Notify(devTree)
 
-	// Wait forever to prevent manager run loop from exiting.
-	<-dp.scanDone
+	// Wait until canceled to prevent manager run loop from exiting.
+	<-ctx.Done()
 
 	return nil
 }
@@ -163,16 +164,27 @@ func main() {
 
 	var dcapInfraResources bool
 
+	var prefix string
+
 	podCount := getDefaultPodCount(uint(runtime.NumCPU()))
 
+	flag.StringVar(&prefix, "prefix", "", "Prefix for devfs paths")
 	flag.UintVar(&enclaveLimit, "enclave-limit", podCount, "Number of \"enclave\" resources")
 	flag.UintVar(&provisionLimit, "provision-limit", podCount, "Number of \"provision\" resources")
 	flag.BoolVar(&dcapInfraResources, "dcap-infra-resources", false, "Deprecated: Register special resources for Intel DCAP infrastructure containers. This will be removed/modified in the future.")
-	flag.Parse()
+	dpapi.ParseFlags()
 
 	klog.V(4).Infof("SGX device plugin started with %d \"%s/enclave\" resources and %d \"%s/provision\" resources.", enclaveLimit, namespace, provisionLimit, namespace)
 
-	plugin := newDevicePlugin(devicePath, enclaveLimit, provisionLimit, dcapInfraResources)
+	plugin := newDevicePlugin(prefix+devicePath, enclaveLimit, provisionLimit, dcapInfraResources)
 	manager := dpapi.NewManager(namespace, plugin)
-	manager.Run()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := manager.Run(ctx); err != nil {
+		klog.Errorf("Device plugin failed: %+v", err)
+		stop()
+		os.Exit(1)
+	}
 }
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path"
@@ -32,7 +33,7 @@ const dcapInfraResources = 2
 
 // mockNotifier implements Notifier interface.
 type mockNotifier struct {
-	scanDone          chan bool
+	cancel            context.CancelFunc
 	enclaveDevCount   int
 	provisionDevCount int
 	dcapInfraResCnt   int
@@ -43,7 +44,7 @@ func (n *mockNotifier) Notify(newDeviceTree dpapi.DeviceTree) {
 	n.enclaveDevCount = len(newDeviceTree[deviceTypeEnclave])
 	n.provisionDevCount = len(newDeviceTree[deviceTypeProvision])
 	n.dcapInfraResCnt = len(newDeviceTree) - n.enclaveDevCount - n.provisionDevCount
-	n.scanDone <- true
+	n.cancel()
 }
 
 func TestPodCount(t *testing.T) {
@@ -177,11 +178,13 @@ func TestScan(t *testing.T) {
 
 			plugin := newDevicePlugin(devfs, tc.requestedEnclaveDevs, tc.requestedProvisionDevs, tc.requestDcapInfra)
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			notifier := &mockNotifier{
-				scanDone: plugin.scanDone,
+				cancel: cancel,
 			}
 
-			err = plugin.Scan(notifier)
+			err = plugin.Scan(ctx, notifier)
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
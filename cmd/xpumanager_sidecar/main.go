@@ -23,11 +23,13 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
 	"path"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -46,12 +48,14 @@ type invalidEntryErr struct{}
 const (
 	labelMaxLength        = 63
 	xeLinkLabelName       = "xe-links"
+	xeLinkCardsLabelName  = "xe-link-cards"
 	pureXeLinkMetricValue = 1
 	labelControlChar      = "Z"
 )
 
 type xpuManagerTopologyMatrixCell struct {
 	LocalDeviceID     int
+	LocalCard         int
 	LocalSubdeviceID  int
 	RemoteDeviceID    int
 	RemoteSubdeviceID int
@@ -149,6 +153,14 @@ func processMetricsLabels(labels []*io_prometheus_client.LabelPair, allowNonSubd
 
 		klog.V(5).Info(name, " ", strVal)
 
+		if name == "dev_file" {
+			if _, err := fmt.Sscanf(strVal, "card%d", &cell.LocalCard); err != nil {
+				cell.LocalCard = -1
+			}
+
+			continue
+		}
+
 		// xelinks should always be on subdevices
 		if !allowNonSubdeviceLinks && name == "local_on_subdevice" && strVal != "true" {
 			return cell, &invalidEntryErr{}
@@ -315,6 +327,7 @@ func createInvalidTopologyCell() xpuManagerTopologyMatrixCell {
 
 	cell.LaneCount = -1
 	cell.LocalDeviceID = -1
+	cell.LocalCard = -1
 	cell.LocalSubdeviceID = -1
 	cell.RemoteDeviceID = -1
 	cell.RemoteSubdeviceID = -1
@@ -333,6 +346,7 @@ func (xms *xpuManagerSidecar) createLabels(topologyInfos []xpuManagerTopologyMat
 	separator := ""
 
 	submitted := map[string]int{}
+	cards := map[int]int{}
 
 	cellToString := func(cell xpuManagerTopologyMatrixCell) string {
 		if cell.LocalDeviceID < cell.RemoteDeviceID {
@@ -345,6 +359,10 @@ func (xms *xpuManagerSidecar) createLabels(topologyInfos []xpuManagerTopologyMat
 	}
 
 	for _, ti := range topologyInfos {
+		if ti.LocalCard >= 0 {
+			cards[ti.LocalDeviceID] = ti.LocalCard
+		}
+
 		if ti.LaneCount < int(xms.laneCount) {
 			continue
 		}
@@ -366,17 +384,38 @@ func (xms *xpuManagerSidecar) createLabels(topologyInfos []xpuManagerTopologyMat
 		submitted[linkString] = count
 	}
 
-	splitLinks := pluginutils.SplitAtLastAlphaNum(links.String(), labelMaxLength, labelControlChar)
+	labels := xms.splitLabel(xeLinkLabelName, links.String())
+
+	// The cards of the devices tell the users of the links which cards
+	// the device numbers in them refer to.
+	devices := slices.Sorted(maps.Keys(cards))
+	deviceCards := make([]string, 0, len(devices))
+
+	for _, device := range devices {
+		deviceCards = append(deviceCards, strconv.Itoa(device)+"."+strconv.Itoa(cards[device]))
+	}
+
+	if len(deviceCards) > 0 {
+		labels = append(labels, xms.splitLabel(xeLinkCardsLabelName, strings.Join(deviceCards, "_"))...)
+	}
+
+	return labels
+}
+
+// splitLabel splits the label value to labels fitting the label length.
+// The first label is named by name and the next ones by name2, name3...
+func (xms *xpuManagerSidecar) splitLabel(name, value string) []string {
+	splitValue := pluginutils.SplitAtLastAlphaNum(value, labelMaxLength, labelControlChar)
 
 	labels := []string{}
 
-	if len(splitLinks) == 0 {
+	if len(splitValue) == 0 {
 		return labels
 	}
 
-	labels = append(labels, xms.labelNamespace+"/"+xeLinkLabelName+"="+splitLinks[0])
-	for i := 1; i < len(splitLinks); i++ {
-		labels = append(labels, xms.labelNamespace+"/"+xeLinkLabelName+strconv.FormatInt(int64(i+1), 10)+"="+splitLinks[i])
+	labels = append(labels, xms.labelNamespace+"/"+name+"="+splitValue[0])
+	for i := 1; i < len(splitValue); i++ {
+		labels = append(labels, xms.labelNamespace+"/"+name+strconv.FormatInt(int64(i+1), 10)+"="+splitValue[i])
 	}
 
 	return labels
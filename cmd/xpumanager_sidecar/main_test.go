@@ -76,7 +76,10 @@ func createTestCases() []testCase {
 				`xpum_topology_link{dev_file="card1",dev_name="Intel(R) Graphics [0x0bdb]",pci_bdf="0000:51:00.0",pci_dev="0xbdb",src="direct",uuid="01000000-0000-0000-0000-000000510000",vendor="Intel(R) Corporation",local_cpu_affinity="0-23,48-71",local_device_id="0",local_numa_index="0",local_on_subdevice="false",local_subdevice_id="0",remote_device_id="1",remote_subdevice_id="1",lane_count="4"} 1`,
 				"",
 			},
-			expectedLabels:     []string{"xpumanager.intel.com/xe-links=0.0-1.0_0.0-1.1"},
+			expectedLabels: []string{
+				"xpumanager.intel.com/xe-links=0.0-1.0_0.0-1.1",
+				"xpumanager.intel.com/xe-link-cards=0.1",
+			},
 			allowSubdeviceless: true,
 		},
 		{
@@ -99,7 +102,10 @@ func createTestCases() []testCase {
 				`xpum_topology_link{dev_file="card1",dev_name="Intel(R) Graphics [0x0bdb]",pci_bdf="0000:51:00.0",pci_dev="0xbdb",src="direct",uuid="01000000-0000-0000-0000-000000510000",vendor="Intel(R) Corporation",local_cpu_affinity="0-23,48-71",local_device_id="0",local_numa_index="0",local_on_subdevice="true",local_subdevice_id="0",remote_device_id="1",remote_subdevice_id="0", lan_count="4"} 1.0`,
 				"",
 			},
-			expectedLabels: []string{"xpumanager.intel.com/xe-links=0.0-1.0"},
+			expectedLabels: []string{
+				"xpumanager.intel.com/xe-links=0.0-1.0",
+				"xpumanager.intel.com/xe-link-cards=0.1",
+			},
 		},
 		{
 			name:         "One xelink with non xelink",
@@ -111,7 +117,10 @@ func createTestCases() []testCase {
 				`xpum_topology_link{dev_file="card1",dev_name="Intel(R) Graphics [0x0bdb]",pci_bdf="0000:51:00.0",pci_dev="0xbdb",src="direct",uuid="01000000-0000-0000-0000-000000510000",vendor="Intel(R) Corporation",local_cpu_affinity="0-23,48-71",local_device_id="0",local_numa_index="0",local_on_subdevice="true",local_subdevice_id="0",remote_device_id="1",remote_subdevice_id="0", lan_count="4"} 1.0`,
 				"",
 			},
-			expectedLabels: []string{"xpumanager.intel.com/xe-links=0.0-1.0"},
+			expectedLabels: []string{
+				"xpumanager.intel.com/xe-links=0.0-1.0",
+				"xpumanager.intel.com/xe-link-cards=0.1",
+			},
 		},
 		{
 			name:         "Cross linked subdevs",
@@ -120,10 +129,13 @@ func createTestCases() []testCase {
 				`# HELP xpum_topology_link Connection type fo two GPU tiles`,
 				`# TYPE xpum_topology_link gauge`,
 				`xpum_topology_link{dev_file="card1",dev_name="Intel(R) Graphics [0x0bdb]",pci_bdf="0000:51:00.0",pci_dev="0xbdb",src="direct",uuid="01000000-0000-0000-0000-000000510000",vendor="Intel(R) Corporation",local_cpu_affinity="0-23,48-71",local_device_id="0",local_numa_index="0",local_on_subdevice="true",local_subdevice_id="0",remote_device_id="1",remote_subdevice_id="1", lan_count="4"} 1`,
-				`xpum_topology_link{dev_file="card1",dev_name="Intel(R) Graphics [0x0bdb]",pci_bdf="0000:51:00.0",pci_dev="0xbdb",src="direct",uuid="01000000-0000-0000-0000-000000510000",vendor="Intel(R) Corporation",local_cpu_affinity="0-23,48-71",local_device_id="1",local_numa_index="0",local_on_subdevice="true",local_subdevice_id="0",remote_device_id="0",remote_subdevice_id="1", lan_count="4"} 1`,
+				`xpum_topology_link{dev_file="card2",dev_name="Intel(R) Graphics [0x0bdb]",pci_bdf="0000:52:00.0",pci_dev="0xbdb",src="direct",uuid="01000000-0000-0000-0000-000000520000",vendor="Intel(R) Corporation",local_cpu_affinity="0-23,48-71",local_device_id="1",local_numa_index="0",local_on_subdevice="true",local_subdevice_id="0",remote_device_id="0",remote_subdevice_id="1", lan_count="4"} 1`,
 				"",
 			},
-			expectedLabels: []string{"xpumanager.intel.com/xe-links=0.0-1.1_0.1-1.0"},
+			expectedLabels: []string{
+				"xpumanager.intel.com/xe-links=0.0-1.1_0.1-1.0",
+				"xpumanager.intel.com/xe-link-cards=0.1_1.2",
+			},
 		},
 		{
 			name:         "One to many",
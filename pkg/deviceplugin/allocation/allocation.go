@@ -0,0 +1,289 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package allocation provides preferred allocation policies shared by the
+// device plugins. The policies are registered by name and choose devices by
+// their attributes and topology instead of parsing device IDs.
+//
+// A plugin opts in by embedding an Allocator, which implements the
+// DevicePreferredAllocator and Reconfigurer interfaces of the framework, and
+// by setting its policy from the -allocation-policy option:
+//
+//	type devicePlugin struct {
+//		allocation.Allocator
+//		...
+//	}
+//
+//	policy := flag.String(allocation.FlagName, allocation.None, allocation.Usage())
+//	dpapi.ParseFlags()
+//
+//	if err := plugin.SetPolicy(*policy); err != nil {
+//		...
+//	}
+package allocation
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+)
+
+// FlagName is the name of the command line option and the config file
+// option choosing the policy.
+const FlagName = "allocation-policy"
+
+// Device is a device available for allocation.
+type Device struct {
+	Attributes dpapi.DeviceAttributes
+	ID         string
+	// Parent is the device shared by the device IDs of a shared device: the
+	// PCI address attribute, the first device node or the ID of the device.
+	Parent string
+	// PhysicalFunction is the physical function attribute of a VF and the
+	// parent of other devices.
+	PhysicalFunction string
+	NUMANodes        []int64
+}
+
+// NewDevice returns the device with the given ID and device info.
+func NewDevice(id string, info *dpapi.DeviceInfo) Device {
+	dev := Device{
+		ID:         id,
+		Parent:     id,
+		Attributes: info.Attributes(),
+	}
+
+	if addr, found := dev.Attributes.StringValue(dpapi.AttributePCIAddress); found {
+		dev.Parent = addr
+	} else if nodes := info.DeviceNodes(); len(nodes) > 0 {
+		dev.Parent = nodes[0].HostPath
+	}
+
+	dev.PhysicalFunction = dev.Parent
+	if pf, found := dev.Attributes.StringValue(dpapi.AttributePhysicalFunction); found {
+		dev.PhysicalFunction = pf
+	}
+
+	if topology := info.Topology(); topology != nil {
+		for _, node := range topology.Nodes {
+			dev.NUMANodes = append(dev.NUMANodes, node.ID)
+		}
+	}
+
+	return dev
+}
+
+// numaKey returns the NUMA nodes of the device as text, e.g. "0,1".
+func (dev *Device) numaKey() string {
+	nodes := make([]string, 0, len(dev.NUMANodes))
+
+	for _, node := range dev.NUMANodes {
+		nodes = append(nodes, strconv.FormatInt(node, 10))
+	}
+
+	return strings.Join(nodes, ",")
+}
+
+// Request is a container request to allocate devices.
+type Request struct {
+	// Available devices in the order given by kubelet.
+	Available []Device
+	// MustInclude devices, which are also available.
+	MustInclude []Device
+	Size        int
+}
+
+// Policy returns the IDs of Size devices preferred for a request. The IDs
+// must include the ones of the MustInclude devices.
+type Policy func(req *Request) []string
+
+var (
+	policies     = map[string]Policy{}
+	policiesLock sync.RWMutex
+)
+
+// Register registers a policy by name. It panics if the name is taken.
+func Register(name string, policy Policy) {
+	policiesLock.Lock()
+	defer policiesLock.Unlock()
+
+	if _, found := policies[name]; found {
+		panic("allocation policy " + name + " registered twice")
+	}
+
+	policies[name] = policy
+}
+
+// Get returns the policy registered by name.
+func Get(name string) (Policy, error) {
+	policiesLock.RLock()
+	defer policiesLock.RUnlock()
+
+	policy, found := policies[name]
+	if !found {
+		return nil, errors.Errorf("unknown allocation policy %q, the valid values: %s", name, strings.Join(names(), ", "))
+	}
+
+	return policy, nil
+}
+
+// Names returns the names of the registered policies in order.
+func Names() []string {
+	policiesLock.RLock()
+	defer policiesLock.RUnlock()
+
+	return names()
+}
+
+func names() []string {
+	names := make([]string, 0, len(policies))
+
+	for name := range policies {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Usage returns the usage text of the policy option.
+func Usage() string {
+	return "preferred allocation policy: " + strings.Join(Names(), ", ")
+}
+
+// Allocator implements the DevicePreferredAllocator interface with a
+// registered policy and the Reconfigurer interface for changing the policy.
+// The zero value uses the None policy.
+type Allocator struct {
+	policy Policy
+	name   string
+	mutex  sync.RWMutex
+}
+
+// Policy returns the name of the policy.
+func (a *Allocator) Policy() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if a.name == "" {
+		return None
+	}
+
+	return a.name
+}
+
+// SetPolicy changes the policy. The allocations of existing containers
+// remain as they are.
+func (a *Allocator) SetPolicy(name string) error {
+	policy, err := Get(name)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	a.policy = policy
+	a.name = name
+	a.mutex.Unlock()
+
+	klog.V(1).Infof("Using %s preferred allocation policy", name)
+
+	return nil
+}
+
+// Reconfigure implements the Reconfigurer interface. Only the policy can
+// be changed, other options need a restart.
+func (a *Allocator) Reconfigure(options map[string]string) error {
+	for name := range options {
+		if name != FlagName {
+			return errors.Errorf("changing option %q requires a restart", name)
+		}
+	}
+
+	if name, found := options[FlagName]; found {
+		return a.SetPolicy(name)
+	}
+
+	return nil
+}
+
+// GetPreferredDeviceAllocation implements the DevicePreferredAllocator interface.
+func (a *Allocator) GetPreferredDeviceAllocation(rqt *pluginapi.PreferredAllocationRequest, devices map[string]dpapi.DeviceInfo) (*pluginapi.PreferredAllocationResponse, error) {
+	a.mutex.RLock()
+	policy := a.policy
+	a.mutex.RUnlock()
+
+	if policy == nil {
+		policy = nonePolicy
+	}
+
+	response := &pluginapi.PreferredAllocationResponse{}
+
+	for _, creq := range rqt.ContainerRequests {
+		req, err := NewRequest(creq, devices)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := policy(req)
+
+		klog.V(3).Infof("AvailableDeviceIDs: %q, MustIncludeDeviceIDs: %q, preferred: %q",
+			creq.AvailableDeviceIDs, creq.MustIncludeDeviceIDs, ids)
+
+		response.ContainerResponses = append(response.ContainerResponses,
+			&pluginapi.ContainerPreferredAllocationResponse{DeviceIDs: ids})
+	}
+
+	return response, nil
+}
+
+// NewRequest returns the Request of a container preferred allocation request.
+// The info of the requested devices is taken from devices. It fails if the
+// allocation size doesn't fit the available and the must include devices.
+func NewRequest(creq *pluginapi.ContainerPreferredAllocationRequest, devices map[string]dpapi.DeviceInfo) (*Request, error) {
+	if int(creq.AllocationSize) > len(creq.AvailableDeviceIDs) {
+		return nil, errors.Errorf("AllocationSize (%d) is greater than the number of available device IDs (%d)",
+			creq.AllocationSize, len(creq.AvailableDeviceIDs))
+	}
+
+	if len(creq.MustIncludeDeviceIDs) > int(creq.AllocationSize) {
+		return nil, errors.Errorf("AllocationSize (%d) is less than the number of must include device IDs (%d)",
+			creq.AllocationSize, len(creq.MustIncludeDeviceIDs))
+	}
+
+	req := &Request{
+		Size:        int(creq.AllocationSize),
+		Available:   make([]Device, 0, len(creq.AvailableDeviceIDs)),
+		MustInclude: make([]Device, 0, len(creq.MustIncludeDeviceIDs)),
+	}
+
+	for _, id := range creq.AvailableDeviceIDs {
+		info := devices[id]
+		req.Available = append(req.Available, NewDevice(id, &info))
+	}
+
+	for _, id := range creq.MustIncludeDeviceIDs {
+		info := devices[id]
+		req.MustInclude = append(req.MustInclude, NewDevice(id, &info))
+	}
+
+	return req, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocation
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+)
+
+func TestRegistry(t *testing.T) {
+	if names := Names(); !reflect.DeepEqual(names, []string{Balanced, None, NUMAAligned, Packed, PFSpread}) {
+		t.Errorf("unexpected policies %v", names)
+	}
+
+	if _, err := Get("fastest"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a policy registered twice")
+		}
+	}()
+
+	Register(None, nonePolicy)
+}
+
+func TestNewDevice(t *testing.T) {
+	nodes := []pluginapi.DeviceSpec{{HostPath: "/dev/dri/card0"}}
+	numa := &pluginapi.TopologyInfo{Nodes: []*pluginapi.NUMANode{{ID: 1}}}
+
+	vf := dpapi.NewDeviceInfoWithTopologyHints(pluginapi.Healthy, nodes, nil, nil, nil, numa, nil)
+	vf.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute("0000:01:00.1"))
+	vf.SetAttribute(dpapi.AttributePhysicalFunction, dpapi.StringAttribute("0000:01:00.0"))
+
+	tcases := []struct {
+		info     dpapi.DeviceInfo
+		name     string
+		expected Device
+	}{
+		{
+			name:     "attributes",
+			info:     vf,
+			expected: Device{ID: "vf", Parent: "0000:01:00.1", PhysicalFunction: "0000:01:00.0", NUMANodes: []int64{1}, Attributes: vf.Attributes()},
+		},
+		{
+			name:     "device node",
+			info:     dpapi.NewDeviceInfoWithTopologyHints(pluginapi.Healthy, nodes, nil, nil, nil, nil, nil),
+			expected: Device{ID: "card0-1", Parent: "/dev/dri/card0", PhysicalFunction: "/dev/dri/card0"},
+		},
+		{
+			name:     "ID",
+			info:     dpapi.NewDeviceInfoWithTopologyHints(pluginapi.Healthy, nil, nil, nil, nil, nil, nil),
+			expected: Device{ID: "wq0", Parent: "wq0", PhysicalFunction: "wq0"},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if dev := NewDevice(tc.expected.ID, &tc.info); !reflect.DeepEqual(dev, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, dev)
+			}
+		})
+	}
+}
+
+func TestAllocator(t *testing.T) {
+	a := &Allocator{}
+	if a.Policy() != None {
+		t.Errorf("unexpected default policy %s", a.Policy())
+	}
+
+	if err := a.SetPolicy("fastest"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+
+	err := a.SetPolicy(Packed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	devices := map[string]dpapi.DeviceInfo{}
+
+	for _, id := range []string{"card0-0", "card0-1", "card1-0", "card1-1"} {
+		info := dpapi.NewDeviceInfoWithTopologyHints(pluginapi.Healthy, nil, nil, nil, nil, nil, nil)
+		info.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute(id[:5]))
+		devices[id] = info
+	}
+
+	rqt := &pluginapi.PreferredAllocationRequest{
+		ContainerRequests: []*pluginapi.ContainerPreferredAllocationRequest{{
+			AvailableDeviceIDs: []string{"card1-1", "card0-0", "card1-0", "card0-1"},
+			AllocationSize:     2,
+		}},
+	}
+
+	preferred := func() []string {
+		t.Helper()
+
+		resp, allocErr := a.GetPreferredDeviceAllocation(rqt, devices)
+		if allocErr != nil {
+			t.Fatal(allocErr)
+		}
+
+		ids := resp.ContainerResponses[0].DeviceIDs
+		slices.Sort(ids)
+
+		return ids
+	}
+
+	if ids := preferred(); !reflect.DeepEqual(ids, []string{"card0-0", "card0-1"}) {
+		t.Errorf("unexpected packed devices %v", ids)
+	}
+
+	if err = a.Reconfigure(map[string]string{FlagName: Balanced}); err != nil || a.Policy() != Balanced {
+		t.Fatalf("policy not reconfigured: %v", err)
+	}
+
+	if ids := preferred(); !reflect.DeepEqual(ids, []string{"card0-0", "card1-0"}) {
+		t.Errorf("unexpected balanced devices %v", ids)
+	}
+
+	for _, options := range []map[string]string{
+		{FlagName: "fastest"},
+		{FlagName: None, "shared-dev-num": "2"},
+	} {
+		if err = a.Reconfigure(options); err == nil || a.Policy() != Balanced {
+			t.Errorf("expected an error and no change for %v", options)
+		}
+	}
+
+	for _, creq := range []*pluginapi.ContainerPreferredAllocationRequest{
+		{AvailableDeviceIDs: []string{"card0-0"}, AllocationSize: 2},
+		{AvailableDeviceIDs: []string{"card0-0", "card0-1"}, MustIncludeDeviceIDs: []string{"card0-0", "card0-1"}, AllocationSize: 1},
+	} {
+		rqt.ContainerRequests[0] = creq
+		if _, err = a.GetPreferredDeviceAllocation(rqt, devices); err == nil {
+			t.Errorf("expected an error for %+v", creq)
+		}
+	}
+}
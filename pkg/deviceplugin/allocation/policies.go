@@ -0,0 +1,298 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocation
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Names of the built-in policies.
+const (
+	// None prefers the devices in the order given by kubelet.
+	None = "none"
+	// Packed fills the parent devices one by one, starting from the ones
+	// with the fewest available device IDs, so that whole devices remain
+	// available for other containers.
+	Packed = "packed"
+	// Balanced spreads the devices over the parent devices, taking each
+	// device from the parent with the most available device IDs.
+	Balanced = "balanced"
+	// NUMAAligned takes the devices from a single NUMA node when one has
+	// enough of them, preferring the node with the fewest, and packs them
+	// in the node.
+	NUMAAligned = "numa-aligned"
+	// PFSpread is like Balanced, but spreads the devices over the physical
+	// functions of the VFs.
+	PFSpread = "pf-spread"
+)
+
+func init() {
+	Register(None, nonePolicy)
+	Register(Packed, packedPolicy)
+	Register(Balanced, balancedPolicy)
+	Register(NUMAAligned, NUMAAlignedPolicy(packedPolicy))
+	Register(PFSpread, pfSpreadPolicy)
+}
+
+// selection collects the IDs preferred for a request.
+type selection struct {
+	selected map[string]struct{}
+	ids      []string
+	size     int
+}
+
+// newSelection returns a selection of the must include devices.
+func newSelection(req *Request) *selection {
+	s := &selection{
+		selected: make(map[string]struct{}, req.Size),
+		ids:      make([]string, 0, req.Size),
+		size:     req.Size,
+	}
+
+	for i := range req.MustInclude {
+		s.add(req.MustInclude[i].ID)
+	}
+
+	return s
+}
+
+func (s *selection) add(id string) {
+	if s.has(id) {
+		return
+	}
+
+	s.selected[id] = struct{}{}
+	s.ids = append(s.ids, id)
+}
+
+func (s *selection) has(id string) bool {
+	_, found := s.selected[id]
+
+	return found
+}
+
+func (s *selection) done() bool {
+	return len(s.ids) >= s.size
+}
+
+// group is a set of devices with the same key, e.g. the same parent.
+type group struct {
+	key string
+	// Devices not selected, in ID order.
+	devices []Device
+	// Number of selected devices.
+	selected int
+}
+
+// groupBy returns the groups of devices in key order.
+func groupBy(devices []Device, s *selection, key func(*Device) string) []*group {
+	groups := make(map[string]*group)
+
+	for i := range devices {
+		k := key(&devices[i])
+
+		g, found := groups[k]
+		if !found {
+			g = &group{key: k}
+			groups[k] = g
+		}
+
+		if s.has(devices[i].ID) {
+			g.selected++
+		} else {
+			g.devices = append(g.devices, devices[i])
+		}
+	}
+
+	sorted := make([]*group, 0, len(groups))
+
+	for _, k := range slices.Sorted(maps.Keys(groups)) {
+		slices.SortFunc(groups[k].devices, func(a, b Device) int {
+			return strings.Compare(a.ID, b.ID)
+		})
+
+		sorted = append(sorted, groups[k])
+	}
+
+	return sorted
+}
+
+func parentKey(dev *Device) string {
+	return dev.Parent
+}
+
+func physicalFunctionKey(dev *Device) string {
+	return dev.PhysicalFunction
+}
+
+// pack selects the devices of the groups having selected devices first and
+// then of the groups with the fewest devices.
+func pack(s *selection, groups []*group) {
+	slices.SortStableFunc(groups, func(a, b *group) int {
+		if a.selected != b.selected {
+			return b.selected - a.selected
+		}
+
+		return len(a.devices) - len(b.devices)
+	})
+
+	for _, g := range groups {
+		for i := range g.devices {
+			if s.done() {
+				return
+			}
+
+			s.add(g.devices[i].ID)
+		}
+	}
+}
+
+// spread selects the devices one by one from the group with the most
+// devices, on ties from the one with the fewest selected devices and then
+// the first in key order.
+func spread(s *selection, groups []*group) {
+	for !s.done() {
+		var next *group
+
+		for _, g := range groups {
+			if len(g.devices) == 0 {
+				continue
+			}
+
+			if next == nil || len(g.devices) > len(next.devices) ||
+				(len(g.devices) == len(next.devices) && g.selected < next.selected) {
+				next = g
+			}
+		}
+
+		if next == nil {
+			return
+		}
+
+		s.add(next.devices[0].ID)
+		next.devices = next.devices[1:]
+		next.selected++
+	}
+}
+
+func nonePolicy(req *Request) []string {
+	s := newSelection(req)
+
+	for i := range req.Available {
+		if s.done() {
+			break
+		}
+
+		s.add(req.Available[i].ID)
+	}
+
+	return s.ids
+}
+
+func packedPolicy(req *Request) []string {
+	s := newSelection(req)
+	pack(s, groupBy(req.Available, s, parentKey))
+
+	return s.ids
+}
+
+func balancedPolicy(req *Request) []string {
+	s := newSelection(req)
+	spread(s, groupBy(req.Available, s, parentKey))
+
+	return s.ids
+}
+
+func pfSpreadPolicy(req *Request) []string {
+	s := newSelection(req)
+	spread(s, groupBy(req.Available, s, physicalFunctionKey))
+
+	return s.ids
+}
+
+// NUMAAlignedPolicy returns a policy which takes the devices from the NUMA
+// nodes of the must include devices first, then from the smallest node having
+// enough devices and then from the nodes with the most devices. Devices
+// without NUMA nodes are in a node of their own. The devices within a node
+// are chosen by policy.
+func NUMAAlignedPolicy(policy Policy) Policy {
+	return func(req *Request) []string {
+		s := newSelection(req)
+		need := req.Size - len(s.ids)
+
+		nodes := groupBy(req.Available, s, func(dev *Device) string {
+			return dev.numaKey()
+		})
+
+		slices.SortStableFunc(nodes, func(a, b *group) int {
+			if a.selected != b.selected {
+				return b.selected - a.selected
+			}
+
+			aFits, bFits := len(a.devices) >= need, len(b.devices) >= need
+
+			switch {
+			case aFits && bFits:
+				return len(a.devices) - len(b.devices)
+			case aFits:
+				return -1
+			case bFits:
+				return 1
+			}
+
+			return len(b.devices) - len(a.devices)
+		})
+
+		for _, node := range nodes {
+			if s.done() {
+				break
+			}
+
+			if len(node.devices) == 0 {
+				continue
+			}
+
+			nodeReq := &Request{
+				Size: node.selected + min(s.size-len(s.ids), len(node.devices)),
+			}
+
+			for _, dev := range req.Available {
+				if dev.numaKey() == node.key {
+					nodeReq.Available = append(nodeReq.Available, dev)
+				}
+			}
+
+			for _, dev := range req.MustInclude {
+				if dev.numaKey() == node.key {
+					nodeReq.MustInclude = append(nodeReq.MustInclude, dev)
+				}
+			}
+
+			// The size leaves room for the must include devices even if
+			// the policy does not return them.
+			for _, id := range policy(nodeReq) {
+				if s.done() {
+					break
+				}
+
+				s.add(id)
+			}
+		}
+
+		return s.ids
+	}
+}
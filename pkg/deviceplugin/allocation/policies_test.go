@@ -0,0 +1,178 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocation
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testDevices returns two cards on NUMA node 0 and one on node 1 with the
+// given numbers of shared device IDs, e.g. "card0-0". Cards 0 and 1 are
+// VFs of the same PF.
+func testDevices(card0, card1, card2 int) []Device {
+	devices := []Device{}
+
+	for card, count := range []int{card0, card1, card2} {
+		for i := range count {
+			dev := Device{
+				ID:        "card" + string(rune('0'+card)) + "-" + string(rune('0'+i)),
+				Parent:    "0000:0" + string(rune('0'+card)) + ":00.0",
+				NUMANodes: []int64{int64(card / 2)},
+			}
+
+			dev.PhysicalFunction = dev.Parent
+			if card != 2 {
+				dev.PhysicalFunction = "0000:00:00.0"
+			}
+
+			devices = append(devices, dev)
+		}
+	}
+
+	return devices
+}
+
+func deviceByID(devices []Device, ids ...string) []Device {
+	found := []Device{}
+
+	for _, id := range ids {
+		for i := range devices {
+			if devices[i].ID == id {
+				found = append(found, devices[i])
+			}
+		}
+	}
+
+	return found
+}
+
+func TestPolicies(t *testing.T) {
+	tcases := []struct {
+		name        string
+		policy      string
+		available   []Device
+		mustInclude []string
+		expected    []string
+		size        int
+	}{
+		{
+			name:      "none",
+			policy:    None,
+			available: testDevices(2, 2, 2),
+			size:      3,
+			expected:  []string{"card0-0", "card0-1", "card1-0"},
+		},
+		{
+			name:        "none with must include",
+			policy:      None,
+			available:   testDevices(2, 2, 2),
+			mustInclude: []string{"card2-1"},
+			size:        2,
+			expected:    []string{"card2-1", "card0-0"},
+		},
+		{
+			name:      "packed fills the fullest parent first",
+			policy:    Packed,
+			available: testDevices(3, 1, 2),
+			size:      3,
+			expected:  []string{"card1-0", "card2-0", "card2-1"},
+		},
+		{
+			name:        "packed with must include",
+			policy:      Packed,
+			available:   testDevices(3, 1, 2),
+			mustInclude: []string{"card0-2"},
+			size:        3,
+			expected:    []string{"card0-2", "card0-0", "card0-1"},
+		},
+		{
+			name:      "balanced",
+			policy:    Balanced,
+			available: testDevices(3, 1, 2),
+			size:      4,
+			expected:  []string{"card0-0", "card2-0", "card0-1", "card1-0"},
+		},
+		{
+			name:      "balanced over equal parents",
+			policy:    Balanced,
+			available: testDevices(2, 2, 2),
+			size:      3,
+			expected:  []string{"card0-0", "card1-0", "card2-0"},
+		},
+		{
+			name:      "balanced over VFs",
+			policy:    Balanced,
+			available: testDevices(1, 1, 1),
+			size:      2,
+			expected:  []string{"card0-0", "card1-0"},
+		},
+		{
+			name:      "PF spread",
+			policy:    PFSpread,
+			available: testDevices(1, 1, 1),
+			size:      2,
+			expected:  []string{"card0-0", "card2-0"},
+		},
+		{
+			name:      "NUMA aligned to the smallest node that fits",
+			policy:    NUMAAligned,
+			available: testDevices(2, 2, 2),
+			size:      2,
+			expected:  []string{"card2-0", "card2-1"},
+		},
+		{
+			name:      "NUMA aligned to the node that fits",
+			policy:    NUMAAligned,
+			available: testDevices(2, 2, 2),
+			size:      3,
+			expected:  []string{"card0-0", "card0-1", "card1-0"},
+		},
+		{
+			name:        "NUMA aligned to the node of must include",
+			policy:      NUMAAligned,
+			available:   testDevices(2, 2, 2),
+			mustInclude: []string{"card1-1"},
+			size:        3,
+			expected:    []string{"card1-1", "card1-0", "card0-0"},
+		},
+		{
+			name:      "NUMA aligned spills over",
+			policy:    NUMAAligned,
+			available: testDevices(2, 2, 2),
+			size:      5,
+			expected:  []string{"card0-0", "card0-1", "card1-0", "card1-1", "card2-0"},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := Get(tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ids := policy(&Request{
+				Available:   tc.available,
+				MustInclude: deviceByID(tc.available, tc.mustInclude...),
+				Size:        tc.size,
+			})
+
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, ids)
+			}
+		})
+	}
+}
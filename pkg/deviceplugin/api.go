@@ -16,6 +16,9 @@
 package deviceplugin
 
 import (
+	"context"
+	"time"
+
 	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/topology"
 	"k8s.io/klog/v2"
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
@@ -27,11 +30,17 @@ type DeviceInfo struct {
 	mounts      []pluginapi.Mount
 	envs        map[string]string
 	annotations map[string]string
+	attributes  DeviceAttributes
 	topology    *pluginapi.TopologyInfo
 	// https://github.com/kubernetes/enhancements/tree/master/keps/sig-node/4009-add-cdi-devices-to-device-plugin-api
 	cdiSpec *cdispec.Spec
-	state   string
-	nodes   []pluginapi.DeviceSpec
+	// CDI spec shared by many devices, see SetCdiCommonSpec.
+	cdiCommonSpec *cdispec.Spec
+	// Time of the latest health transition, set by Manager.
+	healthSince  time.Time
+	state        string
+	healthReason string
+	nodes        []pluginapi.DeviceSpec
 }
 
 // UseDefaultMethodError allows the plugin to request running the default
@@ -88,6 +97,63 @@ func NewDeviceInfoWithTopologyHints(state string, nodes []pluginapi.DeviceSpec,
 	}
 }
 
+// SetHealthReason tells why the device is unhealthy, e.g. "TemperatureCritical".
+// The reason is logged and exposed in the metrics when the device becomes
+// unhealthy, so it should be short and not change from one scan to another.
+func (info *DeviceInfo) SetHealthReason(reason string) {
+	info.healthReason = reason
+}
+
+// SetAttribute sets an attribute of the device, e.g. its PCI device ID.
+// The attributes are passed to the allocation policies and the resource
+// rules, published with DRA and served by the debug endpoint, so that they
+// don't need to be read again from sysfs. See the Attribute* constants for
+// the well-known attributes.
+func (info *DeviceInfo) SetAttribute(name string, value DeviceAttribute) {
+	if info.attributes == nil {
+		info.attributes = make(DeviceAttributes)
+	}
+
+	info.attributes[name] = value
+}
+
+// Attributes returns the attributes of the device.
+func (info *DeviceInfo) Attributes() DeviceAttributes {
+	return info.attributes
+}
+
+// DeviceNodes returns the device nodes of the device.
+func (info *DeviceInfo) DeviceNodes() []pluginapi.DeviceSpec {
+	return info.nodes
+}
+
+// Topology returns the topology hints of the device.
+func (info *DeviceInfo) Topology() *pluginapi.TopologyInfo {
+	return info.topology
+}
+
+// SetCdiCommonSpec adds a CDI spec shared by many devices to the device.
+// The spec typically has one device with the edits common to all the devices
+// of a kind, e.g. the /dev/dri/by-path links of GPUs. It's written once and
+// its CDI devices are returned along with the device's own CDI devices in
+// allocation responses.
+func (info *DeviceInfo) SetCdiCommonSpec(spec *cdispec.Spec) {
+	info.cdiCommonSpec = spec
+}
+
+// cdiSpecList returns the CDI specs of the device.
+func (info *DeviceInfo) cdiSpecList() []*cdispec.Spec {
+	specs := make([]*cdispec.Spec, 0, 2)
+
+	for _, spec := range []*cdispec.Spec{info.cdiSpec, info.cdiCommonSpec} {
+		if spec != nil {
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs
+}
+
 // DeviceTree contains a tree-like structure of device type -> device ID -> device info.
 type DeviceTree map[string]map[string]DeviceInfo
 
@@ -102,17 +168,16 @@ func (tree DeviceTree) AddDevice(devType, id string, info DeviceInfo) {
 		tree[devType] = make(map[string]DeviceInfo)
 	}
 
-	if info.cdiSpec != nil {
-		devLength := len(info.cdiSpec.Devices)
-		if devLength == 0 {
-			klog.Warning("No CDI devices defined in spec, removing spec")
+	if info.cdiSpec != nil && len(info.cdiSpec.Devices) == 0 {
+		klog.Warning("No CDI devices defined in spec, removing spec")
 
-			info.cdiSpec = nil
-		} else if devLength > 1 {
-			klog.Warning("Including more than one CDI device per spec is not supported, using first")
+		info.cdiSpec = nil
+	}
 
-			info.cdiSpec.Devices = info.cdiSpec.Devices[:1]
-		}
+	if info.cdiCommonSpec != nil && len(info.cdiCommonSpec.Devices) == 0 {
+		klog.Warning("No CDI devices defined in common spec, removing spec")
+
+		info.cdiCommonSpec = nil
 	}
 
 	tree[devType][id] = info
@@ -134,16 +199,18 @@ type Notifier interface {
 // Scanner serves as an interface between Manager and a device plugin.
 type Scanner interface {
 	// Scan scans the host for devices and sends all found devices to
-	// a Notifier instance. It's called only once for every device plugin by
-	// Manager in a goroutine and operates in an infinite loop.
-	Scan(Notifier) error
+	// a Notifier instance. It's called by Manager in a goroutine and
+	// operates in a loop until ctx is canceled. If Scan fails, Manager
+	// may call it again after a backoff delay.
+	Scan(ctx context.Context, notifier Notifier) error
 }
 
 // Allocator is an optional interface implemented by device plugins.
 type Allocator interface {
 	// Allocate allows the plugin to replace the server Allocate(). Plugin can return
 	// UseDefaultAllocateMethod if the default server allocation is anyhow preferred
-	// for the particular allocation request.
+	// for the particular allocation request. The device IDs of the container
+	// requests may be changed before, to allocate other devices of the same type.
 	Allocate(*pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error)
 }
 
@@ -160,9 +227,36 @@ type PreferredAllocator interface {
 	GetPreferredAllocation(*pluginapi.PreferredAllocationRequest) (*pluginapi.PreferredAllocationResponse, error)
 }
 
+// DevicePreferredAllocator is an optional interface implemented by device
+// plugins. It's used instead of PreferredAllocator when implemented.
+type DevicePreferredAllocator interface {
+	// GetPreferredDeviceAllocation is like GetPreferredAllocation, but
+	// also gets the devices of the resource by their IDs, so that the
+	// preferred devices can be chosen by their attributes.
+	GetPreferredDeviceAllocation(*pluginapi.PreferredAllocationRequest, map[string]DeviceInfo) (*pluginapi.PreferredAllocationResponse, error)
+}
+
 // ContainerPreStarter is an optional interface implemented by device plugins.
 type ContainerPreStarter interface {
 	// PreStartContainer  defines device initialization function before container is started.
 	// It might include operations like card reset.
 	PreStartContainer(*pluginapi.PreStartContainerRequest) error
 }
+
+// DeviceOwnersUser is an optional interface implemented by device plugins.
+type DeviceOwnersUser interface {
+	// SetDeviceOwners hands the plugin the mapping of its allocated devices
+	// to pods and containers. It's called by Manager before Scan and only
+	// if the kubelet PodResources API is enabled with -pod-resources-socket.
+	SetDeviceOwners(*DeviceOwners)
+}
+
+// Reconfigurer is an optional interface implemented by device plugins.
+type Reconfigurer interface {
+	// Reconfigure applies changed option values without restarting the
+	// plugin. It's called by Manager with the options changed in the file
+	// given with -config, removed options having their default values. The
+	// option values are in the command line format. If Reconfigure returns
+	// an error, none of the options should be applied.
+	Reconfigure(options map[string]string) error
+}
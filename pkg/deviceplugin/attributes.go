@@ -0,0 +1,183 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// Well-known device attributes.
+const (
+	// AttributePCIAddress is the PCI address of the device, e.g. "0000:03:00.0".
+	AttributePCIAddress = "pciAddress"
+	// AttributePCIDeviceID is the PCI device ID of the device, e.g. "0x56a0".
+	AttributePCIDeviceID = "pciDeviceID"
+	// AttributeDriver is the kernel driver of the device, e.g. "i915".
+	AttributeDriver = "driver"
+	// AttributePhysicalFunction is the PCI address of the PF of a VF.
+	AttributePhysicalFunction = "physicalFunction"
+	// AttributeMemory is the local memory of the device in bytes.
+	AttributeMemory = "memory"
+	// AttributeTiles is the number of tiles of the device.
+	AttributeTiles = "tiles"
+	// AttributeFirmwareVersion is the firmware version of the device.
+	AttributeFirmwareVersion = "firmwareVersion"
+)
+
+const deviceAttributesPath = "/debug/device-attributes"
+
+// DeviceAttribute is a typed device attribute. Exactly one of the values is set.
+type DeviceAttribute struct {
+	IntValue    *int64  `json:"int,omitempty"`
+	BoolValue   *bool   `json:"bool,omitempty"`
+	StringValue *string `json:"string,omitempty"`
+	// VersionValue is a version, e.g. a semantic version "1.2.3".
+	VersionValue *string `json:"version,omitempty"`
+}
+
+// IntAttribute returns an integer attribute.
+func IntAttribute(value int64) DeviceAttribute {
+	return DeviceAttribute{IntValue: &value}
+}
+
+// BoolAttribute returns a boolean attribute.
+func BoolAttribute(value bool) DeviceAttribute {
+	return DeviceAttribute{BoolValue: &value}
+}
+
+// StringAttribute returns a string attribute.
+func StringAttribute(value string) DeviceAttribute {
+	return DeviceAttribute{StringValue: &value}
+}
+
+// VersionAttribute returns a version attribute.
+func VersionAttribute(value string) DeviceAttribute {
+	return DeviceAttribute{VersionValue: &value}
+}
+
+// String returns the value of the attribute as text.
+func (a DeviceAttribute) String() string {
+	switch {
+	case a.IntValue != nil:
+		return strconv.FormatInt(*a.IntValue, 10)
+	case a.BoolValue != nil:
+		return strconv.FormatBool(*a.BoolValue)
+	case a.StringValue != nil:
+		return *a.StringValue
+	case a.VersionValue != nil:
+		return *a.VersionValue
+	}
+
+	return ""
+}
+
+// DeviceAttributes maps attribute names to device attributes. Attribute
+// names are C identifiers, so that they can be published with DRA.
+type DeviceAttributes map[string]DeviceAttribute
+
+// IntValue returns the value of an integer attribute.
+func (a DeviceAttributes) IntValue(name string) (int64, bool) {
+	if v := a[name].IntValue; v != nil {
+		return *v, true
+	}
+
+	return 0, false
+}
+
+// BoolValue returns the value of a boolean attribute.
+func (a DeviceAttributes) BoolValue(name string) (value, found bool) {
+	if v := a[name].BoolValue; v != nil {
+		return *v, true
+	}
+
+	return false, false
+}
+
+// StringValue returns the value of a string attribute.
+func (a DeviceAttributes) StringValue(name string) (string, bool) {
+	if v := a[name].StringValue; v != nil {
+		return *v, true
+	}
+
+	return "", false
+}
+
+// VersionValue returns the value of a version attribute.
+func (a DeviceAttributes) VersionValue(name string) (string, bool) {
+	if v := a[name].VersionValue; v != nil {
+		return *v, true
+	}
+
+	return "", false
+}
+
+// attributeRecorder is a Notifier passing the device trees to the next
+// Notifier as they are. It keeps the attributes of the devices for the
+// debug endpoint.
+type attributeRecorder struct {
+	next Notifier
+	// Device type -> device ID -> attributes.
+	devices map[string]map[string]DeviceAttributes
+	mutex   sync.Mutex
+}
+
+func newAttributeRecorder() *attributeRecorder {
+	return &attributeRecorder{
+		devices: make(map[string]map[string]DeviceAttributes),
+	}
+}
+
+// Notify implements the Notifier interface.
+func (r *attributeRecorder) Notify(tree DeviceTree) {
+	devices := make(map[string]map[string]DeviceAttributes)
+
+	for devType, infos := range tree {
+		devices[devType] = make(map[string]DeviceAttributes)
+
+		for id, info := range infos {
+			devices[devType][id] = info.attributes
+		}
+	}
+
+	r.mutex.Lock()
+	r.devices = devices
+	r.mutex.Unlock()
+
+	r.next.Notify(tree)
+}
+
+// ServeHTTP serves the attributes of all devices as JSON.
+func (r *attributeRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	data, err := json.MarshalIndent(r.devices, "", "  ")
+	r.mutex.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err = w.Write(data); err != nil {
+		klog.V(4).Infof("Failed to write device attributes: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func attributeDevice() DeviceInfo {
+	info := NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil)
+	info.SetAttribute(AttributePCIDeviceID, StringAttribute("0x56a0"))
+	info.SetAttribute(AttributeMemory, IntAttribute(16<<30))
+	info.SetAttribute(AttributeFirmwareVersion, VersionAttribute("1.2.3"))
+	info.SetAttribute("sriov", BoolAttribute(true))
+
+	return info
+}
+
+func TestDeviceAttributes(t *testing.T) {
+	info := attributeDevice()
+	attrs := info.Attributes()
+
+	if id, found := attrs.StringValue(AttributePCIDeviceID); !found || id != "0x56a0" {
+		t.Errorf("unexpected PCI device ID %q", id)
+	}
+
+	if memory, found := attrs.IntValue(AttributeMemory); !found || memory != 16<<30 {
+		t.Errorf("unexpected memory %d", memory)
+	}
+
+	if version, found := attrs.VersionValue(AttributeFirmwareVersion); !found || version != "1.2.3" {
+		t.Errorf("unexpected version %q", version)
+	}
+
+	if sriov, found := attrs.BoolValue("sriov"); !found || !sriov {
+		t.Error("unexpected sriov attribute")
+	}
+
+	// Getters of the wrong type and of missing attributes find nothing.
+	if _, found := attrs.IntValue(AttributePCIDeviceID); found {
+		t.Error("string attribute found as an integer")
+	}
+
+	if _, found := attrs.StringValue(AttributeDriver); found {
+		t.Error("missing attribute found")
+	}
+
+	if _, found := (&DeviceInfo{}).Attributes().StringValue(AttributeDriver); found {
+		t.Error("attribute found without attributes")
+	}
+
+	for attr, expected := range map[string]string{
+		AttributePCIDeviceID:     "0x56a0",
+		AttributeMemory:          "17179869184",
+		AttributeFirmwareVersion: "1.2.3",
+		"sriov":                  "true",
+	} {
+		if value := attrs[attr].String(); value != expected {
+			t.Errorf("expected %s to be %q, got %q", attr, expected, value)
+		}
+	}
+}
+
+func TestAttributeRecorder(t *testing.T) {
+	recorder := &treeRecorder{}
+	attributes := newAttributeRecorder()
+	attributes.next = recorder
+
+	tree := NewDeviceTree()
+	tree.AddDevice("gpu", "card0", attributeDevice())
+	attributes.Notify(tree)
+
+	if len(recorder.trees) != 1 {
+		t.Fatal("tree not passed on")
+	}
+
+	rec := httptest.NewRecorder()
+	attributes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, deviceAttributesPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+
+	var served map[string]map[string]DeviceAttributes
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+
+	if id, _ := served["gpu"]["card0"].StringValue(AttributePCIDeviceID); id != "0x56a0" {
+		t.Errorf("unexpected attributes served: %s", rec.Body.String())
+	}
+}
+
+func TestDRAAttributes(t *testing.T) {
+	info := attributeDevice()
+	info.SetAttribute("version2", VersionAttribute("DG02_1.3170"))
+	info.SetAttribute("not-a-c-identifier", StringAttribute("x"))
+	info.SetAttribute("type", StringAttribute("overridden"))
+
+	attrs := draAttributes(draDevice{devType: "gpu", id: "card0", info: info})
+
+	if v := attrs[AttributePCIDeviceID].StringValue; v == nil || *v != "0x56a0" {
+		t.Errorf("unexpected PCI device ID %+v", attrs[AttributePCIDeviceID])
+	}
+
+	if v := attrs[AttributeFirmwareVersion].VersionValue; v == nil || *v != "1.2.3" {
+		t.Errorf("unexpected firmware version %+v", attrs[AttributeFirmwareVersion])
+	}
+
+	if v := attrs["version2"]; v.VersionValue != nil || v.StringValue == nil {
+		t.Errorf("invalid semantic version not published as a string: %+v", v)
+	}
+
+	if _, found := attrs["not-a-c-identifier"]; found {
+		t.Error("invalid attribute name published")
+	}
+
+	if v := attrs["type"].StringValue; *v != "gpu" {
+		t.Errorf("device type overridden: %s", *v)
+	}
+}
+
+// devicePreferredAllocatorStub prefers the devices having an attribute.
+type devicePreferredAllocatorStub struct {
+	devicePluginStub
+}
+
+func (*devicePreferredAllocatorStub) GetPreferredDeviceAllocation(rqt *pluginapi.PreferredAllocationRequest, devices map[string]DeviceInfo) (*pluginapi.PreferredAllocationResponse, error) {
+	resp := &pluginapi.PreferredAllocationResponse{}
+
+	for _, req := range rqt.ContainerRequests {
+		ids := []string{}
+
+		for _, id := range req.AvailableDeviceIDs {
+			info := devices[id]
+			if _, found := info.Attributes().StringValue(AttributePCIDeviceID); found {
+				ids = append(ids, id)
+			}
+		}
+
+		resp.ContainerResponses = append(resp.ContainerResponses, &pluginapi.ContainerPreferredAllocationResponse{DeviceIDs: ids})
+	}
+
+	return resp, nil
+}
+
+func TestPreferredAllocationFunc(t *testing.T) {
+	devices := map[string]DeviceInfo{
+		"dev1": {},
+		"dev2": attributeDevice(),
+	}
+	rqt := &pluginapi.PreferredAllocationRequest{
+		ContainerRequests: []*pluginapi.ContainerPreferredAllocationRequest{
+			{AvailableDeviceIDs: []string{"dev1", "dev2"}, AllocationSize: 1},
+		},
+	}
+
+	if f := NewManager("test", &emptyScannerStub{}).preferredAllocationFunc(); f != nil {
+		t.Error("preferred allocation without an allocator")
+	}
+
+	if f := NewManager("test", &devicePluginStub{}).preferredAllocationFunc(); f == nil {
+		t.Error("PreferredAllocator not used")
+	}
+
+	f := NewManager("test", &devicePreferredAllocatorStub{}).preferredAllocationFunc()
+	if f == nil {
+		t.Fatal("DevicePreferredAllocator not used")
+	}
+
+	resp, err := f(rqt, devices)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := resp.ContainerResponses[0].DeviceIDs; len(ids) != 1 || ids[0] != "dev2" {
+		t.Errorf("unexpected preferred devices %v", ids)
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	cdispec "tags.cncf.io/container-device-interface/specs-go"
+)
+
+const (
+	// CDIModeDynamic makes Manager write the CDI specs given in DeviceInfo
+	// when the devices get allocated.
+	CDIModeDynamic = "dynamic"
+	// CDIModeStatic makes Manager write CDI specs for all the devices when
+	// they are found. Allocation responses only refer to the CDI devices.
+	CDIModeStatic = "static"
+
+	// Suffix of the file listing the CDI specs written for a namespace. CDI
+	// runtimes only read .yaml and .json files, so the index doesn't confuse them.
+	cdiIndexSuffix = ".cdi-specs"
+)
+
+var (
+	cdiMode = flag.String("cdi-mode", CDIModeDynamic,
+		"CDI spec mode: dynamic or static. With static, a CDI spec of all the devices is written to "+CDIDir+
+			" when they are found and allocations only refer to the CDI devices")
+
+	cdiInvalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.:]+`)
+)
+
+// cdiSpecs keeps track of the CDI spec files written for the devices of
+// a namespace, e.g. "gpu.intel.com", so that the specs of devices which
+// no longer exist can be removed. The set is persisted in an index file
+// next to the specs to also cover the specs written by earlier runs.
+type cdiSpecs struct {
+	files map[string]struct{}
+	// Last written static spec.
+	static *cdispec.Spec
+	dir    string
+	index  string
+	// CDI kind of the static spec, empty in the dynamic mode.
+	staticKind string
+	mutex      sync.Mutex
+	loaded     bool
+}
+
+func newCdiSpecs(dir, namespace string) *cdiSpecs {
+	return &cdiSpecs{
+		dir:   dir,
+		index: filepath.Join(dir, "."+namespace+cdiIndexSuffix),
+		files: make(map[string]struct{}),
+	}
+}
+
+// load reads the index once. A missing or broken index only means that
+// the specs of earlier runs can't be cleaned up.
+func (s *cdiSpecs) load() {
+	if s.loaded {
+		return
+	}
+
+	s.loaded = true
+
+	data, err := os.ReadFile(s.index)
+	if os.IsNotExist(err) {
+		return
+	}
+
+	var files []string
+
+	if err == nil {
+		err = json.Unmarshal(data, &files)
+	}
+
+	if err != nil {
+		klog.Warningf("Ignoring CDI spec index %s: %v", s.index, err)
+
+		return
+	}
+
+	for _, name := range files {
+		// Only plain file names are written to the index.
+		if name == filepath.Base(name) {
+			s.files[name] = struct{}{}
+		}
+	}
+}
+
+// save writes the index atomically.
+func (s *cdiSpecs) save() {
+	files := make([]string, 0, len(s.files))
+	for name := range s.files {
+		files = append(files, name)
+	}
+
+	sort.Strings(files)
+
+	data, err := json.Marshal(files)
+	if err == nil {
+		tmp := s.index + ".tmp"
+
+		if err = os.WriteFile(tmp, data, 0o600); err == nil {
+			err = os.Rename(tmp, s.index)
+		}
+	}
+
+	if err != nil {
+		klog.Warningf("Failed to save CDI spec index %s: %v", s.index, err)
+	}
+}
+
+// write writes the CDI spec of a device unless the device is already known
+// to the CDI cache and adds the spec file to the set. Returns a list of CDI
+// device names.
+func (s *cdiSpecs) write(ctx context.Context, spec *cdispec.Spec) ([]*pluginapi.CDIDevice, error) {
+	if spec == nil {
+		return []*pluginapi.CDIDevice{}, nil
+	}
+
+	names, err := writeCdiSpecToFilesystem(ctx, spec, s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	name := cdiSpecFileName(spec.Kind, spec.Devices[0].Name)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.load()
+
+	if _, ok := s.files[name]; !ok {
+		s.files[name] = struct{}{}
+		s.save()
+	}
+
+	return names, nil
+}
+
+func (s *cdiSpecs) isStatic() bool {
+	return s != nil && s.staticKind != ""
+}
+
+// sync removes the spec files of the devices missing from tree. In the static
+// mode, the specs of all the devices in tree are written first.
+func (s *cdiSpecs) sync(tree DeviceTree) {
+	current := make(map[string]struct{})
+
+	if s.isStatic() {
+		s.writeStatic(tree, current)
+	}
+
+	for _, devices := range tree {
+		for _, dev := range devices {
+			for _, spec := range dev.cdiSpecList() {
+				if len(spec.Devices) > 0 {
+					current[cdiSpecFileName(spec.Kind, spec.Devices[0].Name)] = struct{}{}
+				}
+			}
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.load()
+	s.remove(func(name string) bool {
+		_, found := current[name]
+
+		return !found
+	})
+}
+
+// writeStatic writes a spec with a CDI device for every device in tree which
+// doesn't come with a CDI spec of its own, and the specs given in DeviceInfo.
+// The names of the written files are added to current.
+func (s *cdiSpecs) writeStatic(tree DeviceTree, current map[string]struct{}) {
+	spec := staticCdiSpec(s.staticKind, tree)
+	name := strings.ReplaceAll(s.staticKind, "/", "-") + ".yaml"
+
+	if len(spec.Devices) > 0 {
+		if err := s.writeStaticSpec(spec, name); err != nil {
+			klog.Errorf("Static CDI spec write failed: %+v", err)
+		}
+
+		current[name] = struct{}{}
+	}
+
+	for _, devices := range tree {
+		for _, dev := range devices {
+			for _, devSpec := range dev.cdiSpecList() {
+				if _, err := s.write(context.Background(), devSpec); err != nil {
+					klog.Errorf("CDI spec write failed: %+v", err)
+				}
+			}
+		}
+	}
+}
+
+// writeStaticSpec (re)writes the static spec when its devices change.
+func (s *cdiSpecs) writeStaticSpec(spec *cdispec.Spec, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.load()
+
+	if _, ok := s.files[name]; ok && reflect.DeepEqual(spec, s.static) {
+		return nil
+	}
+
+	cache, err := cdi.NewCache(cdi.WithAutoRefresh(false), cdi.WithSpecDirs(s.dir))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err = cache.WriteSpec(spec, name); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Fix access issues due to: https://github.com/cncf-tags/container-device-interface/issues/224
+	if err = os.Chmod(filepath.Join(s.dir, name), 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+
+	s.static = spec
+	s.files[name] = struct{}{}
+	s.save()
+
+	return nil
+}
+
+// staticCdiDeviceName converts device type and ID to a CDI device name.
+func staticCdiDeviceName(devType, id string) string {
+	return strings.Trim(cdiInvalidNameChars.ReplaceAllString(devType+"-"+id, "-"), "-_.:")
+}
+
+// staticCdiDevice returns a CDI device with the device nodes and mounts of
+// a device. The environment variables are left to the allocation responses,
+// so that PostAllocate can still combine and renumber them. Devices with a
+// CDI spec of their own and devices without any container edits don't get one.
+func staticCdiDevice(devType, id string, info *DeviceInfo) (cdispec.Device, bool) {
+	if info.cdiSpec != nil {
+		return cdispec.Device{}, false
+	}
+
+	nodes := make([]*pluginapi.DeviceSpec, 0, len(info.nodes))
+	for i := range info.nodes {
+		nodes = append(nodes, &info.nodes[i])
+	}
+
+	mounts := make([]*pluginapi.Mount, 0, len(info.mounts))
+	for i := range info.mounts {
+		mounts = append(mounts, &info.mounts[i])
+	}
+
+	dev := cdispec.Device{
+		Name:           staticCdiDeviceName(devType, id),
+		ContainerEdits: cdiContainerEdits(nodes, mounts, nil),
+	}
+
+	edits := dev.ContainerEdits
+
+	return dev, len(edits.DeviceNodes) > 0 || len(edits.Mounts) > 0
+}
+
+// staticCdiSpec returns a spec of the given kind with the CDI devices of tree.
+func staticCdiSpec(kind string, tree DeviceTree) *cdispec.Spec {
+	spec := &cdispec.Spec{
+		Version: CDIVersion,
+		Kind:    kind,
+		Devices: []cdispec.Device{},
+	}
+
+	for devType, devices := range tree {
+		for id, info := range devices {
+			if dev, ok := staticCdiDevice(devType, id, &info); ok {
+				spec.Devices = append(spec.Devices, dev)
+			}
+		}
+	}
+
+	sort.Slice(spec.Devices, func(i, j int) bool {
+		return spec.Devices[i].Name < spec.Devices[j].Name
+	})
+
+	return spec
+}
+
+// cdiContainerEdits converts device nodes, mounts and environment variables
+// of an allocation into CDI container edits.
+func cdiContainerEdits(nodes []*pluginapi.DeviceSpec, mounts []*pluginapi.Mount, envs map[string]string) cdispec.ContainerEdits {
+	var edits cdispec.ContainerEdits
+
+	for _, node := range nodes {
+		edits.DeviceNodes = append(edits.DeviceNodes, &cdispec.DeviceNode{
+			HostPath:    node.HostPath,
+			Path:        node.ContainerPath,
+			Permissions: node.Permissions,
+		})
+	}
+
+	for _, mount := range mounts {
+		options := []string{"bind", "rw"}
+		if mount.ReadOnly {
+			options = []string{"bind", "ro"}
+		}
+
+		edits.Mounts = append(edits.Mounts, &cdispec.Mount{
+			HostPath:      mount.HostPath,
+			ContainerPath: mount.ContainerPath,
+			Type:          "none",
+			Options:       options,
+		})
+	}
+
+	for name, value := range envs {
+		edits.Env = append(edits.Env, name+"="+value)
+	}
+
+	sort.Strings(edits.Env)
+
+	return edits
+}
+
+func (s *cdiSpecs) remove(stale func(name string) bool) {
+	removed := false
+
+	for name := range s.files {
+		if !stale(name) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("Failed to remove CDI spec %s: %v", name, err)
+
+			continue
+		}
+
+		klog.V(2).Infof("Removed CDI spec %s", name)
+
+		delete(s.files, name)
+
+		removed = true
+	}
+
+	if removed {
+		s.save()
+	}
+}
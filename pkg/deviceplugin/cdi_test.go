@@ -0,0 +1,294 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	cdispec "tags.cncf.io/container-device-interface/specs-go"
+)
+
+func testCdiDevice(name string) DeviceInfo {
+	return NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, &cdispec.Spec{
+		Version: CDIVersion,
+		Kind:    CDIVendor + "/test",
+		Devices: []cdispec.Device{{
+			Name: name,
+			ContainerEdits: cdispec.ContainerEdits{
+				Env: []string{"DEVICE=" + name},
+			},
+		}},
+	})
+}
+
+func testCdiTree(names ...string) DeviceTree {
+	tree := NewDeviceTree()
+	for _, name := range names {
+		tree.AddDevice("test", name, testCdiDevice(name))
+	}
+
+	return tree
+}
+
+func writeTestCdiSpecs(t *testing.T, specs *cdiSpecs, tree DeviceTree) {
+	t.Helper()
+
+	for _, devices := range tree {
+		for _, dev := range devices {
+			for _, spec := range dev.cdiSpecList() {
+				if _, err := specs.write(context.Background(), spec); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func checkCdiSpecFiles(t *testing.T, dir string, expected map[string]bool) {
+	t.Helper()
+
+	for name, exists := range expected {
+		_, err := os.Stat(filepath.Join(dir, cdiSpecFileName(CDIVendor+"/test", name)))
+		if exists && err != nil {
+			t.Errorf("CDI spec of %s is missing: %v", name, err)
+		}
+
+		if !exists && !os.IsNotExist(err) {
+			t.Errorf("CDI spec of %s not removed: %v", name, err)
+		}
+	}
+}
+
+func TestCdiSpecsReconcile(t *testing.T) {
+	tcases := []struct {
+		expected map[string]bool
+		name     string
+		written  []string
+		current  []string
+	}{
+		{
+			name:     "nothing written",
+			current:  []string{"dev1"},
+			expected: map[string]bool{"dev1": false},
+		},
+		{
+			name:     "all devices exist",
+			written:  []string{"dev1", "dev2"},
+			current:  []string{"dev1", "dev2", "dev3"},
+			expected: map[string]bool{"dev1": true, "dev2": true, "dev3": false},
+		},
+		{
+			name:     "device removed",
+			written:  []string{"dev1", "dev2"},
+			current:  []string{"dev2"},
+			expected: map[string]bool{"dev1": false, "dev2": true},
+		},
+		{
+			name:     "all devices removed",
+			written:  []string{"dev1", "dev2"},
+			expected: map[string]bool{"dev1": false, "dev2": false},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			specs := newCdiSpecs(dir, "test.intel.com")
+
+			writeTestCdiSpecs(t, specs, testCdiTree(tc.written...))
+
+			specs.sync(testCdiTree(tc.current...))
+
+			checkCdiSpecFiles(t, dir, tc.expected)
+		})
+	}
+}
+
+func TestCdiSpecsRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTestCdiSpecs(t, newCdiSpecs(dir, "test.intel.com"), testCdiTree("dev1", "dev2"))
+
+	// A spec not written by the namespace is never touched.
+	writeTestCdiSpecs(t, newCdiSpecs(dir, "other.intel.com"), testCdiTree("other"))
+
+	// After a restart, the specs written by the previous run are known from the index.
+	specs := newCdiSpecs(dir, "test.intel.com")
+	specs.sync(testCdiTree("dev2"))
+
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev1": false, "dev2": true, "other": true})
+
+	specs.sync(testCdiTree())
+
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev2": false, "other": true})
+
+	if len(newCdiSpecs(dir, "test.intel.com").files) != 0 {
+		t.Error("index not emptied")
+	}
+}
+
+func TestCdiSpecsIndex(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "cdi")
+	outside := filepath.Join(root, "outside.yaml")
+
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(outside, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tcases := []struct {
+		name  string
+		index string
+	}{
+		{name: "broken index", index: `["`},
+		{name: "path in index", index: `["../outside.yaml"]`},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			specs := newCdiSpecs(dir, "test.intel.com")
+
+			if err := os.WriteFile(specs.index, []byte(tc.index), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			writeTestCdiSpecs(t, specs, testCdiTree("dev1"))
+
+			newCdiSpecs(dir, "test.intel.com").sync(testCdiTree())
+
+			checkCdiSpecFiles(t, dir, map[string]bool{"dev1": false})
+
+			if _, err := os.Stat(outside); err != nil {
+				t.Errorf("file outside of the CDI dir removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestNotifierReconcilesCdiSpecs(t *testing.T) {
+	dir := t.TempDir()
+	specs := newCdiSpecs(dir, "test.intel.com")
+
+	writeTestCdiSpecs(t, specs, testCdiTree("dev1", "dev2"))
+
+	updatesCh := make(chan updateInfo, 1)
+	n := newNotifier(updatesCh)
+	n.cdiSpecs = specs
+
+	n.Notify(testCdiTree("dev1"))
+
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev1": true, "dev2": false})
+}
+
+func TestCdiSpecsReconcileCommon(t *testing.T) {
+	dir := t.TempDir()
+	specs := newCdiSpecs(dir, "test.intel.com")
+
+	common := testCdiDevice("common").cdiSpec
+
+	commonTree := func(names ...string) DeviceTree {
+		tree := NewDeviceTree()
+
+		for _, name := range names {
+			info := testCdiDevice(name)
+			info.SetCdiCommonSpec(common)
+			tree.AddDevice("test", name, info)
+		}
+
+		return tree
+	}
+
+	writeTestCdiSpecs(t, specs, commonTree("dev1", "dev2"))
+
+	specs.sync(commonTree("dev2"))
+
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev1": false, "dev2": true, "common": true})
+
+	specs.sync(commonTree())
+
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev2": false, "common": false})
+}
+
+func TestCdiSpecsStatic(t *testing.T) {
+	dir := t.TempDir()
+	specs := newCdiSpecs(dir, "test.intel.com")
+	specs.staticKind = CDIVendor + "/test"
+
+	staticFile := filepath.Join(dir, "intel.cdi.k8s.io-test.yaml")
+
+	node := func(name string) DeviceInfo {
+		return NewDeviceInfoWithTopologyHints(pluginapi.Healthy, []pluginapi.DeviceSpec{{
+			HostPath:      "/dev/" + name,
+			ContainerPath: "/dev/" + name,
+			Permissions:   "rw",
+		}}, nil, map[string]string{"DEVICE": name}, nil, nil, nil)
+	}
+
+	tree := NewDeviceTree()
+	tree.AddDevice("pf", "/dev/dev0", node("dev0"))
+	tree.AddDevice("vf", "dev1", node("dev1"))
+	tree.AddDevice("vf", "dev2", testCdiDevice("dev2"))
+	tree.AddDevice("vf", "empty", NewDeviceInfoWithTopologyHints(pluginapi.Healthy, nil, nil, nil, nil, nil, nil))
+
+	specs.sync(tree)
+
+	spec, err := cdi.ReadSpec(staticFile, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, dev := range spec.Devices {
+		names = append(names, dev.Name)
+	}
+
+	if expected := []string{"pf-dev-dev0", "vf-dev1"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected static CDI devices %v, got %v", expected, names)
+	}
+
+	// The envs are given in the allocation responses.
+	if env := spec.Devices[1].ContainerEdits.Env; len(env) != 0 {
+		t.Errorf("unexpected envs: %v", env)
+	}
+
+	// Devices with a CDI spec of their own get their spec written as well.
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev2": true})
+
+	delete(tree["vf"], "dev1")
+	delete(tree["vf"], "dev2")
+	specs.sync(tree)
+
+	if spec, err = cdi.ReadSpec(staticFile, 0); err != nil || len(spec.Devices) != 1 {
+		t.Errorf("static spec not updated: %v", err)
+	}
+
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev2": false})
+
+	specs.sync(NewDeviceTree())
+
+	if _, err = os.Stat(staticFile); !os.IsNotExist(err) {
+		t.Errorf("static spec not removed: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"k8s.io/klog/v2"
+)
+
+const configFlagName = "config"
+
+var (
+	configFile = flag.String(configFlagName, "",
+		"YAML or JSON file mapping command line option names to values, e.g. a mounted ConfigMap. Options given on the command line override the file. The file is watched for changes, which the plugin applies if it supports reconfiguration")
+
+	// Configuration loaded by ParseFlags.
+	parsedConfig *config
+)
+
+// ParseFlags parses the command line flags like flag.Parse and sets the
+// flags not given on the command line from the file given with -config.
+// On invalid options, ParseFlags prints the error and exits with status 2
+// like flag.Parse does.
+func ParseFlags() {
+	flag.Parse()
+
+	if *configFile == "" {
+		return
+	}
+
+	cfg := newConfig(flag.CommandLine, *configFile)
+	if err := cfg.load(); err != nil {
+		fmt.Fprintf(flag.CommandLine.Output(), "%v\n", err)
+		os.Exit(2)
+	}
+
+	parsedConfig = cfg
+}
+
+// configValue is an option value in the config file. Scalars are taken as
+// they are written and sequences are joined with commas, so that
+// e.g. "allow-ids: [0x49c5, 0x49c6]" works.
+type configValue string
+
+func (v *configValue) UnmarshalYAML(unmarshal func(any) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*v = configValue(strings.Join(list, ","))
+
+		return nil
+	}
+
+	var scalar string
+	if err := unmarshal(&scalar); err != nil {
+		return errors.New("option values must be scalars or lists of scalars")
+	}
+
+	*v = configValue(scalar)
+
+	return nil
+}
+
+// config sets the options in a config file to the flags of a FlagSet.
+type config struct {
+	flags *flag.FlagSet
+	// Flags given on the command line, the file doesn't override them.
+	commandLine map[string]struct{}
+	// Options set from the file.
+	applied map[string]string
+	file    string
+}
+
+func newConfig(flags *flag.FlagSet, file string) *config {
+	c := &config{
+		flags:       flags,
+		file:        file,
+		commandLine: make(map[string]struct{}),
+		applied:     make(map[string]string),
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		c.commandLine[f.Name] = struct{}{}
+	})
+
+	return c
+}
+
+// read returns the options in the config file which are not overridden
+// on the command line.
+func (c *config) read() (map[string]string, error) {
+	data, err := os.ReadFile(c.file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	values := make(map[string]configValue)
+	if err = yaml.UnmarshalStrict(data, &values); err != nil {
+		return nil, errors.Wrapf(err, "invalid config file %s", c.file)
+	}
+
+	options := make(map[string]string)
+
+	for name, value := range values {
+		if name == configFlagName || c.flags.Lookup(name) == nil {
+			return nil, errors.Errorf("invalid config file %s: unknown option %q", c.file, name)
+		}
+
+		if _, found := c.commandLine[name]; found {
+			klog.V(2).Infof("Option %q in config file is overridden on the command line", name)
+
+			continue
+		}
+
+		options[name] = string(value)
+	}
+
+	return options, nil
+}
+
+// set sets the values of the flags in name order.
+func (c *config) set(options map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		if err := c.flags.Set(name, options[name]); err != nil {
+			return errors.Wrapf(err, "invalid value %q for option %q in config file %s", options[name], name, c.file)
+		}
+	}
+
+	return nil
+}
+
+// load sets the flags from the config file.
+func (c *config) load() error {
+	options, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	if err = c.set(options); err != nil {
+		return err
+	}
+
+	c.applied = options
+
+	return nil
+}
+
+// reload reads the config file and hands the changed options to the
+// Reconfigurer. If the file is invalid or the Reconfigurer fails, the
+// previous options stay in effect. Without a Reconfigurer, the changed
+// options are logged as needing a restart.
+func (c *config) reload(r Reconfigurer) error {
+	options, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[string]string)
+	previous := make(map[string]string)
+
+	for name, value := range options {
+		if old, found := c.applied[name]; !found || old != value {
+			changed[name] = value
+		}
+	}
+
+	for name := range c.applied {
+		if _, found := options[name]; !found {
+			changed[name] = c.flags.Lookup(name).DefValue
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if r == nil {
+		klog.Warningf("Changing options %q in config file %s requires restarting the plugin",
+			slices.Sorted(maps.Keys(changed)), c.file)
+
+		return nil
+	}
+
+	for name := range changed {
+		previous[name] = c.flags.Lookup(name).Value.String()
+	}
+
+	err = c.set(changed)
+	if err == nil {
+		klog.Infof("Reconfiguring with options %v", changed)
+
+		err = r.Reconfigure(changed)
+	}
+
+	if err != nil {
+		if restoreErr := c.set(previous); restoreErr != nil {
+			klog.Warningf("Unable to restore options: %+v", restoreErr)
+		}
+
+		return err
+	}
+
+	c.applied = options
+
+	return nil
+}
+
+// watch reconfigures r, which can be nil, when the config file changes until
+// ctx is canceled.
+func (c *config) watch(ctx context.Context, r Reconfigurer) {
+	watchDir(ctx, c.file, func() {
+		if err := c.reload(r); err != nil {
+			klog.Errorf("Config file not applied, keeping the previous options: %+v", err)
+		}
+	})
+}
+
+// watchDir calls changed on changes in the directory of file until ctx
+// is canceled. The directory is watched, so that also files which are
+// replaced, e.g. ConfigMap volumes, and files created later work.
+func watchDir(ctx context.Context, file string, changed func()) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		klog.Errorf("Unable to watch %s: %+v", file, err)
+
+		return
+	}
+	defer watcher.Close()
+
+	if err = watcher.Add(filepath.Dir(file)); err != nil {
+		klog.Errorf("Unable to watch %s: %+v", file, err)
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err = <-watcher.Errors:
+			klog.Warningf("Watching %s failed: %v", file, err)
+		case <-watcher.Events:
+			changed()
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type testOptions struct {
+	policy    string
+	allowIDs  string
+	sharedNum int
+	monitor   bool
+}
+
+func newTestFlags(t *testing.T, args ...string) (*flag.FlagSet, *testOptions) {
+	t.Helper()
+
+	opts := &testOptions{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.IntVar(&opts.sharedNum, "shared-dev-num", 1, "")
+	fs.StringVar(&opts.policy, "allocation-policy", "none", "")
+	fs.StringVar(&opts.allowIDs, "allow-ids", "", "")
+	fs.BoolVar(&opts.monitor, "enable-monitoring", false, "")
+	fs.String(configFlagName, "", "")
+
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	return fs, opts
+}
+
+func writeConfig(t *testing.T, file, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigLoad(t *testing.T) {
+	tcases := []struct {
+		name        string
+		content     string
+		args        []string
+		expected    testOptions
+		expectedErr bool
+	}{
+		{
+			name:     "yaml",
+			content:  "shared-dev-num: 3\nallocation-policy: packed\nallow-ids: [0x49c5, 0x49c6]\nenable-monitoring: true\n",
+			expected: testOptions{sharedNum: 3, policy: "packed", allowIDs: "0x49c5,0x49c6", monitor: true},
+		},
+		{
+			name:     "json",
+			content:  `{"shared-dev-num": 2, "allow-ids": "0x49c5"}`,
+			expected: testOptions{sharedNum: 2, policy: "none", allowIDs: "0x49c5"},
+		},
+		{
+			name:     "command line overrides",
+			content:  "shared-dev-num: 3\nallocation-policy: packed\n",
+			args:     []string{"-shared-dev-num=5"},
+			expected: testOptions{sharedNum: 5, policy: "packed"},
+		},
+		{
+			name:        "unknown option",
+			content:     "shared-dev-nums: 3\n",
+			expectedErr: true,
+		},
+		{
+			name:        "config option",
+			content:     "config: other.yaml\n",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid value",
+			content:     "shared-dev-num: many\n",
+			expectedErr: true,
+		},
+		{
+			name:        "nested value",
+			content:     "allow-ids:\n  id: 0x49c5\n",
+			expectedErr: true,
+		},
+		{
+			name:        "duplicate option",
+			content:     "shared-dev-num: 3\nshared-dev-num: 4\n",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			writeConfig(t, file, tc.content)
+
+			fs, opts := newTestFlags(t, tc.args...)
+
+			err := newConfig(fs, file).load()
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if *opts != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, *opts)
+			}
+		})
+	}
+
+	fs, _ := newTestFlags(t)
+	if err := newConfig(fs, filepath.Join(t.TempDir(), "missing.yaml")).load(); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
+
+// reconfigurerStub records the options it is reconfigured with.
+type reconfigurerStub struct {
+	err     error
+	options chan map[string]string
+}
+
+func (r *reconfigurerStub) Reconfigure(options map[string]string) error {
+	if r.options != nil {
+		r.options <- options
+	}
+
+	return r.err
+}
+
+func TestConfigReload(t *testing.T) {
+	tcases := []struct {
+		reconfigureErr error
+		name           string
+		content        string
+		expected       map[string]string
+		expectedOpts   testOptions
+		expectedErr    bool
+	}{
+		{
+			name:         "unchanged",
+			content:      "shared-dev-num: 3\nallocation-policy: packed\n",
+			expectedOpts: testOptions{sharedNum: 3, policy: "packed"},
+		},
+		{
+			name:         "changed and added",
+			content:      "shared-dev-num: 4\nallocation-policy: packed\nallow-ids: 0x49c5\n",
+			expected:     map[string]string{"shared-dev-num": "4", "allow-ids": "0x49c5"},
+			expectedOpts: testOptions{sharedNum: 4, policy: "packed", allowIDs: "0x49c5"},
+		},
+		{
+			name:         "removed",
+			content:      "shared-dev-num: 3\n",
+			expected:     map[string]string{"allocation-policy": "none"},
+			expectedOpts: testOptions{sharedNum: 3, policy: "none"},
+		},
+		{
+			name:         "invalid value",
+			content:      "shared-dev-num: 4\nallocation-policy: packed\nenable-monitoring: maybe\n",
+			expectedOpts: testOptions{sharedNum: 3, policy: "packed"},
+			expectedErr:  true,
+		},
+		{
+			name:           "reconfigure fails",
+			content:        "shared-dev-num: 0\nallocation-policy: packed\n",
+			reconfigureErr: errors.New("invalid shared-dev-num"),
+			expected:       map[string]string{"shared-dev-num": "0"},
+			expectedOpts:   testOptions{sharedNum: 3, policy: "packed"},
+			expectedErr:    true,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			writeConfig(t, file, "shared-dev-num: 3\nallocation-policy: packed\n")
+
+			fs, opts := newTestFlags(t)
+
+			cfg := newConfig(fs, file)
+			if err := cfg.load(); err != nil {
+				t.Fatal(err)
+			}
+
+			writeConfig(t, file, tc.content)
+
+			r := &reconfigurerStub{err: tc.reconfigureErr, options: make(chan map[string]string, 1)}
+
+			err := cfg.reload(r)
+			if tc.expectedErr != (err != nil) {
+				t.Errorf("unexpected error: %+v", err)
+			}
+
+			var options map[string]string
+
+			select {
+			case options = <-r.options:
+			default:
+			}
+
+			if !reflect.DeepEqual(options, tc.expected) {
+				t.Errorf("expected reconfiguration with %v, got %v", tc.expected, options)
+			}
+
+			if *opts != tc.expectedOpts {
+				t.Errorf("expected %+v, got %+v", tc.expectedOpts, *opts)
+			}
+
+			// Failed reloads are retried on the next change.
+			if tc.expectedErr {
+				writeConfig(t, file, "shared-dev-num: 3\nallocation-policy: packed\n")
+
+				if err = cfg.reload(r); err != nil || len(r.options) != 0 {
+					t.Errorf("unexpected reconfiguration after restoring the file: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigReloadWithoutReconfigurer(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, file, "shared-dev-num: 3\n")
+
+	fs, opts := newTestFlags(t)
+
+	cfg := newConfig(fs, file)
+	if err := cfg.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig(t, file, "shared-dev-num: 4\nallow-ids: 0x49c5\n")
+
+	if err := cfg.reload(nil); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+
+	// The options need a restart, so the plugin keeps running with the
+	// loaded ones.
+	if expected := (testOptions{sharedNum: 3, policy: "none"}); *opts != expected {
+		t.Errorf("expected %+v, got %+v", expected, *opts)
+	}
+
+	if expected := map[string]string{"shared-dev-num": "3"}; !reflect.DeepEqual(cfg.applied, expected) {
+		t.Errorf("expected applied options %v, got %v", expected, cfg.applied)
+	}
+}
+
+func TestConfigWatch(t *testing.T) {
+	dir, err := os.MkdirTemp("/tmp", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yaml")
+	writeConfig(t, file, "shared-dev-num: 3\n")
+
+	fs, _ := newTestFlags(t)
+
+	cfg := newConfig(fs, file)
+	if err = cfg.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &reconfigurerStub{options: make(chan map[string]string, 10)}
+	go cfg.watch(ctx, r)
+
+	// Give the watch time to start.
+	time.Sleep(100 * time.Millisecond)
+
+	// ConfigMap volumes replace the file with a rename.
+	tmp := filepath.Join(dir, "config.yaml.tmp")
+	writeConfig(t, tmp, "shared-dev-num: 2\n")
+
+	if err = os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case options := <-r.options:
+		if options["shared-dev-num"] != "2" {
+			t.Errorf("unexpected options %v", options)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("plugin not reconfigured")
+	}
+}
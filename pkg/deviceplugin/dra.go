@@ -0,0 +1,698 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	resourceapi "k8s.io/api/resource/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+	drapb "k8s.io/kubelet/pkg/apis/dra/v1"
+	registerapi "k8s.io/kubelet/pkg/apis/pluginregistration/v1"
+	cdispec "tags.cncf.io/container-device-interface/specs-go"
+)
+
+const (
+	// KubeletPluginsDir is the directory where DRA drivers create their service sockets.
+	KubeletPluginsDir = "/var/lib/kubelet/plugins"
+	// KubeletPluginsRegistryDir is the directory kubelet watches for plugin registration sockets.
+	KubeletPluginsRegistryDir = "/var/lib/kubelet/plugins_registry"
+
+	draDeviceNameMaxLength = 63
+	draPublishTimeout      = 30 * time.Second
+)
+
+var (
+	draInvalidNameChars  = regexp.MustCompile(`[^a-z0-9]+`)
+	draAttributeNameRE   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,31}$`)
+	draSemanticVersionRE = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+)
+
+// draDevice links a DRA device name to a device in the DeviceTree.
+type draDevice struct {
+	devType string
+	id      string
+	info    DeviceInfo
+}
+
+// DRADriver publishes the devices found by a Scanner as ResourceSlices and
+// serves the kubelet DRA plugin API for them. It is an alternative to
+// Manager for clusters using Dynamic Resource Allocation.
+type DRADriver struct {
+	drapb.UnsafeDRAPluginServer
+	registerapi.UnsafeRegistrationServer
+	scanner      Scanner
+	client       kubernetes.Interface
+	devices      map[string]draDevice
+	postAllocate postAllocateFunc
+	regServer    *grpc.Server
+	draServer    *grpc.Server
+	driverName   string
+	nodeName     string
+	pluginDir    string
+	registryDir  string
+	cdiSpecs     *cdiSpecs
+	published    [][]resourceapi.Device
+	// Names of the ResourceSlices published by an earlier run.
+	leftover   []string
+	generation int64
+	mutex      sync.Mutex
+	adopted    bool
+}
+
+// NewDRADriver creates a DRA driver named driverName for the node nodeName.
+// Devices found by scanner are published with client.
+func NewDRADriver(driverName, nodeName string, scanner Scanner, client kubernetes.Interface) *DRADriver {
+	d := &DRADriver{
+		scanner:     scanner,
+		client:      client,
+		devices:     make(map[string]draDevice),
+		driverName:  driverName,
+		nodeName:    nodeName,
+		pluginDir:   filepath.Join(KubeletPluginsDir, driverName),
+		registryDir: KubeletPluginsRegistryDir,
+		cdiSpecs:    newCdiSpecs(CDIDir, driverName),
+	}
+
+	if postAllocator, ok := scanner.(PostAllocator); ok {
+		d.postAllocate = postAllocator.PostAllocate
+	}
+
+	return d
+}
+
+// newDRADriverInCluster creates a DRA driver using the in-cluster configuration
+// and the node name given in the NODE_NAME environment variable.
+func newDRADriverInCluster(driverName string, scanner Scanner) (*DRADriver, error) {
+	nodeName, client, err := inClusterClient("DRA")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDRADriver(driverName, nodeName, scanner, client), nil
+}
+
+// inClusterClient returns the node name given in the NODE_NAME environment
+// variable and a client using the in-cluster configuration. The feature
+// needing them is named in the errors.
+func inClusterClient(feature string) (string, kubernetes.Interface, error) {
+	nodeName := os.Getenv("NODE_NAME")
+	if nodeName == "" {
+		return "", nil, errors.Errorf("NODE_NAME environment variable is required for %s", feature)
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to get in-cluster config")
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to create kubernetes client")
+	}
+
+	return nodeName, client, nil
+}
+
+// Run starts the DRA gRPC services, registers the driver with kubelet and
+// publishes the devices reported by the Scanner until ctx is canceled.
+// The sockets are removed before Run returns.
+func (d *DRADriver) Run(ctx context.Context) error {
+	if err := d.serve(); err != nil {
+		return err
+	}
+
+	defer d.stop()
+
+	if err := d.scanner.Scan(ctx, &scanTracer{next: d}); err != nil && ctx.Err() == nil {
+		return errors.Wrap(err, "device scan failed")
+	}
+
+	return nil
+}
+
+func (d *DRADriver) cdiClass() string {
+	return strings.SplitN(d.driverName, ".", 2)[0]
+}
+
+func (d *DRADriver) draSocket() string {
+	return filepath.Join(d.pluginDir, "dra.sock")
+}
+
+func (d *DRADriver) registrationSocket() string {
+	return filepath.Join(d.registryDir, d.driverName+"-reg.sock")
+}
+
+func listenAndServe(socket string, srv *grpc.Server) error {
+	// We don't care if the socket file doesn't exist.
+	_ = os.Remove(socket)
+
+	var lc net.ListenConfig
+
+	lis, err := lc.Listen(context.Background(), "unix", socket)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to listen to socket %s", socket)
+	}
+
+	go func() {
+		klog.V(1).Infof("Start server at: %s", socket)
+
+		if serveErr := srv.Serve(lis); serveErr != nil {
+			klog.Errorf("unable to start gRPC server: %+v", serveErr)
+		}
+	}()
+
+	return waitForServer(socket, 10*time.Second)
+}
+
+// serve starts the DRA service first and then the registration service,
+// which makes kubelet connect to the DRA service.
+func (d *DRADriver) serve() error {
+	if err := os.MkdirAll(d.pluginDir, 0o750); err != nil {
+		return errors.Wrap(err, "Failed to create plugin directory")
+	}
+
+	d.draServer = grpc.NewServer()
+	drapb.RegisterDRAPluginServer(d.draServer, d)
+
+	if err := listenAndServe(d.draSocket(), d.draServer); err != nil {
+		return err
+	}
+
+	d.regServer = grpc.NewServer()
+	registerapi.RegisterRegistrationServer(d.regServer, d)
+
+	return listenAndServe(d.registrationSocket(), d.regServer)
+}
+
+// stop stops the gRPC services and removes their sockets. The registration
+// socket goes first so that kubelet unregisters the driver.
+func (d *DRADriver) stop() {
+	if d.regServer != nil {
+		d.regServer.Stop()
+		_ = os.Remove(d.registrationSocket())
+	}
+
+	if d.draServer != nil {
+		d.draServer.Stop()
+		_ = os.Remove(d.draSocket())
+	}
+}
+
+// GetInfo implements the kubelet plugin registration API.
+func (d *DRADriver) GetInfo(ctx context.Context, req *registerapi.InfoRequest) (*registerapi.PluginInfo, error) {
+	return &registerapi.PluginInfo{
+		Type:              registerapi.DRAPlugin,
+		Name:              d.driverName,
+		Endpoint:          d.draSocket(),
+		SupportedVersions: []string{drapb.DRAPluginService},
+	}, nil
+}
+
+// NotifyRegistrationStatus implements the kubelet plugin registration API.
+func (d *DRADriver) NotifyRegistrationStatus(ctx context.Context, status *registerapi.RegistrationStatus) (*registerapi.RegistrationStatusResponse, error) {
+	if !status.PluginRegistered {
+		klog.Errorf("DRA driver %s registration failed: %s", d.driverName, status.Error)
+	} else {
+		klog.V(1).Infof("DRA driver %s registered", d.driverName)
+	}
+
+	return &registerapi.RegistrationStatusResponse{}, nil
+}
+
+// draDeviceName converts device type and ID to a DNS label usable as a DRA device name.
+func draDeviceName(devType, id string) string {
+	name := strings.ToLower(devType + "-" + id)
+	name = strings.Trim(draInvalidNameChars.ReplaceAllString(name, "-"), "-")
+
+	if len(name) > draDeviceNameMaxLength {
+		name = strings.TrimRight(name[:draDeviceNameMaxLength], "-")
+	}
+
+	return name
+}
+
+// Notify implements the Notifier interface. Found devices are published as ResourceSlices.
+func (d *DRADriver) Notify(tree DeviceTree) {
+	devices := make(map[string]draDevice)
+
+	for devType, devs := range tree {
+		for id, info := range devs {
+			name := draDeviceName(devType, id)
+			if _, found := devices[name]; found {
+				klog.Warningf("Skipping %s/%s: DRA device name %s is already in use", devType, id, name)
+
+				continue
+			}
+
+			devices[name] = draDevice{
+				devType: devType,
+				id:      id,
+				info:    info,
+			}
+		}
+	}
+
+	d.mutex.Lock()
+	d.devices = devices
+	d.mutex.Unlock()
+
+	// Claim specs are removed when kubelet unprepares the claims, only
+	// the specs of vanished devices are collected here.
+	d.cdiSpecs.sync(tree)
+
+	ctx, cancel := context.WithTimeout(context.Background(), draPublishTimeout)
+	defer cancel()
+
+	if err := d.publish(ctx, devices); err != nil {
+		klog.Errorf("Failed to publish ResourceSlices: %+v", err)
+	}
+}
+
+// draAttributes returns the DRA attributes of a device. Device attributes
+// with names not valid in DRA are left out and versions which are not
+// semantic versions are published as strings.
+func draAttributes(dev draDevice) map[resourceapi.QualifiedName]resourceapi.DeviceAttribute {
+	attrs := map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{}
+
+	for name, attr := range dev.info.attributes {
+		if !draAttributeNameRE.MatchString(name) {
+			klog.V(4).Infof("Attribute %q of %s/%s is not a valid DRA attribute name", name, dev.devType, dev.id)
+
+			continue
+		}
+
+		if attr.VersionValue != nil && !draSemanticVersionRE.MatchString(*attr.VersionValue) {
+			attr = DeviceAttribute{StringValue: attr.VersionValue}
+		}
+
+		attrs[resourceapi.QualifiedName(name)] = resourceapi.DeviceAttribute{
+			IntValue:     attr.IntValue,
+			BoolValue:    attr.BoolValue,
+			StringValue:  attr.StringValue,
+			VersionValue: attr.VersionValue,
+		}
+	}
+
+	attrs["type"] = resourceapi.DeviceAttribute{StringValue: &dev.devType}
+	attrs["id"] = resourceapi.DeviceAttribute{StringValue: &dev.id}
+
+	if dev.info.topology != nil && len(dev.info.topology.Nodes) == 1 {
+		numa := dev.info.topology.Nodes[0].ID
+		attrs["numaNode"] = resourceapi.DeviceAttribute{IntValue: &numa}
+	}
+
+	return attrs
+}
+
+// sliceDevices splits the healthy devices into chunks fitting into
+// ResourceSlices. Unhealthy devices are not published so that the scheduler
+// doesn't allocate them.
+func sliceDevices(devices map[string]draDevice) [][]resourceapi.Device {
+	names := make([]string, 0, len(devices))
+
+	for name, dev := range devices {
+		if dev.info.state != pluginapi.Healthy {
+			klog.V(4).Infof("Not publishing %s/%s: device is %s", dev.devType, dev.id, dev.info.state)
+
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	chunks := [][]resourceapi.Device{}
+
+	for chunk := range slices.Chunk(names, resourceapi.ResourceSliceMaxDevices) {
+		slice := make([]resourceapi.Device, 0, len(chunk))
+
+		for _, name := range chunk {
+			slice = append(slice, resourceapi.Device{
+				Name:       name,
+				Attributes: draAttributes(devices[name]),
+			})
+		}
+
+		chunks = append(chunks, slice)
+	}
+
+	return chunks
+}
+
+func (d *DRADriver) sliceName(idx int) string {
+	return fmt.Sprintf("%s-%s-%d", d.nodeName, d.driverName, idx)
+}
+
+// adoptSlices takes over the ResourceSlices of the node published by an
+// earlier run of the driver: the pool generation continues from theirs and
+// the slices not published again are deleted.
+func (d *DRADriver) adoptSlices(ctx context.Context) error {
+	list, err := d.client.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			resourceapi.ResourceSliceSelectorNodeName: d.nodeName,
+			resourceapi.ResourceSliceSelectorDriver:   d.driverName,
+		}.String(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list ResourceSlices")
+	}
+
+	for i := range list.Items {
+		slice := &list.Items[i]
+		if slice.Spec.Driver != d.driverName || slice.Spec.NodeName == nil || *slice.Spec.NodeName != d.nodeName {
+			continue
+		}
+
+		d.generation = max(d.generation, slice.Spec.Pool.Generation)
+		d.leftover = append(d.leftover, slice.Name)
+	}
+
+	klog.V(2).Infof("Found %d ResourceSlices of an earlier run (generation %d)", len(d.leftover), d.generation)
+
+	d.adopted = true
+
+	return nil
+}
+
+// publish creates, updates and deletes the node's ResourceSlices to match the devices.
+func (d *DRADriver) publish(ctx context.Context, devices map[string]draDevice) error {
+	if !d.adopted {
+		if err := d.adoptSlices(ctx); err != nil {
+			return err
+		}
+	}
+
+	chunks := sliceDevices(devices)
+
+	if d.published != nil && reflect.DeepEqual(chunks, d.published) {
+		return nil
+	}
+
+	d.generation++
+
+	slicesAPI := d.client.ResourceV1().ResourceSlices()
+
+	for idx, chunk := range chunks {
+		slice := &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: d.sliceName(idx),
+			},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   d.driverName,
+				NodeName: &d.nodeName,
+				Pool: resourceapi.ResourcePool{
+					Name:               d.nodeName,
+					Generation:         d.generation,
+					ResourceSliceCount: int64(len(chunks)),
+				},
+				Devices: chunk,
+			},
+		}
+
+		old, err := slicesAPI.Get(ctx, slice.Name, metav1.GetOptions{})
+
+		switch {
+		case apierrors.IsNotFound(err):
+			_, err = slicesAPI.Create(ctx, slice, metav1.CreateOptions{})
+		case err == nil:
+			slice.ResourceVersion = old.ResourceVersion
+			_, err = slicesAPI.Update(ctx, slice, metav1.UpdateOptions{})
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to publish ResourceSlice %s", slice.Name)
+		}
+	}
+
+	if err := d.deleteStaleSlices(ctx, len(chunks)); err != nil {
+		return err
+	}
+
+	published := 0
+	for _, chunk := range chunks {
+		published += len(chunk)
+	}
+
+	klog.V(1).Infof("Published %d devices in %d ResourceSlices (generation %d)", published, len(chunks), d.generation)
+
+	d.published = chunks
+
+	return nil
+}
+
+// deleteStaleSlices deletes the ResourceSlices published earlier which are
+// not among the count slices published now.
+func (d *DRADriver) deleteStaleSlices(ctx context.Context, count int) error {
+	current := make(map[string]struct{}, count)
+	for idx := range count {
+		current[d.sliceName(idx)] = struct{}{}
+	}
+
+	stale := slices.Clone(d.leftover)
+	for idx := count; idx < len(d.published); idx++ {
+		stale = append(stale, d.sliceName(idx))
+	}
+
+	for _, name := range stale {
+		if _, found := current[name]; found {
+			continue
+		}
+
+		err := d.client.ResourceV1().ResourceSlices().Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete ResourceSlice %s", name)
+		}
+	}
+
+	d.leftover = nil
+
+	return nil
+}
+
+// NodePrepareResources implements the kubelet DRA plugin API.
+func (d *DRADriver) NodePrepareResources(ctx context.Context, req *drapb.NodePrepareResourcesRequest) (*drapb.NodePrepareResourcesResponse, error) {
+	resp := &drapb.NodePrepareResourcesResponse{
+		Claims: make(map[string]*drapb.NodePrepareResourceResponse),
+	}
+
+	for _, claim := range req.Claims {
+		devices, err := d.prepareClaim(ctx, claim)
+		if err != nil {
+			klog.Errorf("Failed to prepare claim %s/%s: %+v", claim.Namespace, claim.Name, err)
+
+			resp.Claims[claim.Uid] = &drapb.NodePrepareResourceResponse{Error: err.Error()}
+
+			continue
+		}
+
+		resp.Claims[claim.Uid] = &drapb.NodePrepareResourceResponse{Devices: devices}
+	}
+
+	return resp, nil
+}
+
+func (d *DRADriver) prepareClaim(ctx context.Context, claim *drapb.Claim) ([]*drapb.Device, error) {
+	rc, err := d.client.ResourceV1().ResourceClaims(claim.Namespace).Get(ctx, claim.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ResourceClaim")
+	}
+
+	if string(rc.UID) != claim.Uid {
+		return nil, errors.Errorf("ResourceClaim UID mismatch: %s != %s", rc.UID, claim.Uid)
+	}
+
+	if rc.Status.Allocation == nil {
+		return nil, errors.New("ResourceClaim is not allocated")
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	requests := []string{}
+	results := map[string][]resourceapi.DeviceRequestAllocationResult{}
+
+	for _, result := range rc.Status.Allocation.Devices.Results {
+		if result.Driver != d.driverName || result.Pool != d.nodeName {
+			continue
+		}
+
+		if _, found := d.devices[result.Device]; !found {
+			return nil, errors.Errorf("allocated device %s not found", result.Device)
+		}
+
+		if _, found := results[result.Request]; !found {
+			requests = append(requests, result.Request)
+		}
+
+		results[result.Request] = append(results[result.Request], result)
+	}
+
+	prepared := []*drapb.Device{}
+	spec := &cdispec.Spec{
+		Version: CDIVersion,
+		Kind:    d.cdiKind(),
+	}
+
+	for _, request := range requests {
+		devices, cdiDevices, err := d.prepareRequest(ctx, claim.Uid, results[request])
+		if err != nil {
+			return nil, err
+		}
+
+		prepared = append(prepared, devices...)
+		spec.Devices = append(spec.Devices, cdiDevices...)
+	}
+
+	if len(spec.Devices) > 0 {
+		if _, err := writeCdiSpecToFilesystem(ctx, spec, d.cdiSpecs.dir); err != nil {
+			return nil, errors.Wrap(err, "CDI spec write failed")
+		}
+	}
+
+	return prepared, nil
+}
+
+// prepareRequest prepares the devices allocated for one request of a claim.
+// Every device gets a claim specific CDI device with its device nodes and
+// mounts. Containers get all the devices of the requests they use, so the
+// envs are combined by PostAllocate over the request and given with every
+// device of it.
+func (d *DRADriver) prepareRequest(ctx context.Context, uid string, results []resourceapi.DeviceRequestAllocationResult) ([]*drapb.Device, []cdispec.Device, error) {
+	devices := map[string]DeviceInfo{}
+	responses := make([]*pluginapi.ContainerAllocateResponse, 0, len(results))
+	combined := &pluginapi.ContainerAllocateResponse{
+		Envs:        map[string]string{},
+		Annotations: map[string]string{},
+	}
+
+	for _, result := range results {
+		devices[result.Device] = d.devices[result.Device].info
+
+		cresp, err := allocateContainer(ctx, devices, []string{result.Device}, d.cdiSpecs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		responses = append(responses, cresp)
+
+		combined.Devices = append(combined.Devices, cresp.Devices...)
+		combined.Mounts = append(combined.Mounts, cresp.Mounts...)
+		combined.CdiDevices = append(combined.CdiDevices, cresp.CdiDevices...)
+		maps.Copy(combined.Envs, cresp.Envs)
+		maps.Copy(combined.Annotations, cresp.Annotations)
+	}
+
+	if d.postAllocate != nil {
+		response := &pluginapi.AllocateResponse{
+			ContainerResponses: []*pluginapi.ContainerAllocateResponse{combined},
+		}
+
+		if err := d.postAllocate(response); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(combined.Annotations) > 0 {
+		klog.Warningf("Annotations are not supported with DRA, ignoring: %v", combined.Annotations)
+	}
+
+	prepared := make([]*drapb.Device, 0, len(results))
+	cdiDevices := []cdispec.Device{}
+
+	for i, result := range results {
+		dev := &drapb.Device{
+			RequestNames: []string{result.Request},
+			PoolName:     result.Pool,
+			DeviceName:   result.Device,
+		}
+
+		edits := cdiContainerEdits(responses[i].Devices, responses[i].Mounts, combined.Envs)
+		if len(edits.DeviceNodes) > 0 || len(edits.Mounts) > 0 || len(edits.Env) > 0 {
+			name := claimCdiDeviceName(uid, result.Device)
+
+			cdiDevices = append(cdiDevices, cdispec.Device{
+				Name:           name,
+				ContainerEdits: edits,
+			})
+			dev.CdiDeviceIds = append(dev.CdiDeviceIds, d.cdiKind()+"="+name)
+		}
+
+		for _, name := range responses[i].CdiDevices {
+			dev.CdiDeviceIds = append(dev.CdiDeviceIds, name.Name)
+		}
+
+		prepared = append(prepared, dev)
+	}
+
+	return prepared, cdiDevices, nil
+}
+
+func (d *DRADriver) cdiKind() string {
+	return CDIVendor + "/" + d.cdiClass()
+}
+
+// claimCdiDeviceName returns the name of the CDI device of a claim's device.
+// The claim's CDI spec file is named after its first device.
+func claimCdiDeviceName(uid, device string) string {
+	return "claim-" + uid + "-" + device
+}
+
+// NodeUnprepareResources implements the kubelet DRA plugin API.
+func (d *DRADriver) NodeUnprepareResources(ctx context.Context, req *drapb.NodeUnprepareResourcesRequest) (*drapb.NodeUnprepareResourcesResponse, error) {
+	resp := &drapb.NodeUnprepareResourcesResponse{
+		Claims: make(map[string]*drapb.NodeUnprepareResourceResponse),
+	}
+
+	for _, claim := range req.Claims {
+		resp.Claims[claim.Uid] = &drapb.NodeUnprepareResourceResponse{}
+
+		specFiles, err := filepath.Glob(filepath.Join(d.cdiSpecs.dir, cdiSpecFileName(d.cdiKind(), claimCdiDeviceName(claim.Uid, "*"))))
+		if err != nil {
+			resp.Claims[claim.Uid].Error = err.Error()
+
+			continue
+		}
+
+		for _, specFile := range specFiles {
+			if err := os.Remove(specFile); err != nil && !os.IsNotExist(err) {
+				resp.Claims[claim.Uid].Error = err.Error()
+			}
+		}
+	}
+
+	return resp, nil
+}
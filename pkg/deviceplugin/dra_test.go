@@ -0,0 +1,478 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+	drapb "k8s.io/kubelet/pkg/apis/dra/v1"
+	registerapi "k8s.io/kubelet/pkg/apis/pluginregistration/v1"
+	"tags.cncf.io/container-device-interface/pkg/cdi"
+	cdispec "tags.cncf.io/container-device-interface/specs-go"
+)
+
+const (
+	testDriverName = "test.intel.com"
+	testNodeName   = "node1"
+)
+
+func newTestDRADriver(t *testing.T, objects ...any) (*DRADriver, *fake.Clientset) {
+	t.Helper()
+
+	client := fake.NewClientset()
+
+	for _, obj := range objects {
+		if claim, ok := obj.(*resourceapi.ResourceClaim); ok {
+			if _, err := client.ResourceV1().ResourceClaims(claim.Namespace).Create(context.Background(), claim, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	d := NewDRADriver(testDriverName, testNodeName, &devicePluginStub{}, client)
+	d.cdiSpecs = newCdiSpecs(t.TempDir(), testDriverName)
+
+	return d, client
+}
+
+func TestDRADeviceName(t *testing.T) {
+	tcases := []struct {
+		devType  string
+		id       string
+		expected string
+	}{
+		{devType: "i915", id: "card0-1", expected: "i915-card0-1"},
+		{devType: "vfio", id: "0000:6b:00.1", expected: "vfio-0000-6b-00-1"},
+		{devType: "wq-user-shared", id: "wq-user-shared-wq0.0-3", expected: "wq-user-shared-wq-user-shared-wq0-0-3"},
+		{devType: "PF", id: "/dev/dlb0", expected: "pf-dev-dlb0"},
+		{devType: "t", id: fmt.Sprintf("%070d", 0), expected: "t-" + fmt.Sprintf("%061d", 0)},
+	}
+
+	for _, tc := range tcases {
+		if name := draDeviceName(tc.devType, tc.id); name != tc.expected {
+			t.Errorf("%s/%s: expected %s, got %s", tc.devType, tc.id, tc.expected, name)
+		}
+	}
+}
+
+func TestDRANotify(t *testing.T) {
+	d, client := newTestDRADriver(t)
+	ctx := context.Background()
+
+	tree := NewDeviceTree()
+	for i := range resourceapi.ResourceSliceMaxDevices + 1 {
+		tree.AddDevice("typea", fmt.Sprintf("dev%d", i), DeviceInfo{
+			state:    pluginapi.Healthy,
+			topology: &pluginapi.TopologyInfo{Nodes: []*pluginapi.NUMANode{{ID: 1}}},
+		})
+	}
+
+	d.Notify(tree)
+
+	slices, err := client.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(slices.Items) != 2 {
+		t.Fatalf("expected 2 ResourceSlices, got %d", len(slices.Items))
+	}
+
+	for _, slice := range slices.Items {
+		if slice.Spec.Driver != testDriverName || *slice.Spec.NodeName != testNodeName {
+			t.Errorf("unexpected driver or node in slice %s", slice.Name)
+		}
+
+		if slice.Spec.Pool.Generation != 1 || slice.Spec.Pool.ResourceSliceCount != 2 {
+			t.Errorf("unexpected pool in slice %s: %+v", slice.Name, slice.Spec.Pool)
+		}
+
+		attrs := slice.Spec.Devices[0].Attributes
+		if *attrs["type"].StringValue != "typea" || *attrs["numaNode"].IntValue != 1 {
+			t.Errorf("unexpected attributes in slice %s: %+v", slice.Name, attrs)
+		}
+	}
+
+	// Unchanged devices don't generate updates.
+	d.Notify(tree)
+
+	if d.generation != 1 {
+		t.Errorf("expected generation 1, got %d", d.generation)
+	}
+
+	tree = NewDeviceTree()
+	tree.AddDevice("typeb", "dev0", DeviceInfo{state: pluginapi.Healthy})
+	tree.AddDevice("typeb", "dev1", DeviceInfo{state: pluginapi.Unhealthy})
+
+	d.Notify(tree)
+
+	slices, err = client.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(slices.Items) != 1 {
+		t.Fatalf("expected 1 ResourceSlice, got %d", len(slices.Items))
+	}
+
+	// Unhealthy devices are not published.
+	if slices.Items[0].Spec.Pool.Generation != 2 || len(slices.Items[0].Spec.Devices) != 1 ||
+		slices.Items[0].Spec.Devices[0].Name != "typeb-dev0" {
+		t.Errorf("unexpected ResourceSlice: %+v", slices.Items[0].Spec)
+	}
+}
+
+func TestDRANotifyAfterRestart(t *testing.T) {
+	d, client := newTestDRADriver(t)
+	ctx := context.Background()
+
+	otherNode := "node2"
+
+	// Slices of an earlier run, which had more devices, and of another node.
+	for idx, node := range []string{testNodeName, testNodeName, testNodeName, otherNode} {
+		slice := &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s-%d", node, testDriverName, idx)},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   testDriverName,
+				NodeName: &node,
+				Pool:     resourceapi.ResourcePool{Name: node, Generation: 5, ResourceSliceCount: 3},
+			},
+		}
+
+		if _, err := client.ResourceV1().ResourceSlices().Create(ctx, slice, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tree := NewDeviceTree()
+	tree.AddDevice("typea", "dev0", DeviceInfo{state: pluginapi.Healthy})
+
+	d.Notify(tree)
+
+	slices, err := client.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+
+	for _, slice := range slices.Items {
+		names = append(names, slice.Name)
+
+		if *slice.Spec.NodeName == testNodeName && (slice.Spec.Pool.Generation != 6 || slice.Spec.Pool.ResourceSliceCount != 1) {
+			t.Errorf("unexpected pool in slice %s: %+v", slice.Name, slice.Spec.Pool)
+		}
+	}
+
+	sort.Strings(names)
+
+	expected := []string{d.sliceName(0), otherNode + "-" + testDriverName + "-3"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected ResourceSlices %v, got %v", expected, names)
+	}
+}
+
+func newTestClaim(name, uid string, devices ...string) *resourceapi.ResourceClaim {
+	claim := &resourceapi.ResourceClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(uid),
+		},
+	}
+
+	if len(devices) == 0 {
+		return claim
+	}
+
+	claim.Status.Allocation = &resourceapi.AllocationResult{}
+
+	for _, dev := range devices {
+		claim.Status.Allocation.Devices.Results = append(claim.Status.Allocation.Devices.Results,
+			resourceapi.DeviceRequestAllocationResult{
+				Request: "req",
+				Driver:  testDriverName,
+				Pool:    testNodeName,
+				Device:  dev,
+			})
+	}
+
+	return claim
+}
+
+func TestDRANodePrepareResources(t *testing.T) {
+	d, _ := newTestDRADriver(t,
+		newTestClaim("claim1", "uid1", "typea-dev1", "typea-dev2"),
+		newTestClaim("claim2", "uid2", "typea-dev3"),
+		newTestClaim("claim3", "uid3"),
+		newTestClaim("claim4", "uid4", "typea-missing"),
+	)
+
+	tree := NewDeviceTree()
+	tree.AddDevice("typea", "dev1", DeviceInfo{
+		state: pluginapi.Healthy,
+		nodes: []pluginapi.DeviceSpec{{HostPath: "/dev/dev1", ContainerPath: "/dev/dev1", Permissions: "rw"}},
+		envs:  map[string]string{"FOO": "bar"},
+		cdiSpec: &cdispec.Spec{
+			Version: CDIVersion,
+			Kind:    CDIVendor + "/foo",
+			Devices: []cdispec.Device{{
+				Name: "dev1",
+				ContainerEdits: cdispec.ContainerEdits{
+					Env: []string{"BAR=foo"},
+				},
+			}},
+		},
+	})
+	tree.AddDevice("typea", "dev2", DeviceInfo{
+		state:  pluginapi.Healthy,
+		mounts: []pluginapi.Mount{{HostPath: "/mnt", ContainerPath: "/mnt", ReadOnly: true}},
+	})
+	tree.AddDevice("typea", "dev3", DeviceInfo{state: pluginapi.Unhealthy})
+
+	d.Notify(tree)
+
+	resp, err := d.NodePrepareResources(context.Background(), &drapb.NodePrepareResourcesRequest{
+		Claims: []*drapb.Claim{
+			{Namespace: "default", Name: "claim1", Uid: "uid1"},
+			{Namespace: "default", Name: "claim2", Uid: "uid2"},
+			{Namespace: "default", Name: "claim3", Uid: "uid3"},
+			{Namespace: "default", Name: "claim4", Uid: "uid4"},
+			{Namespace: "default", Name: "claim1", Uid: "wrong"},
+			{Namespace: "default", Name: "claim5", Uid: "uid5"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claim1 := resp.Claims["uid1"]
+	if claim1.Error != "" || len(claim1.Devices) != 2 {
+		t.Fatalf("unexpected response for claim1: %+v", claim1)
+	}
+
+	expectedIDs := [][]string{
+		{CDIVendor + "/test=claim-uid1-typea-dev1", CDIVendor + "/foo=dev1"},
+		{CDIVendor + "/test=claim-uid1-typea-dev2"},
+	}
+	for i, expected := range expectedIDs {
+		if fmt.Sprint(claim1.Devices[i].CdiDeviceIds) != fmt.Sprint(expected) {
+			t.Errorf("expected CDI devices %v, got %v", expected, claim1.Devices[i].CdiDeviceIds)
+		}
+	}
+
+	claimSpec := filepath.Join(d.cdiSpecs.dir, "intel.cdi.k8s.io-test-claim-uid1-typea-dev1.yaml")
+	if _, err = os.Stat(claimSpec); err != nil {
+		t.Errorf("claim CDI spec not written: %v", err)
+	}
+
+	for _, uid := range []string{"uid2", "uid4", "wrong", "uid5"} {
+		if resp.Claims[uid].Error == "" {
+			t.Errorf("expected an error for claim %s", uid)
+		}
+	}
+
+	if resp.Claims["uid3"].Error == "" {
+		t.Errorf("expected an error for unallocated claim")
+	}
+
+	unprepResp, err := d.NodeUnprepareResources(context.Background(), &drapb.NodeUnprepareResourcesRequest{
+		Claims: []*drapb.Claim{{Namespace: "default", Name: "claim1", Uid: "uid1"}},
+	})
+	if err != nil || unprepResp.Claims["uid1"].Error != "" {
+		t.Fatalf("unprepare failed: %v, %+v", err, unprepResp)
+	}
+
+	if _, err = os.Stat(claimSpec); !os.IsNotExist(err) {
+		t.Errorf("claim CDI spec not removed: %v", err)
+	}
+}
+
+func TestDRARegistration(t *testing.T) {
+	d, _ := newTestDRADriver(t)
+
+	tmpDir, err := os.MkdirTemp("/tmp", "dra")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	d.pluginDir = filepath.Join(tmpDir, "plugins", testDriverName)
+	d.registryDir = tmpDir
+
+	if err = d.serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer d.stop()
+
+	conn, err := grpc.NewClient("unix://"+d.registrationSocket(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	info, err := registerapi.NewRegistrationClient(conn).GetInfo(context.Background(), &registerapi.InfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Type != registerapi.DRAPlugin || info.Name != testDriverName || info.Endpoint != d.draSocket() {
+		t.Errorf("unexpected plugin info: %+v", info)
+	}
+
+	if _, err = registerapi.NewRegistrationClient(conn).NotifyRegistrationStatus(context.Background(),
+		&registerapi.RegistrationStatus{PluginRegistered: true}); err != nil {
+		t.Error(err)
+	}
+
+	draConn, err := grpc.NewClient("unix://"+info.Endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer draConn.Close()
+
+	resp, err := drapb.NewDRAPluginClient(draConn).NodeUnprepareResources(context.Background(), &drapb.NodeUnprepareResourcesRequest{})
+	if err != nil || len(resp.Claims) != 0 {
+		t.Errorf("unexpected response: %+v, %v", resp, err)
+	}
+}
+
+func TestDRARunCancel(t *testing.T) {
+	d, _ := newTestDRADriver(t)
+	d.scanner = &blockingScannerStub{}
+
+	tmpDir, err := os.MkdirTemp("/tmp", "dra")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	d.pluginDir = filepath.Join(tmpDir, "plugins", testDriverName)
+	d.registryDir = tmpDir
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	if err = d.Run(ctx); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	for _, socket := range []string{d.draSocket(), d.registrationSocket()} {
+		if _, err = os.Stat(socket); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", socket, err)
+		}
+	}
+}
+
+func TestDRANodePrepareMultiRequestClaim(t *testing.T) {
+	claim := newTestClaim("claim1", "uid1", "typea-dev1", "typea-dev2", "typea-dev3")
+	claim.Status.Allocation.Devices.Results[2].Request = "other"
+
+	d, _ := newTestDRADriver(t, claim)
+
+	// The envs are combined over the devices of a request.
+	d.postAllocate = func(resp *pluginapi.AllocateResponse) error {
+		cresp := resp.ContainerResponses[0]
+		cresp.Envs = map[string]string{"DEVICES": fmt.Sprint(len(cresp.Devices))}
+
+		return nil
+	}
+
+	tree := NewDeviceTree()
+	for _, id := range []string{"dev1", "dev2", "dev3"} {
+		tree.AddDevice("typea", id, DeviceInfo{
+			state: pluginapi.Healthy,
+			nodes: []pluginapi.DeviceSpec{{HostPath: "/dev/" + id, ContainerPath: "/dev/" + id, Permissions: "rw"}},
+		})
+	}
+
+	d.Notify(tree)
+
+	resp, err := d.NodePrepareResources(context.Background(), &drapb.NodePrepareResourcesRequest{
+		Claims: []*drapb.Claim{{Namespace: "default", Name: "claim1", Uid: "uid1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prepared := resp.Claims["uid1"]
+	if prepared.Error != "" || len(prepared.Devices) != 3 {
+		t.Fatalf("unexpected response: %+v", prepared)
+	}
+
+	expected := []struct {
+		request string
+		env     string
+	}{
+		{request: "req", env: "DEVICES=2"},
+		{request: "req", env: "DEVICES=2"},
+		{request: "other", env: "DEVICES=1"},
+	}
+
+	// All the claim's CDI devices are in one spec named after the first one.
+	spec, err := cdi.ReadSpec(filepath.Join(d.cdiSpecs.dir, "intel.cdi.k8s.io-test-claim-uid1-typea-dev1.yaml"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(spec.Devices) != len(prepared.Devices) {
+		t.Fatalf("expected %d CDI devices, got %d", len(prepared.Devices), len(spec.Devices))
+	}
+
+	for i, dev := range prepared.Devices {
+		name := claimCdiDeviceName("uid1", fmt.Sprintf("typea-dev%d", i+1))
+
+		if fmt.Sprint(dev.RequestNames) != fmt.Sprint([]string{expected[i].request}) {
+			t.Errorf("%s: expected request %s, got %v", dev.DeviceName, expected[i].request, dev.RequestNames)
+		}
+
+		if fmt.Sprint(dev.CdiDeviceIds) != fmt.Sprint([]string{CDIVendor + "/test=" + name}) {
+			t.Errorf("%s: unexpected CDI devices %v", dev.DeviceName, dev.CdiDeviceIds)
+		}
+
+		if spec.Devices[i].Name != name {
+			t.Errorf("%s: expected CDI device %s, got %s", dev.DeviceName, name, spec.Devices[i].Name)
+		}
+
+		edits := spec.Devices[i].ContainerEdits
+		if len(edits.DeviceNodes) != 1 || edits.DeviceNodes[0].HostPath != "/dev/"+strings.TrimPrefix(dev.DeviceName, "typea-") {
+			t.Errorf("%s: unexpected device nodes %+v", dev.DeviceName, edits.DeviceNodes)
+		}
+
+		if fmt.Sprint(edits.Env) != fmt.Sprint([]string{expected[i].env}) {
+			t.Errorf("%s: expected env %s, got %v", dev.DeviceName, expected[i].env, edits.Env)
+		}
+	}
+}
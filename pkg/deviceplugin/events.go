@@ -0,0 +1,334 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// Reasons of the node events and conditions.
+const (
+	EventReasonDeviceUnhealthy  = "DeviceUnhealthy"
+	EventReasonDeviceHealthy    = "DeviceHealthy"
+	EventReasonAllocationFailed = "AllocationFailed"
+	EventReasonReregistered     = "Reregistered"
+
+	ConditionReasonUnhealthyDevices = "UnhealthyDevices"
+	ConditionReasonDevicesHealthy   = "DevicesHealthy"
+	ConditionReasonNoDevices        = "NoDevices"
+
+	// Minimum interval between node condition updates.
+	nodeConditionInterval = 10 * time.Second
+	nodeStatusTimeout     = 30 * time.Second
+	// Maximum number of device IDs listed in a condition message.
+	maxConditionDevices = 10
+)
+
+var nodeEventsEnabled = flag.Bool("node-events", false,
+	"publish Kubernetes Events and per-resource conditions on the node for device health transitions, allocation failures and kubelet re-registrations. NODE_NAME environment variable must be set")
+
+// nodeEvents is a Notifier passing the device trees to the next Notifier
+// as they are. It records the health transitions of the devices as Events
+// on the node and keeps a condition per resource in the node status telling
+// if the resource has unhealthy devices. The conditions are updated at most
+// once per interval. A nil *nodeEvents records nothing.
+type nodeEvents struct {
+	next     Notifier
+	client   kubernetes.Interface
+	recorder record.EventRecorder
+	node     *v1.ObjectReference
+	timer    *time.Timer
+	// Device type -> device ID -> health.
+	health map[string]map[string]string
+	// Conditions of the resources, wanted and in the node status.
+	wanted    map[v1.NodeConditionType]v1.NodeCondition
+	published map[v1.NodeConditionType]v1.NodeCondition
+	updated   time.Time
+	namespace string
+	interval  time.Duration
+	mutex     sync.Mutex
+}
+
+// newNodeEvents returns nodeEvents for the resources in namespace on node
+// nodeName. The Events are sent until ctx is canceled.
+func newNodeEvents(ctx context.Context, namespace, nodeName string, client kubernetes.Interface) *nodeEvents {
+	broadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+
+	context.AfterFunc(ctx, broadcaster.Shutdown)
+
+	return &nodeEvents{
+		client: client,
+		recorder: broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{
+			Component: strings.SplitN(namespace, ".", 2)[0] + "-device-plugin",
+			Host:      nodeName,
+		}),
+		// Like kubelet, use the node name as its UID so that the events
+		// are shown by kubectl describe node.
+		node: &v1.ObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+			UID:  types.UID(nodeName),
+		},
+		health:    make(map[string]map[string]string),
+		wanted:    make(map[v1.NodeConditionType]v1.NodeCondition),
+		published: make(map[v1.NodeConditionType]v1.NodeCondition),
+		namespace: namespace,
+		interval:  nodeConditionInterval,
+	}
+}
+
+// notifier returns the Notifier passing the device trees to next.
+func (e *nodeEvents) notifier(next Notifier) Notifier {
+	if e == nil {
+		return next
+	}
+
+	e.next = next
+
+	return e
+}
+
+// Notify implements the Notifier interface.
+func (e *nodeEvents) Notify(tree DeviceTree) {
+	e.update(tree)
+	e.next.Notify(tree)
+}
+
+func (e *nodeEvents) update(tree DeviceTree) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	health := make(map[string]map[string]string)
+
+	for _, devType := range slices.Sorted(maps.Keys(tree)) {
+		health[devType] = make(map[string]string)
+
+		for _, id := range slices.Sorted(maps.Keys(tree[devType])) {
+			info := tree[devType][id]
+			health[devType][id] = info.state
+
+			old, found := e.health[devType][id]
+			if (found && old != info.state) || (!found && info.state != pluginapi.Healthy) {
+				e.healthChanged(devType, id, info)
+			}
+		}
+
+		condition := resourceCondition(e.namespace+"/"+devType, tree[devType])
+		e.wanted[condition.Type] = condition
+	}
+
+	for devType := range e.health {
+		if _, found := tree[devType]; !found {
+			condition := resourceCondition(e.namespace+"/"+devType, nil)
+			e.wanted[condition.Type] = condition
+		}
+	}
+
+	e.health = health
+
+	e.scheduleConditions()
+}
+
+func (e *nodeEvents) healthChanged(devType, id string, info DeviceInfo) {
+	if info.state == pluginapi.Healthy {
+		e.recorder.Eventf(e.node, v1.EventTypeNormal, EventReasonDeviceHealthy,
+			"Device %s/%s is healthy again", devType, id)
+
+		return
+	}
+
+	message := fmt.Sprintf("Device %s/%s is %s", devType, id, info.state)
+	if info.healthReason != "" {
+		message += ": " + info.healthReason
+	}
+
+	e.recorder.Event(e.node, v1.EventTypeWarning, EventReasonDeviceUnhealthy, message)
+}
+
+// allocationFailed records a failed allocation of a resource.
+func (e *nodeEvents) allocationFailed(devType string, err error) {
+	if e == nil {
+		return
+	}
+
+	e.recorder.Eventf(e.node, v1.EventTypeWarning, EventReasonAllocationFailed,
+		"Allocation of %s/%s failed: %v", e.namespace, devType, err)
+}
+
+// registered records the registrations of a resource after the first one,
+// which happen when kubelet restarts.
+func (e *nodeEvents) registered(devType string, registrations int) {
+	if e == nil || registrations < 2 {
+		return
+	}
+
+	e.recorder.Eventf(e.node, v1.EventTypeNormal, EventReasonReregistered,
+		"Device plugin for %s/%s registered again with kubelet (%d registrations)", e.namespace, devType, registrations)
+}
+
+// resourceCondition returns the node condition of a resource with devices.
+func resourceCondition(resourceName string, devices map[string]DeviceInfo) v1.NodeCondition {
+	condition := v1.NodeCondition{
+		Type:   v1.NodeConditionType(resourceName + "-unhealthy"),
+		Status: v1.ConditionFalse,
+	}
+
+	if len(devices) == 0 {
+		condition.Reason = ConditionReasonNoDevices
+		condition.Message = "No devices found"
+
+		return condition
+	}
+
+	unhealthy := []string{}
+
+	for _, id := range slices.Sorted(maps.Keys(devices)) {
+		info := devices[id]
+		if info.state == pluginapi.Healthy {
+			continue
+		}
+
+		if info.healthReason != "" {
+			id += " (" + info.healthReason + ")"
+		}
+
+		unhealthy = append(unhealthy, id)
+	}
+
+	if len(unhealthy) == 0 {
+		condition.Reason = ConditionReasonDevicesHealthy
+		condition.Message = fmt.Sprintf("All %d devices are healthy", len(devices))
+
+		return condition
+	}
+
+	condition.Status = v1.ConditionTrue
+	condition.Reason = ConditionReasonUnhealthyDevices
+	condition.Message = fmt.Sprintf("%d of %d devices are unhealthy: ", len(unhealthy), len(devices))
+
+	if len(unhealthy) > maxConditionDevices {
+		unhealthy = append(unhealthy[:maxConditionDevices], "...")
+	}
+
+	condition.Message += strings.Join(unhealthy, ", ")
+
+	return condition
+}
+
+// changedConditions returns the wanted conditions not in the node status.
+func (e *nodeEvents) changedConditions() []v1.NodeCondition {
+	changed := []v1.NodeCondition{}
+
+	for _, conditionType := range slices.Sorted(maps.Keys(e.wanted)) {
+		wanted := e.wanted[conditionType]
+
+		published, found := e.published[conditionType]
+		if found && published.Status == wanted.Status && published.Reason == wanted.Reason && published.Message == wanted.Message {
+			continue
+		}
+
+		changed = append(changed, wanted)
+	}
+
+	return changed
+}
+
+// scheduleConditions schedules an update of the changed conditions when
+// there is none pending. It must be called with the mutex held.
+func (e *nodeEvents) scheduleConditions() {
+	if e.timer != nil || len(e.changedConditions()) == 0 {
+		return
+	}
+
+	e.timer = time.AfterFunc(max(e.interval-time.Since(e.updated), 0), e.updateConditions)
+}
+
+// updateConditions patches the changed conditions to the node status.
+func (e *nodeEvents) updateConditions() {
+	now := metav1.Now()
+
+	e.mutex.Lock()
+	changed := e.changedConditions()
+
+	for i := range changed {
+		changed[i].LastHeartbeatTime = now
+		changed[i].LastTransitionTime = now
+
+		if published, found := e.published[changed[i].Type]; found && published.Status == changed[i].Status {
+			changed[i].LastTransitionTime = published.LastTransitionTime
+		}
+	}
+	e.mutex.Unlock()
+
+	err := e.patchConditions(changed)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.timer = nil
+	e.updated = time.Now()
+
+	if err != nil {
+		klog.Warningf("Failed to update node conditions: %+v", err)
+	} else {
+		for _, condition := range changed {
+			e.published[condition.Type] = condition
+		}
+	}
+
+	e.scheduleConditions()
+}
+
+func (e *nodeEvents) patchConditions(conditions []v1.NodeCondition) error {
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"conditions": conditions,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal node conditions")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nodeStatusTimeout)
+	defer cancel()
+
+	_, err = e.client.CoreV1().Nodes().PatchStatus(ctx, e.node.Name, patch)
+
+	return errors.Wrapf(err, "failed to patch the status of node %s", e.node.Name)
+}
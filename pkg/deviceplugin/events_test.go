@@ -0,0 +1,280 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const testConditionType = "intel.com/gpu-unhealthy"
+
+func newTestNodeEvents(t *testing.T) (*nodeEvents, *fake.Clientset) {
+	t.Helper()
+
+	client := fake.NewClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	e := newNodeEvents(ctx, "intel.com", "node1", client)
+	e.next = &treeRecorder{}
+
+	return e, client
+}
+
+// waitForEventReasons returns the reasons of the node events in order once
+// there are count of them.
+func waitForEventReasons(t *testing.T, client *fake.Clientset, count int) []string {
+	t.Helper()
+
+	for range 100 {
+		events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(events.Items) >= count {
+			slices.SortFunc(events.Items, func(a, b v1.Event) int {
+				return a.FirstTimestamp.Compare(b.FirstTimestamp.Time)
+			})
+
+			reasons := []string{}
+
+			for _, event := range events.Items {
+				if event.InvolvedObject.Kind != "Node" || event.InvolvedObject.Name != "node1" {
+					t.Errorf("event for unexpected object %+v", event.InvolvedObject)
+				}
+
+				reasons = append(reasons, event.Reason)
+			}
+
+			return reasons
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("less than %d node events", count)
+
+	return nil
+}
+
+// waitForCondition returns the test resource condition of the node once it
+// has the given reason.
+func waitForCondition(t *testing.T, client *fake.Clientset, reason string) v1.NodeCondition {
+	t.Helper()
+
+	for range 100 {
+		node, err := client.CoreV1().Nodes().Get(context.Background(), "node1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == testConditionType && condition.Reason == reason {
+				return condition
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("no node condition %s with reason %s", testConditionType, reason)
+
+	return v1.NodeCondition{}
+}
+
+func countPatches(client *fake.Clientset) int {
+	patches := 0
+
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" && action.GetResource().Resource == "nodes" && action.GetSubresource() == "status" {
+			patches++
+		}
+	}
+
+	return patches
+}
+
+func TestNodeEvents(t *testing.T) {
+	e, client := newTestNodeEvents(t)
+	next := e.next.(*treeRecorder)
+
+	e.Notify(healthTree(pluginapi.Healthy))
+	e.Notify(healthTree(pluginapi.Unhealthy))
+	e.Notify(healthTree(pluginapi.Unhealthy))
+	waitForEventReasons(t, client, 1)
+
+	e.Notify(healthTree(pluginapi.Healthy))
+	waitForEventReasons(t, client, 2)
+
+	e.allocationFailed("gpu", errors.New("Invalid allocation request with unhealthy device card0"))
+	waitForEventReasons(t, client, 3)
+
+	e.registered("gpu", 1)
+	e.registered("gpu", 2)
+
+	reasons := waitForEventReasons(t, client, 4)
+	expected := []string{EventReasonDeviceUnhealthy, EventReasonDeviceHealthy, EventReasonAllocationFailed, EventReasonReregistered}
+
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("expected events %v, got %v", expected, reasons)
+	}
+
+	if len(next.trees) != 4 {
+		t.Errorf("expected 4 trees passed on, got %d", len(next.trees))
+	}
+
+	var disabled *nodeEvents
+
+	if n := disabled.notifier(next); n != next {
+		t.Error("expected the next notifier without node events")
+	}
+
+	disabled.allocationFailed("gpu", errors.New("failed"))
+	disabled.registered("gpu", 2)
+}
+
+func TestNodeConditions(t *testing.T) {
+	e, client := newTestNodeEvents(t)
+
+	e.Notify(healthTree(pluginapi.Unhealthy))
+
+	unhealthy := waitForCondition(t, client, ConditionReasonUnhealthyDevices)
+	if unhealthy.Status != v1.ConditionTrue || unhealthy.Message != "1 of 1 devices are unhealthy: card0 (TemperatureCritical)" {
+		t.Errorf("unexpected condition %+v", unhealthy)
+	}
+
+	if patches := countPatches(client); patches != 1 {
+		t.Errorf("expected one node status patch, got %d", patches)
+	}
+
+	// Changes within the interval are published together after it.
+	e.mutex.Lock()
+	e.interval = time.Hour
+	e.mutex.Unlock()
+
+	e.Notify(healthTree(pluginapi.Healthy))
+	e.Notify(healthTree(pluginapi.Unhealthy))
+	e.Notify(healthTree(pluginapi.Healthy))
+	time.Sleep(100 * time.Millisecond)
+
+	if patches := countPatches(client); patches != 1 {
+		t.Errorf("expected no node status patches within the interval, got %d", patches-1)
+	}
+
+	e.mutex.Lock()
+	e.timer.Stop()
+	e.timer = nil
+	e.interval = 0
+	e.scheduleConditions()
+	e.mutex.Unlock()
+
+	healthy := waitForCondition(t, client, ConditionReasonDevicesHealthy)
+	if healthy.Status != v1.ConditionFalse || healthy.LastTransitionTime.Before(&unhealthy.LastTransitionTime) {
+		t.Errorf("unexpected condition %+v", healthy)
+	}
+
+	if patches := countPatches(client); patches != 2 {
+		t.Errorf("expected two node status patches, got %d", patches)
+	}
+
+	// Removed resources have no devices. The status does not change.
+	e.Notify(NewDeviceTree())
+
+	condition := waitForCondition(t, client, ConditionReasonNoDevices)
+	if condition.Status != v1.ConditionFalse || !condition.LastTransitionTime.Equal(&healthy.LastTransitionTime) {
+		t.Errorf("expected the transition time to stay at %v, got %+v", healthy.LastTransitionTime, condition)
+	}
+
+	if patches := countPatches(client); patches != 3 {
+		t.Errorf("expected three node status patches, got %d", patches)
+	}
+}
+
+func TestResourceCondition(t *testing.T) {
+	unhealthy := DeviceInfo{state: pluginapi.Unhealthy}
+	unhealthy.SetHealthReason("Quarantined")
+
+	many := map[string]DeviceInfo{}
+	for i := range maxConditionDevices + 2 {
+		many["dev"+strconv.Itoa(i+10)] = DeviceInfo{state: pluginapi.Unhealthy}
+	}
+
+	tcases := []struct {
+		devices map[string]DeviceInfo
+		name    string
+		status  v1.ConditionStatus
+		reason  string
+		message string
+	}{
+		{
+			name:    "no devices",
+			status:  v1.ConditionFalse,
+			reason:  ConditionReasonNoDevices,
+			message: "No devices found",
+		},
+		{
+			name: "healthy devices",
+			devices: map[string]DeviceInfo{
+				"dev1": {state: pluginapi.Healthy},
+				"dev2": {state: pluginapi.Healthy},
+			},
+			status:  v1.ConditionFalse,
+			reason:  ConditionReasonDevicesHealthy,
+			message: "All 2 devices are healthy",
+		},
+		{
+			name: "unhealthy devices",
+			devices: map[string]DeviceInfo{
+				"dev1": {state: pluginapi.Healthy},
+				"dev2": unhealthy,
+				"dev3": {state: pluginapi.Unhealthy},
+			},
+			status:  v1.ConditionTrue,
+			reason:  ConditionReasonUnhealthyDevices,
+			message: "2 of 3 devices are unhealthy: dev2 (Quarantined), dev3",
+		},
+		{
+			name:    "too many unhealthy devices",
+			devices: many,
+			status:  v1.ConditionTrue,
+			reason:  ConditionReasonUnhealthyDevices,
+			message: "12 of 12 devices are unhealthy: dev10, dev11, dev12, dev13, dev14, dev15, dev16, dev17, dev18, dev19, ...",
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			condition := resourceCondition("intel.com/gpu", tc.devices)
+
+			if condition.Type != testConditionType || condition.Status != tc.status ||
+				condition.Reason != tc.reason || condition.Message != tc.message {
+				t.Errorf("unexpected condition %+v", condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const deviceHealthPath = "/debug/device-health"
+
+var (
+	healthFailureThreshold = flag.Int("health-failure-threshold", 1,
+		"number of consecutive unhealthy scan results before a healthy device is reported unhealthy to kubelet")
+	healthRecoveryThreshold = flag.Int("health-recovery-threshold", 1,
+		"number of consecutive healthy scan results before an unhealthy device is reported healthy to kubelet")
+)
+
+// deviceHealth is the health of a device as reported to kubelet.
+type deviceHealth struct {
+	// Since is the time of the latest health transition.
+	Since  time.Time `json:"since"`
+	Health string    `json:"health"`
+	// Reason tells why the device is unhealthy.
+	Reason string `json:"reason,omitempty"`
+	// Pending is the number of consecutive scan results disagreeing with Health.
+	Pending int `json:"pending,omitempty"`
+}
+
+// healthFilter is a Notifier passing the device trees reported by a Scanner
+// to the next Notifier with debounced device health. A device becomes
+// unhealthy after failures consecutive unhealthy scan results and healthy
+// again after recoveries consecutive healthy ones.
+type healthFilter struct {
+	next Notifier
+	// Device type -> device ID -> health.
+	devices    map[string]map[string]*deviceHealth
+	failures   int
+	recoveries int
+	mutex      sync.Mutex
+}
+
+func newHealthFilter(failures, recoveries int) *healthFilter {
+	return &healthFilter{
+		devices:    make(map[string]map[string]*deviceHealth),
+		failures:   max(failures, 1),
+		recoveries: max(recoveries, 1),
+	}
+}
+
+// Notify implements the Notifier interface.
+func (f *healthFilter) Notify(tree DeviceTree) {
+	f.next.Notify(f.filter(tree, time.Now()))
+}
+
+// filter returns a copy of tree with the debounced health of the devices.
+func (f *healthFilter) filter(tree DeviceTree, now time.Time) DeviceTree {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	filtered := NewDeviceTree()
+	devices := make(map[string]map[string]*deviceHealth)
+
+	for devType, infos := range tree {
+		devices[devType] = make(map[string]*deviceHealth)
+
+		for id, info := range infos {
+			health := f.update(devType, id, info, now)
+			devices[devType][id] = health
+
+			info.state = health.Health
+			info.healthReason = health.Reason
+			info.healthSince = health.Since
+
+			filtered.AddDevice(devType, id, info)
+		}
+	}
+
+	for devType, healths := range f.devices {
+		for id := range healths {
+			if _, found := devices[devType][id]; !found {
+				forgetDeviceHealth(devType, id)
+			}
+		}
+	}
+
+	f.devices = devices
+
+	return filtered
+}
+
+// update updates the health of a device with a new scan result.
+func (f *healthFilter) update(devType, id string, info DeviceInfo, now time.Time) *deviceHealth {
+	health, found := f.devices[devType][id]
+	if !found {
+		health = &deviceHealth{Health: info.state, Reason: info.healthReason, Since: now}
+		observeDeviceHealth(devType, id, health)
+
+		return health
+	}
+
+	if info.state == health.Health {
+		health.Pending = 0
+
+		return health
+	}
+
+	health.Pending++
+
+	threshold := f.recoveries
+	if info.state != pluginapi.Healthy {
+		threshold = f.failures
+	}
+
+	if health.Pending < threshold {
+		klog.V(4).Infof("Device %s/%s reported %s (%d/%d)", devType, id, info.state, health.Pending, threshold)
+
+		return health
+	}
+
+	*health = deviceHealth{Health: info.state, Reason: info.healthReason, Since: now}
+
+	if health.Health == pluginapi.Healthy {
+		klog.Infof("Device %s/%s is healthy again", devType, id)
+	} else {
+		klog.Warningf("Device %s/%s is %s, reason: %q", devType, id, health.Health, health.Reason)
+	}
+
+	healthTransitionsTotal.WithLabelValues(devType, health.Health).Inc()
+	observeDeviceHealth(devType, id, health)
+
+	return health
+}
+
+// ServeHTTP serves the health of all devices as JSON.
+func (f *healthFilter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	data, err := json.MarshalIndent(f.devices, "", "  ")
+	f.mutex.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err = w.Write(data); err != nil {
+		klog.V(4).Infof("Failed to write device health: %v", err)
+	}
+}
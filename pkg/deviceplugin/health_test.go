@@ -0,0 +1,160 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+type treeRecorder struct {
+	trees []DeviceTree
+}
+
+func (r *treeRecorder) Notify(tree DeviceTree) {
+	r.trees = append(r.trees, tree)
+}
+
+func healthTree(state string) DeviceTree {
+	info := DeviceInfo{state: state}
+	if state != pluginapi.Healthy {
+		info.SetHealthReason("TemperatureCritical")
+	}
+
+	tree := NewDeviceTree()
+	tree.AddDevice("gpu", "card0", info)
+
+	return tree
+}
+
+func TestHealthFilter(t *testing.T) {
+	h, u := pluginapi.Healthy, pluginapi.Unhealthy
+
+	tcases := []struct {
+		name       string
+		reported   []string
+		expected   []string
+		failures   int
+		recoveries int
+	}{
+		{
+			name:     "no debouncing",
+			reported: []string{h, u, h, u},
+			expected: []string{h, u, h, u},
+		},
+		{
+			name:       "single failures are ignored",
+			reported:   []string{h, u, h, u, u, h, u, u, u, h},
+			expected:   []string{h, h, h, h, h, h, h, h, u, u},
+			failures:   3,
+			recoveries: 2,
+		},
+		{
+			name:       "recovery needs consecutive successes",
+			reported:   []string{u, h, u, h, h, u},
+			expected:   []string{u, u, u, u, h, h},
+			failures:   2,
+			recoveries: 2,
+		},
+		{
+			name:     "initial state is taken as is",
+			reported: []string{u, h},
+			expected: []string{u, h},
+			failures: 5,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &treeRecorder{}
+			filter := newHealthFilter(tc.failures, tc.recoveries)
+			filter.next = recorder
+
+			for _, state := range tc.reported {
+				filter.Notify(healthTree(state))
+			}
+
+			states := []string{}
+			for _, tree := range recorder.trees {
+				states = append(states, tree["gpu"]["card0"].state)
+			}
+
+			if !reflect.DeepEqual(states, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, states)
+			}
+		})
+	}
+}
+
+func TestHealthFilterTransitions(t *testing.T) {
+	h, u := pluginapi.Healthy, pluginapi.Unhealthy
+	filter := newHealthFilter(2, 1)
+	start := time.Now()
+
+	tree := filter.filter(healthTree(h), start)
+	if dev := tree["gpu"]["card0"]; dev.healthSince != start || dev.healthReason != "" {
+		t.Errorf("unexpected initial health: %+v", dev)
+	}
+
+	tree = filter.filter(healthTree(u), start.Add(time.Second))
+	if dev := tree["gpu"]["card0"]; dev.state != h || dev.healthSince != start {
+		t.Errorf("health changed before the threshold: %+v", dev)
+	}
+
+	failed := start.Add(2 * time.Second)
+
+	tree = filter.filter(healthTree(u), failed)
+	if dev := tree["gpu"]["card0"]; dev.state != u || dev.healthSince != failed || dev.healthReason != "TemperatureCritical" {
+		t.Errorf("unexpected health after the threshold: %+v", dev)
+	}
+
+	// Unchanged health keeps the trees equal so that kubelet isn't updated in vain.
+	if next := filter.filter(healthTree(u), failed.Add(time.Second)); !reflect.DeepEqual(next, tree) {
+		t.Errorf("unchanged health changed the tree: %+v", next)
+	}
+
+	filter.filter(NewDeviceTree(), failed.Add(2*time.Second))
+
+	if len(filter.devices["gpu"]) != 0 {
+		t.Errorf("removed device not forgotten: %+v", filter.devices)
+	}
+}
+
+func TestHealthFilterHandler(t *testing.T) {
+	filter := newHealthFilter(1, 1)
+	filter.filter(healthTree(pluginapi.Unhealthy), time.Now())
+
+	rec := httptest.NewRecorder()
+	filter.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, deviceHealthPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var all map[string]map[string]deviceHealth
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil {
+		t.Fatal(err)
+	}
+
+	if dev := all["gpu"]["card0"]; dev.Health != pluginapi.Unhealthy || dev.Reason != "TemperatureCritical" {
+		t.Errorf("unexpected device health: %+v", all)
+	}
+}
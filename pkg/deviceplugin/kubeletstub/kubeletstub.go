@@ -0,0 +1,426 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubeletstub provides an in-process fake kubelet for testing device
+// plugins without a cluster. It serves the device plugin Registration service,
+// connects back to the registered plugins, records the devices they list and
+// calls their Allocate, GetPreferredAllocation and PreStartContainer methods.
+//
+// A plugin is pointed to the fake kubelet with Manager.SetDevicePluginPath:
+//
+//	kubelet := kubeletstub.New(dir)
+//	if err := kubelet.Start(); err != nil {
+//		t.Fatal(err)
+//	}
+//	defer kubelet.Stop()
+//
+//	manager := dpapi.NewManager(namespace, plugin)
+//	manager.SetDevicePluginPath(dir)
+//	manager.SetCDIDir(filepath.Join(dir, "cdi"))
+//	go manager.Run(ctx)
+//
+//	devices, err := kubelet.WaitForDevices(ctx, resourceName, kubeletstub.HealthyDevices(2))
+package kubeletstub
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+var errNotRegistered = errors.New("resource not registered")
+
+// plugin is a registered device plugin.
+type plugin struct {
+	client  pluginapi.DevicePluginClient
+	conn    *grpc.ClientConn
+	cancel  context.CancelFunc
+	options *pluginapi.DevicePluginOptions
+	devices []*pluginapi.Device
+}
+
+// Kubelet is a fake kubelet serving the Registration service at
+// kubelet.sock in its directory.
+type Kubelet struct {
+	pluginapi.UnsafeRegistrationServer
+	server *grpc.Server
+	// Resource name -> plugin.
+	plugins map[string]*plugin
+	// Resource name -> number of registrations.
+	registrations map[string]int
+	// changed is closed and replaced when plugins, their devices or
+	// registrations change.
+	changed chan struct{}
+	dir     string
+	mutex   sync.Mutex
+}
+
+// New returns a Kubelet serving at dir, which must exist.
+func New(dir string) *Kubelet {
+	return &Kubelet{
+		dir:           dir,
+		plugins:       make(map[string]*plugin),
+		registrations: make(map[string]int),
+		changed:       make(chan struct{}),
+	}
+}
+
+// Dir returns the directory of the kubelet and device plugin sockets.
+func (k *Kubelet) Dir() string {
+	return k.dir
+}
+
+// Socket returns the path of the kubelet socket.
+func (k *Kubelet) Socket() string {
+	return filepath.Join(k.dir, filepath.Base(pluginapi.KubeletSocket))
+}
+
+// Start starts serving the Registration service. Like kubelet, Start
+// removes the sockets of the earlier registered plugins, which makes the
+// plugins register again.
+func (k *Kubelet) Start() error {
+	var lc net.ListenConfig
+
+	lis, err := lc.Listen(context.Background(), "unix", k.Socket())
+	if err != nil {
+		return errors.Wrap(err, "failed to listen to kubelet socket")
+	}
+
+	server := grpc.NewServer()
+	pluginapi.RegisterRegistrationServer(server, k)
+
+	k.mutex.Lock()
+	k.server = server
+	k.mutex.Unlock()
+
+	go func() {
+		if serveErr := server.Serve(lis); serveErr != nil {
+			klog.Errorf("Fake kubelet failed: %v", serveErr)
+		}
+	}()
+
+	return k.removePluginSockets()
+}
+
+// Stop stops serving, disconnects from the plugins and removes the
+// kubelet socket.
+func (k *Kubelet) Stop() {
+	k.mutex.Lock()
+	server := k.server
+	k.server = nil
+
+	for resourceName, p := range k.plugins {
+		p.close()
+		delete(k.plugins, resourceName)
+	}
+
+	k.notifyLocked()
+	k.mutex.Unlock()
+
+	if server != nil {
+		server.Stop()
+	}
+
+	if err := os.Remove(k.Socket()); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Failed to remove %s: %v", k.Socket(), err)
+	}
+}
+
+// Restart simulates a kubelet restart: the registered plugins are
+// forgotten and their sockets removed.
+func (k *Kubelet) Restart() error {
+	k.Stop()
+
+	return k.Start()
+}
+
+func (k *Kubelet) removePluginSockets() error {
+	entries, err := os.ReadDir(k.dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read device plugin directory")
+	}
+
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSocket == 0 || entry.Name() == filepath.Base(k.Socket()) {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(k.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// Register implements the Registration service. It connects to the plugin
+// and starts watching its devices.
+func (k *Kubelet) Register(ctx context.Context, r *pluginapi.RegisterRequest) (*pluginapi.Empty, error) {
+	if r.Version != pluginapi.Version {
+		return nil, errors.Errorf("unsupported API version %s", r.Version)
+	}
+
+	conn, err := grpc.NewClient("unix://"+filepath.Join(k.dir, r.Endpoint),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to device plugin")
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	p := &plugin{
+		client:  pluginapi.NewDevicePluginClient(conn),
+		conn:    conn,
+		cancel:  cancel,
+		options: r.Options,
+	}
+
+	stream, err := p.client.ListAndWatch(watchCtx, &pluginapi.Empty{})
+	if err != nil {
+		p.close()
+
+		return nil, errors.Wrap(err, "failed to watch devices")
+	}
+
+	k.mutex.Lock()
+	if old, found := k.plugins[r.ResourceName]; found {
+		old.close()
+	}
+
+	k.plugins[r.ResourceName] = p
+	k.registrations[r.ResourceName]++
+	k.notifyLocked()
+	k.mutex.Unlock()
+
+	klog.V(2).Infof("Fake kubelet: %s registered at %s", r.ResourceName, r.Endpoint)
+
+	go k.watch(r.ResourceName, p, stream)
+
+	return &pluginapi.Empty{}, nil
+}
+
+// watch records the device lists sent by a plugin until the stream ends.
+func (k *Kubelet) watch(resourceName string, p *plugin, stream pluginapi.DevicePlugin_ListAndWatchClient) {
+	for {
+		resp, err := stream.Recv()
+
+		k.mutex.Lock()
+
+		if k.plugins[resourceName] != p {
+			k.mutex.Unlock()
+
+			return
+		}
+
+		if err != nil {
+			klog.V(2).Infof("Fake kubelet: %s disconnected: %v", resourceName, err)
+			p.close()
+			delete(k.plugins, resourceName)
+			k.notifyLocked()
+			k.mutex.Unlock()
+
+			return
+		}
+
+		p.devices = resp.Devices
+		k.notifyLocked()
+		k.mutex.Unlock()
+	}
+}
+
+func (p *plugin) close() {
+	p.cancel()
+
+	if err := p.conn.Close(); err != nil {
+		klog.V(4).Infof("Fake kubelet: closing connection failed: %v", err)
+	}
+}
+
+func (k *Kubelet) notifyLocked() {
+	close(k.changed)
+	k.changed = make(chan struct{})
+}
+
+// Devices returns the latest devices listed by the plugin of a resource and
+// whether the plugin is registered.
+func (k *Kubelet) Devices(resourceName string) ([]*pluginapi.Device, bool) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	p, found := k.plugins[resourceName]
+	if !found {
+		return nil, false
+	}
+
+	return p.devices, true
+}
+
+// Options returns the options the plugin of a resource registered with.
+func (k *Kubelet) Options(resourceName string) (*pluginapi.DevicePluginOptions, bool) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	p, found := k.plugins[resourceName]
+	if !found {
+		return nil, false
+	}
+
+	return p.options, true
+}
+
+// Registrations returns how many times a resource has been registered.
+func (k *Kubelet) Registrations(resourceName string) int {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	return k.registrations[resourceName]
+}
+
+// WaitForDevices waits until the plugin of a resource is registered and
+// its devices satisfy cond, and returns the devices.
+func (k *Kubelet) WaitForDevices(ctx context.Context, resourceName string, cond func([]*pluginapi.Device) bool) ([]*pluginapi.Device, error) {
+	for {
+		k.mutex.Lock()
+		p, found := k.plugins[resourceName]
+		changed := k.changed
+
+		var devices []*pluginapi.Device
+		if found {
+			devices = p.devices
+		}
+		k.mutex.Unlock()
+
+		if found && cond(devices) {
+			return devices, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return devices, errors.Wrapf(ctx.Err(), "waiting for %s devices", resourceName)
+		case <-changed:
+		}
+	}
+}
+
+// WaitForRegistrations waits until a resource has been registered at least
+// count times.
+func (k *Kubelet) WaitForRegistrations(ctx context.Context, resourceName string, count int) error {
+	for {
+		k.mutex.Lock()
+		registrations := k.registrations[resourceName]
+		_, found := k.plugins[resourceName]
+		changed := k.changed
+		k.mutex.Unlock()
+
+		if found && registrations >= count {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "waiting for %s registration %d", resourceName, count)
+		case <-changed:
+		}
+	}
+}
+
+// HealthyDevices returns a WaitForDevices condition for count healthy devices.
+func HealthyDevices(count int) func([]*pluginapi.Device) bool {
+	return func(devices []*pluginapi.Device) bool {
+		healthy := 0
+
+		for _, dev := range devices {
+			if dev.Health == pluginapi.Healthy {
+				healthy++
+			}
+		}
+
+		return healthy == count
+	}
+}
+
+func (k *Kubelet) client(resourceName string) (pluginapi.DevicePluginClient, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	p, found := k.plugins[resourceName]
+	if !found {
+		return nil, errors.Wrap(errNotRegistered, resourceName)
+	}
+
+	return p.client, nil
+}
+
+// Allocate allocates devices for containers from the plugin of a resource.
+// Every list of device IDs is a container.
+func (k *Kubelet) Allocate(ctx context.Context, resourceName string, containers ...[]string) (*pluginapi.AllocateResponse, error) {
+	client, err := k.client(resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pluginapi.AllocateRequest{}
+	for _, ids := range containers {
+		req.ContainerRequests = append(req.ContainerRequests, &pluginapi.ContainerAllocateRequest{DevicesIds: ids})
+	}
+
+	resp, err := client.Allocate(ctx, req)
+
+	return resp, errors.Wrap(err, "allocate failed")
+}
+
+// GetPreferredAllocation asks the plugin of a resource for size devices
+// out of available including mustInclude.
+func (k *Kubelet) GetPreferredAllocation(ctx context.Context, resourceName string, available, mustInclude []string, size int) ([]string, error) {
+	client, err := k.client(resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetPreferredAllocation(ctx, &pluginapi.PreferredAllocationRequest{
+		ContainerRequests: []*pluginapi.ContainerPreferredAllocationRequest{{
+			AvailableDeviceIDs:   available,
+			MustIncludeDeviceIDs: mustInclude,
+			AllocationSize:       int32(size),
+		}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "preferred allocation failed")
+	}
+
+	if len(resp.ContainerResponses) != 1 {
+		return nil, errors.Errorf("expected one container response, got %d", len(resp.ContainerResponses))
+	}
+
+	return resp.ContainerResponses[0].DeviceIDs, nil
+}
+
+// PreStartContainer calls PreStartContainer of the plugin of a resource.
+func (k *Kubelet) PreStartContainer(ctx context.Context, resourceName string, ids []string) error {
+	client, err := k.client(resourceName)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.PreStartContainer(ctx, &pluginapi.PreStartContainerRequest{DevicesIds: ids})
+
+	return errors.Wrap(err, "pre-start container failed")
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubeletstub
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+)
+
+const (
+	namespace    = "stub.intel.com"
+	resourceName = namespace + "/dev"
+)
+
+// testPlugin sends the device trees it is given to the Manager.
+type testPlugin struct {
+	trees chan dpapi.DeviceTree
+}
+
+func (p *testPlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case tree := <-p.trees:
+			notifier.Notify(tree)
+		}
+	}
+}
+
+func testTree(health ...string) dpapi.DeviceTree {
+	tree := dpapi.NewDeviceTree()
+
+	for i, state := range health {
+		id := "dev" + string(rune('0'+i))
+		tree.AddDevice("dev", id, dpapi.NewDeviceInfo(state, []pluginapi.DeviceSpec{{
+			HostPath:      "/dev/" + id,
+			ContainerPath: "/dev/" + id,
+			Permissions:   "rw",
+		}}, nil, nil, nil, nil))
+	}
+
+	return tree
+}
+
+func TestKubelet(t *testing.T) {
+	// Socket paths have to be short.
+	dir, err := os.MkdirTemp("/tmp", "kubeletstub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kubelet := New(dir)
+	if err = kubelet.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer kubelet.Stop()
+
+	plugin := &testPlugin{trees: make(chan dpapi.DeviceTree, 1)}
+	plugin.trees <- testTree(pluginapi.Healthy, pluginapi.Healthy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	manager := dpapi.NewManager(namespace, plugin)
+	manager.SetDevicePluginPath(dir)
+
+	runErr := make(chan error, 1)
+
+	go func() {
+		runErr <- manager.Run(ctx)
+	}()
+
+	if _, err = kubelet.WaitForDevices(ctx, resourceName, HealthyDevices(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := kubelet.Allocate(ctx, resourceName, []string{"dev0"}, []string{"dev1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.ContainerResponses) != 2 || resp.ContainerResponses[1].Devices[0].HostPath != "/dev/dev1" {
+		t.Errorf("unexpected allocation: %+v", resp)
+	}
+
+	if _, err = kubelet.Allocate(ctx, resourceName, []string{"dev2"}); err == nil {
+		t.Error("unknown device allocated")
+	}
+
+	if _, err = kubelet.Allocate(ctx, namespace+"/other", []string{"dev0"}); err == nil {
+		t.Error("unregistered resource allocated")
+	}
+
+	plugin.trees <- testTree(pluginapi.Healthy, pluginapi.Unhealthy)
+
+	if _, err = kubelet.WaitForDevices(ctx, resourceName, HealthyDevices(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = kubelet.Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = kubelet.WaitForRegistrations(ctx, resourceName, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	devices, err := kubelet.WaitForDevices(ctx, resourceName, func(devices []*pluginapi.Device) bool {
+		return len(devices) == 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !HealthyDevices(1)(devices) {
+		t.Errorf("device health lost in kubelet restart: %v", devices)
+	}
+
+	if _, err = kubelet.Allocate(ctx, resourceName, []string{"dev0"}); err != nil {
+		t.Errorf("allocation after kubelet restart failed: %+v", err)
+	}
+
+	cancel()
+
+	if err = <-runErr; err != nil {
+		t.Errorf("manager failed: %+v", err)
+	}
+
+	// The stub forgets the plugin once the device list stream ends.
+	for i := 0; ; i++ {
+		if _, found := kubelet.Devices(resourceName); !found {
+			break
+		}
+
+		if i == 50 {
+			t.Fatal("stopped plugin still registered")
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
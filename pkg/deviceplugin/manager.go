@@ -15,17 +15,47 @@
 package deviceplugin
 
 import (
+	"context"
+	"flag"
 	"os"
 	"reflect"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
+const (
+	// KubeletAPIDevicePlugin makes Manager serve devices with the kubelet device plugin API.
+	KubeletAPIDevicePlugin = "deviceplugin"
+	// KubeletAPIDRA makes Manager serve devices with Dynamic Resource Allocation.
+	KubeletAPIDRA = "dra"
+)
+
+const (
+	// Upper limit of the delay between the restarts of a failing scan.
+	maxScanRestartBackoff = 2 * time.Minute
+	// Failures of scans that have run for longer than this are not counted as consecutive.
+	scanStablePeriod = 5 * time.Minute
+	// How long Manager waits for a canceled scan to return.
+	scanStopTimeout = 10 * time.Second
+)
+
+var (
+	kubeletAPI = flag.String("kubelet-api", KubeletAPIDevicePlugin,
+		"kubelet API used for serving the devices: deviceplugin or dra. With dra, devices are published as ResourceSlices and NODE_NAME environment variable must be set")
+	scanRestarts = flag.Int("scan-restarts", 5,
+		"number of consecutive times a failed device scan is restarted before the plugin exits. Negative means no limit")
+	scanRestartBackoff = flag.Duration("scan-restart-backoff", time.Second,
+		"delay before restarting a failed device scan. The delay doubles on every consecutive failure up to "+maxScanRestartBackoff.String())
+)
+
 type allocateFunc func(*pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error)
 type postAllocateFunc func(*pluginapi.AllocateResponse) error
 type preStartContainerFunc func(*pluginapi.PreStartContainerRequest) error
-type getPreferredAllocationFunc func(*pluginapi.PreferredAllocationRequest) (*pluginapi.PreferredAllocationResponse, error)
+type getPreferredAllocationFunc func(*pluginapi.PreferredAllocationRequest, map[string]DeviceInfo) (*pluginapi.PreferredAllocationResponse, error)
 
 // updateInfo contains info for added, updated and deleted devices.
 type updateInfo struct {
@@ -38,6 +68,11 @@ type updateInfo struct {
 type notifier struct {
 	deviceTree DeviceTree
 	updatesCh  chan<- updateInfo
+	// done unblocks pending updates when Manager stops.
+	done <-chan struct{}
+	// cdiSpecs are synced with the first and every changed scan result.
+	cdiSpecs *cdiSpecs
+	notified bool
 }
 
 func newNotifier(updatesCh chan<- updateInfo) *notifier {
@@ -47,6 +82,9 @@ func newNotifier(updatesCh chan<- updateInfo) *notifier {
 }
 
 func (n *notifier) Notify(newDeviceTree DeviceTree) {
+	first := !n.notified
+	n.notified = true
+
 	added := NewDeviceTree()
 	updated := NewDeviceTree()
 
@@ -62,15 +100,24 @@ func (n *notifier) Notify(newDeviceTree DeviceTree) {
 		}
 	}
 
-	if len(added) > 0 || len(updated) > 0 || len(n.deviceTree) > 0 {
-		n.updatesCh <- updateInfo{
+	changed := len(added) > 0 || len(updated) > 0 || len(n.deviceTree) > 0
+
+	if changed {
+		select {
+		case n.updatesCh <- updateInfo{
 			Added:   added,
 			Updated: updated,
 			Removed: n.deviceTree,
+		}:
+		case <-n.done:
 		}
 	}
 
 	n.deviceTree = newDeviceTree
+
+	if n.cdiSpecs != nil && (changed || first) {
+		n.cdiSpecs.sync(newDeviceTree)
+	}
 }
 
 // Manager manages life cycle of device plugins and handles the scan results
@@ -78,8 +125,43 @@ func (n *notifier) Notify(newDeviceTree DeviceTree) {
 type Manager struct {
 	devicePlugin Scanner
 	servers      map[string]devicePluginServer
-	createServer func(string, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc) devicePluginServer
-	namespace    string
+	createServer func(string, *cdiSpecs, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc, *nodeEvents) devicePluginServer
+	// CDI specs written for the allocated devices.
+	cdiSpecs *cdiSpecs
+	// Node events and conditions, nil when not published.
+	events *nodeEvents
+	// Options loaded from the file given with -config, nil without one.
+	config *config
+	// serveErrs receives the errors of failed servers.
+	serveErrs   chan error
+	namespace   string
+	kubeletAPI  string
+	cdiMode     string
+	metricsAddr string
+	// Output format of the scan-and-print mode, empty when serving the devices.
+	printFormat string
+	// Directory of the kubelet and device plugin sockets.
+	devicePluginPath string
+	// OTLP collector endpoint and file the traces are exported to.
+	tracingEndpoint string
+	tracingFile     string
+	// PodResources API socket and debug endpoint address for DeviceOwners.
+	podResourcesSocket string
+	debugAddr          string
+	// Address of the liveness probe endpoint.
+	healthProbeAddr string
+	// File listing the devices to quarantine.
+	quarantineFile string
+	// File with the rules renaming device types.
+	resourceRulesFile string
+	// Initial delay and maximum number of consecutive restarts of failed scans.
+	scanRestartBackoff time.Duration
+	scanRestarts       int
+	// Consecutive scan results needed for device health transitions.
+	healthFailures   int
+	healthRecoveries int
+	// Publish node events and conditions.
+	nodeEvents bool
 }
 
 // NewManager creates a new instance of Manager.
@@ -89,26 +171,361 @@ func NewManager(namespace string, devicePlugin Scanner) *Manager {
 		namespace:    namespace,
 		servers:      make(map[string]devicePluginServer),
 		createServer: newServer,
+		cdiSpecs:     newCdiSpecs(CDIDir, namespace),
+		serveErrs:    make(chan error, 1),
+		config:       parsedConfig,
+		kubeletAPI:   *kubeletAPI,
+		cdiMode:      *cdiMode,
+		metricsAddr:  *metricsBindAddress,
+		printFormat:  *scanAndPrint,
+
+		devicePluginPath:   pluginapi.DevicePluginPath,
+		podResourcesSocket: *podResourcesSocket,
+		debugAddr:          *debugBindAddress,
+		healthProbeAddr:    *healthProbeBindAddress,
+		scanRestartBackoff: *scanRestartBackoff,
+		scanRestarts:       *scanRestarts,
+		healthFailures:     *healthFailureThreshold,
+		healthRecoveries:   *healthRecoveryThreshold,
+		quarantineFile:     *quarantineFile,
+		resourceRulesFile:  *resourceRulesFile,
+		nodeEvents:         *nodeEventsEnabled,
+		tracingEndpoint:    *tracingEndpoint,
+		tracingFile:        *tracingFile,
+	}
+}
+
+// SetDevicePluginPath changes the directory of the kubelet and device plugin
+// sockets from the kubelet default. It is meant for running plugins against
+// a fake kubelet, see package kubeletstub.
+func (m *Manager) SetDevicePluginPath(dir string) {
+	m.devicePluginPath = dir
+}
+
+// SetCDIDir changes the directory of the CDI specs written for the allocated
+// devices from CDIDir, e.g. to keep tests running against a fake kubelet
+// from writing to the host.
+func (m *Manager) SetCDIDir(dir string) {
+	m.cdiSpecs = newCdiSpecs(dir, m.namespace)
+}
+
+// Run serves the devices found by the Scanner until ctx is canceled, the
+// Scanner fails more times in a row than allowed or a server fails. Before
+// returning, Run cancels the Scanner, stops all servers and removes their
+// sockets. The CDI specs are kept for the containers still using the
+// devices. Cancellation of ctx is not an error.
+//
+// In the scan-and-print mode, Run only prints the devices found by the
+// first scan to the standard output.
+func (m *Manager) Run(ctx context.Context) error {
+	if m.printFormat != "" {
+		return m.scanAndPrint(ctx, os.Stdout)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if m.metricsAddr != "" {
+		go serveMetrics(ctx, m.metricsAddr)
+	}
+
+	if m.healthProbeAddr != "" {
+		go serveHealthProbe(ctx, m.healthProbeAddr, kubeletRegistrations)
+	}
+
+	stopTracing, err := m.setupTracing(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer stopTracing()
+
+	owners, err := m.setupDeviceOwners()
+	if err != nil {
+		return err
+	}
+
+	if owners != nil {
+		defer owners.Close()
+	}
+
+	if m.config != nil {
+		r, _ := m.devicePlugin.(Reconfigurer)
+		go m.config.watch(ctx, r)
+	}
+
+	health := newHealthFilter(m.healthFailures, m.healthRecoveries)
+	attributes := newAttributeRecorder()
+	attributes.next = health
+
+	if m.debugAddr != "" {
+		go serveDebug(ctx, m.debugAddr, owners, health, attributes)
+	}
+
+	quarantine, err := m.setupQuarantine(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = m.setupNodeEvents(ctx); err != nil {
+		return err
+	}
+
+	health.next = quarantine
+
+	scanned, err := m.setupResourceRules(attributes)
+	if err != nil {
+		return err
+	}
+
+	dra, err := m.selectKubeletAPI()
+	if err != nil {
+		return err
+	}
+
+	if dra {
+		return m.runDRA(ctx, scanned, quarantine)
+	}
+
+	return m.serve(ctx, cancel, scanned, quarantine)
+}
+
+// selectKubeletAPI checks the CDI mode and the kubelet API and reports whether
+// the devices are served with DRA.
+func (m *Manager) selectKubeletAPI() (bool, error) {
+	switch m.cdiMode {
+	case CDIModeStatic:
+		m.cdiSpecs.staticKind = CDIVendor + "/" + strings.SplitN(m.namespace, ".", 2)[0]
+	case CDIModeDynamic, "":
+	default:
+		return false, errors.Errorf("unsupported CDI mode: %s", m.cdiMode)
+	}
+
+	switch m.kubeletAPI {
+	case KubeletAPIDRA:
+		if m.cdiMode == CDIModeStatic {
+			klog.Warning("Static CDI mode is not supported with DRA, ignoring")
+		}
+
+		return true, nil
+	case KubeletAPIDevicePlugin, "":
+		return false, nil
+	default:
+		return false, errors.Errorf("unsupported kubelet API: %s", m.kubeletAPI)
 	}
 }
 
-// Run prepares and launches event loop for updates from Scanner.
-func (m *Manager) Run() {
+// serve runs the Scanner and updates the device plugin servers with the
+// devices found until ctx is canceled, the Scanner gives up or a server fails.
+func (m *Manager) serve(ctx context.Context, cancel context.CancelFunc, scanned Notifier, quarantine *quarantine) error {
 	updatesCh := make(chan updateInfo)
+	scanErr := make(chan error, 1)
+
+	n := newNotifier(updatesCh)
+	n.done = ctx.Done()
+	n.cdiSpecs = m.cdiSpecs
+	quarantine.next = m.events.notifier(n)
 
 	go func() {
-		err := m.devicePlugin.Scan(newNotifier(updatesCh))
+		scanErr <- m.scan(ctx, scanned)
+	}()
+
+	defer m.stopServers()
+
+	for {
+		select {
+		case update := <-updatesCh:
+			m.handleUpdate(update)
+		case err := <-scanErr:
+			return err
+		case err := <-m.serveErrs:
+			cancel()
+			waitForScan(scanErr)
+
+			return err
+		case <-ctx.Done():
+			klog.V(1).Info("Shutting down")
+			waitForScan(scanErr)
+
+			return nil
+		}
+	}
+}
+
+// scan runs the Scanner and restarts it with an exponential backoff when it fails.
+func (m *Manager) scan(ctx context.Context, n Notifier) error {
+	backoff := m.scanRestartBackoff
+	failures := 0
+	traced := &scanTracer{next: n}
+
+	for {
+		start := time.Now()
+
+		err := m.devicePlugin.Scan(ctx, traced)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+
+		// A scan that has been running fine for a while starts a new series of failures.
+		if time.Since(start) > scanStablePeriod {
+			failures = 0
+			backoff = m.scanRestartBackoff
+		}
+
+		failures++
+
+		if m.scanRestarts >= 0 && failures > m.scanRestarts {
+			return errors.Wrap(err, "device scan failed")
+		}
+
+		klog.Errorf("Device scan failed, restarting in %v (attempt %d): %+v", backoff, failures, err)
+		scanRestartsTotal.Inc()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, maxScanRestartBackoff)
+	}
+}
+
+// waitForScan waits for a canceled scan to return.
+func waitForScan(scanErr <-chan error) {
+	select {
+	case err := <-scanErr:
 		if err != nil {
 			klog.Errorf("Device scan failed: %+v", err)
-			os.Exit(1)
 		}
+	case <-time.After(scanStopTimeout):
+		klog.Warningf("Device scan did not stop in %v", scanStopTimeout)
+	}
+}
 
-		close(updatesCh)
-	}()
+// stopServers stops all servers. The CDI specs are kept, as kubelet uses
+// them to restart the containers of the running pods.
+func (m *Manager) stopServers() {
+	for devType, srv := range m.servers {
+		if err := srv.Stop(); err != nil {
+			klog.Errorf("Unable to stop gRPC server for %q: %+v", devType, err)
+		}
+
+		delete(m.servers, devType)
+	}
+}
+
+// setupDeviceOwners hands DeviceOwners to the plugin when the PodResources
+// API is enabled.
+func (m *Manager) setupDeviceOwners() (*DeviceOwners, error) {
+	if m.podResourcesSocket == "" {
+		return nil, nil
+	}
+
+	owners, err := NewDeviceOwners(m.podResourcesSocket, m.namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set up device owners")
+	}
+
+	if user, ok := m.devicePlugin.(DeviceOwnersUser); ok {
+		user.SetDeviceOwners(owners)
+	}
+
+	return owners, nil
+}
+
+// setupQuarantine loads the quarantine file and starts watching it when
+// one is given.
+func (m *Manager) setupQuarantine(ctx context.Context) (*quarantine, error) {
+	q := newQuarantine(m.quarantineFile)
+	if m.quarantineFile == "" {
+		return q, nil
+	}
+
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+
+	go q.watch(ctx)
 
-	for update := range updatesCh {
-		m.handleUpdate(update)
+	return q, nil
+}
+
+// setupResourceRules returns the Notifier for the Scanner, renaming the
+// devices before passing them to next when resource rules are given.
+func (m *Manager) setupResourceRules(next Notifier) (Notifier, error) {
+	if m.resourceRulesFile == "" {
+		return next, nil
+	}
+
+	rules, err := loadResourceRules(m.resourceRulesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	rules.next = next
+
+	return rules, nil
+}
+
+// setupTracing starts exporting traces when an endpoint or a file is given.
+// The returned function stops the exports.
+func (m *Manager) setupTracing(ctx context.Context) (func(), error) {
+	if m.tracingEndpoint == "" && m.tracingFile == "" {
+		return func() {}, nil
+	}
+
+	return setupTracing(ctx, strings.SplitN(m.namespace, ".", 2)[0]+"-device-plugin", m.tracingEndpoint, m.tracingFile)
+}
+
+// setupNodeEvents starts publishing node events and conditions when enabled.
+func (m *Manager) setupNodeEvents(ctx context.Context) error {
+	if !m.nodeEvents {
+		return nil
+	}
+
+	nodeName, client, err := inClusterClient("node events")
+	if err != nil {
+		return err
+	}
+
+	m.events = newNodeEvents(ctx, m.namespace, nodeName, client)
+
+	return nil
+}
+
+// runDRA serves the devices found by the Scanner with a DRA driver named after the namespace.
+func (m *Manager) runDRA(ctx context.Context, scanned Notifier, quarantine *quarantine) error {
+	driver, err := newDRADriverInCluster(m.namespace, m.devicePlugin)
+	if err != nil {
+		return errors.Wrap(err, "failed to create DRA driver")
+	}
+
+	if err = driver.serve(); err != nil {
+		return err
 	}
+
+	defer driver.stop()
+
+	quarantine.next = m.events.notifier(driver)
+
+	return m.scan(ctx, scanned)
+}
+
+// preferredAllocationFunc returns the preferred allocation hook of the
+// plugin, nil if it has none.
+func (m *Manager) preferredAllocationFunc() getPreferredAllocationFunc {
+	if allocator, ok := m.devicePlugin.(DevicePreferredAllocator); ok {
+		return allocator.GetPreferredDeviceAllocation
+	}
+
+	if allocator, ok := m.devicePlugin.(PreferredAllocator); ok {
+		return func(rqt *pluginapi.PreferredAllocationRequest, _ map[string]DeviceInfo) (*pluginapi.PreferredAllocationResponse, error) {
+			return allocator.GetPreferredAllocation(rqt)
+		}
+	}
+
+	return nil
 }
 
 func (m *Manager) handleUpdate(update updateInfo) {
@@ -116,10 +533,9 @@ func (m *Manager) handleUpdate(update updateInfo) {
 
 	for devType, devices := range update.Added {
 		var (
-			allocate               allocateFunc
-			postAllocate           postAllocateFunc
-			preStartContainer      preStartContainerFunc
-			getPreferredAllocation getPreferredAllocationFunc
+			allocate          allocateFunc
+			postAllocate      postAllocateFunc
+			preStartContainer preStartContainerFunc
 		)
 
 		if postAllocator, ok := m.devicePlugin.(PostAllocator); ok {
@@ -130,23 +546,21 @@ func (m *Manager) handleUpdate(update updateInfo) {
 			preStartContainer = containerPreStarter.PreStartContainer
 		}
 
-		if preferredAllocator, ok := m.devicePlugin.(PreferredAllocator); ok {
-			getPreferredAllocation = preferredAllocator.GetPreferredAllocation
-		}
-
 		if allocator, ok := m.devicePlugin.(Allocator); ok {
 			allocate = allocator.Allocate
 		}
 
-		m.servers[devType] = m.createServer(devType, postAllocate, preStartContainer, getPreferredAllocation, allocate)
+		m.servers[devType] = m.createServer(devType, m.cdiSpecs, postAllocate, preStartContainer, m.preferredAllocationFunc(), allocate, m.events)
 
-		go func(dt string) {
-			err := m.servers[dt].Serve(m.namespace)
-			if err != nil {
-				klog.Errorf("Failed to serve %s/%s: %+v", m.namespace, dt, err)
-				os.Exit(1)
+		go func(dt string, srv devicePluginServer) {
+			if err := srv.Serve(m.namespace, m.devicePluginPath); err != nil {
+				// Only the first error is of interest, Run returns on it.
+				select {
+				case m.serveErrs <- errors.Wrapf(err, "failed to serve %s/%s", m.namespace, dt):
+				default:
+				}
 			}
-		}(devType)
+		}(devType, m.servers[devType])
 		m.servers[devType].Update(devices)
 	}
 
@@ -15,8 +15,12 @@
 package deviceplugin
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
@@ -137,7 +141,7 @@ func TestNotify(t *testing.T) {
 
 type serverStub struct{}
 
-func (*serverStub) Serve(string) error {
+func (*serverStub) Serve(string, string) error {
 	return nil
 }
 
@@ -149,7 +153,7 @@ func (*serverStub) Stop() error {
 
 type devicePluginStub struct{}
 
-func (*devicePluginStub) Scan(n Notifier) error {
+func (*devicePluginStub) Scan(ctx context.Context, n Notifier) error {
 	tree := NewDeviceTree()
 	tree.AddDevice("testdevice", "dev1", DeviceInfo{
 		state: pluginapi.Healthy,
@@ -274,7 +278,7 @@ func TestHandleUpdate(t *testing.T) {
 		mgr := Manager{
 			devicePlugin: &devicePluginStub{},
 			servers:      tt.servers,
-			createServer: func(string, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc) devicePluginServer {
+			createServer: func(string, *cdiSpecs, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc, *nodeEvents) devicePluginServer {
 				return &serverStub{}
 			},
 		}
@@ -290,8 +294,187 @@ func TestHandleUpdate(t *testing.T) {
 
 func TestRun(t *testing.T) {
 	mgr := NewManager("testnamespace", &devicePluginStub{})
-	mgr.createServer = func(string, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc) devicePluginServer {
+	mgr.createServer = func(string, *cdiSpecs, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc, *nodeEvents) devicePluginServer {
 		return &serverStub{}
 	}
-	mgr.Run()
+
+	if err := mgr.Run(context.Background()); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
+
+	if len(mgr.servers) != 0 {
+		t.Errorf("servers not stopped: %d", len(mgr.servers))
+	}
+}
+
+func TestStopServersKeepsCdiSpecs(t *testing.T) {
+	dir := t.TempDir()
+
+	mgr := Manager{
+		servers:  map[string]devicePluginServer{"test": &serverStub{}},
+		cdiSpecs: newCdiSpecs(dir, "test.intel.com"),
+	}
+
+	writeTestCdiSpecs(t, mgr.cdiSpecs, testCdiTree("dev1"))
+
+	mgr.stopServers()
+
+	// Kubelet needs the specs to restart the containers of running pods.
+	checkCdiSpecFiles(t, dir, map[string]bool{"dev1": true})
+}
+
+func TestRunUnsupportedModes(t *testing.T) {
+	tcases := []struct {
+		name       string
+		kubeletAPI string
+		cdiMode    string
+	}{
+		{name: "kubelet API", kubeletAPI: "foo"},
+		{name: "CDI mode", cdiMode: "foo"},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mgr := NewManager("testnamespace", &devicePluginStub{})
+			mgr.kubeletAPI = tc.kubeletAPI
+			mgr.cdiMode = tc.cdiMode
+
+			if err := mgr.Run(context.Background()); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// blockingScannerStub reports one device and waits for cancellation.
+type blockingScannerStub struct{}
+
+func (*blockingScannerStub) Scan(ctx context.Context, n Notifier) error {
+	tree := NewDeviceTree()
+	tree.AddDevice("testdevice", "dev1", DeviceInfo{state: pluginapi.Healthy})
+	n.Notify(tree)
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// stoppableServerStub records Stop calls and can fail Serve.
+type stoppableServerStub struct {
+	serveErr error
+	stopped  atomic.Bool
+}
+
+func (s *stoppableServerStub) Serve(string, string) error {
+	return s.serveErr
+}
+
+func (*stoppableServerStub) Update(map[string]DeviceInfo) {}
+
+func (s *stoppableServerStub) Stop() error {
+	s.stopped.Store(true)
+
+	return nil
+}
+
+func TestRunCancel(t *testing.T) {
+	srv := &stoppableServerStub{}
+
+	mgr := NewManager("testnamespace", &blockingScannerStub{})
+	mgr.createServer = func(string, *cdiSpecs, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc, *nodeEvents) devicePluginServer {
+		return srv
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- mgr.Run(ctx)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	if !srv.stopped.Load() {
+		t.Error("server not stopped")
+	}
+}
+
+func TestRunServeError(t *testing.T) {
+	srv := &stoppableServerStub{serveErr: errFake}
+
+	mgr := NewManager("testnamespace", &blockingScannerStub{})
+	mgr.createServer = func(string, *cdiSpecs, postAllocateFunc, preStartContainerFunc, getPreferredAllocationFunc, allocateFunc, *nodeEvents) devicePluginServer {
+		return srv
+	}
+
+	if err := mgr.Run(context.Background()); !errors.Is(err, errFake) {
+		t.Errorf("expected serve error, got %+v", err)
+	}
+
+	if !srv.stopped.Load() {
+		t.Error("server not stopped")
+	}
+}
+
+// failingScannerStub fails every scan.
+type failingScannerStub struct {
+	calls atomic.Int32
+}
+
+func (s *failingScannerStub) Scan(context.Context, Notifier) error {
+	s.calls.Add(1)
+
+	return errFake
+}
+
+func TestScanRestarts(t *testing.T) {
+	tcases := []struct {
+		name          string
+		restarts      int
+		expectedCalls int32
+	}{
+		{name: "no restarts", restarts: 0, expectedCalls: 1},
+		{name: "three restarts", restarts: 3, expectedCalls: 4},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			scanner := &failingScannerStub{}
+			mgr := NewManager("testnamespace", scanner)
+			mgr.scanRestarts = tc.restarts
+			mgr.scanRestartBackoff = time.Millisecond
+
+			if err := mgr.Run(context.Background()); !errors.Is(err, errFake) {
+				t.Errorf("expected scan error, got %+v", err)
+			}
+
+			if calls := scanner.calls.Load(); calls != tc.expectedCalls {
+				t.Errorf("expected %d scans, got %d", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestScanRestartCancel(t *testing.T) {
+	scanner := &failingScannerStub{}
+	mgr := NewManager("testnamespace", scanner)
+	mgr.scanRestarts = -1
+	mgr.scanRestartBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	if err := mgr.Run(ctx); err != nil {
+		t.Errorf("unexpected error: %+v", err)
+	}
 }
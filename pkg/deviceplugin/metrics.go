@@ -0,0 +1,257 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	metricsNamespace = "intel_device_plugin"
+
+	methodAllocate               = "Allocate"
+	methodGetPreferredAllocation = "GetPreferredAllocation"
+	methodPreStartContainer      = "PreStartContainer"
+
+	httpReadHeaderTimeout = 10 * time.Second
+	httpShutdownTimeout   = 5 * time.Second
+)
+
+var (
+	metricsBindAddress = flag.String("metrics-bind-address", "",
+		"address (e.g. :8080) for serving Prometheus metrics at /metrics. Metrics are not served when empty")
+	debugBindAddress = flag.String("debug-bind-address", "",
+		"local address (e.g. localhost:8081) for serving the device health at "+deviceHealthPath+
+			" and, with -pod-resources-socket, the device to pod mapping at "+deviceOwnersPath+". Not served when empty")
+
+	metricsRegistry = prometheus.NewRegistry()
+
+	devicesAdvertised = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "devices_advertised",
+		Help:      "Number of devices advertised to kubelet.",
+	}, []string{"resource"})
+
+	devicesByHealth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "devices",
+		Help:      "Number of devices advertised to kubelet by health state.",
+	}, []string{"resource", "health"})
+
+	devicesUnhealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "device_unhealthy",
+		Help:      "Set to 1 for every unhealthy device with the reason it's unhealthy.",
+	}, []string{"resource", "device", "reason"})
+
+	healthTransitionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "health_transitions_total",
+		Help:      "Number of device health transitions by the new health state.",
+	}, []string{"resource", "health"})
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "requests_total",
+		Help:      "Number of kubelet requests served.",
+	}, []string{"resource", "method"})
+
+	requestErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "request_errors_total",
+		Help:      "Number of kubelet requests that failed.",
+	}, []string{"resource", "method"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "request_duration_seconds",
+		Help:      "Latency of kubelet requests.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 4, 10),
+	}, []string{"resource", "method"})
+
+	registrationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "kubelet_registrations_total",
+		Help:      "Number of successful registrations with kubelet, including re-registrations after kubelet restarts.",
+	}, []string{"resource"})
+
+	scansTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "scans_total",
+		Help:      "Number of device trees reported by the Scanner.",
+	})
+
+	scanInterval = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "scan_interval_seconds",
+		Help:      "Time between consecutive device trees reported by the Scanner.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+
+	lastScanTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "last_scan_timestamp_seconds",
+		Help:      "Unix time of the latest device tree reported by the Scanner.",
+	})
+
+	scanRestartsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "scan_restarts_total",
+		Help:      "Number of times a failed Scanner has been restarted.",
+	})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		devicesAdvertised,
+		devicesByHealth,
+		devicesUnhealthy,
+		healthTransitionsTotal,
+		requestsTotal,
+		requestErrorsTotal,
+		requestDuration,
+		registrationsTotal,
+		scansTotal,
+		scanInterval,
+		lastScanTimestamp,
+		scanRestartsTotal,
+	)
+}
+
+// observeRequest records a served kubelet request.
+func observeRequest(devType, method string, start time.Time, err error) {
+	requestsTotal.WithLabelValues(devType, method).Inc()
+	requestDuration.WithLabelValues(devType, method).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		requestErrorsTotal.WithLabelValues(devType, method).Inc()
+	}
+}
+
+// observeDevices records the devices advertised to kubelet.
+func observeDevices(devType string, devices map[string]DeviceInfo) {
+	healthy, unhealthy := 0, 0
+
+	for _, dev := range devices {
+		if dev.state == pluginapi.Healthy {
+			healthy++
+		} else {
+			unhealthy++
+		}
+	}
+
+	devicesAdvertised.WithLabelValues(devType).Set(float64(len(devices)))
+	devicesByHealth.WithLabelValues(devType, pluginapi.Healthy).Set(float64(healthy))
+	devicesByHealth.WithLabelValues(devType, pluginapi.Unhealthy).Set(float64(unhealthy))
+}
+
+// forgetDevices removes the metrics of a device type that is no longer served.
+func forgetDevices(devType string) {
+	labels := prometheus.Labels{"resource": devType}
+
+	devicesAdvertised.DeletePartialMatch(labels)
+	devicesByHealth.DeletePartialMatch(labels)
+}
+
+// observeDeviceHealth records the health of a device.
+func observeDeviceHealth(devType, id string, health *deviceHealth) {
+	forgetDeviceHealth(devType, id)
+
+	if health.Health != pluginapi.Healthy {
+		devicesUnhealthy.WithLabelValues(devType, id, health.Reason).Set(1)
+	}
+}
+
+// forgetDeviceHealth removes the health metrics of a device.
+func forgetDeviceHealth(devType, id string) {
+	devicesUnhealthy.DeletePartialMatch(prometheus.Labels{"resource": devType, "device": id})
+}
+
+// observeScan records a device tree reported by the Scanner. previous is the
+// time of the previously reported tree or zero for the first one.
+func observeScan(previous, now time.Time) {
+	scansTotal.Inc()
+	lastScanTimestamp.Set(float64(now.Unix()))
+
+	if !previous.IsZero() {
+		scanInterval.Observe(now.Sub(previous).Seconds())
+	}
+}
+
+// serveMetrics serves the Prometheus metrics at addr until ctx is canceled.
+func serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+
+	klog.V(1).Infof("Serving metrics at %s/metrics", addr)
+
+	if err := serveHTTP(ctx, addr, mux); err != nil {
+		klog.Errorf("Metrics server failed: %+v", err)
+	}
+}
+
+// serveDebug serves the device health, attributes and owners at addr until
+// ctx is canceled. owners may be nil.
+func serveDebug(ctx context.Context, addr string, owners *DeviceOwners, health, attributes http.Handler) {
+	mux := http.NewServeMux()
+	mux.Handle(deviceHealthPath, health)
+	mux.Handle(deviceAttributesPath, attributes)
+
+	if owners != nil {
+		mux.Handle(deviceOwnersPath, owners)
+	}
+
+	klog.V(1).Infof("Serving debug endpoints at %s", addr)
+
+	if err := serveHTTP(ctx, addr, mux); err != nil {
+		klog.Errorf("Debug server failed: %+v", err)
+	}
+}
+
+// serveHTTP serves handler at addr until ctx is canceled or the server fails.
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
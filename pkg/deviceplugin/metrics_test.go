@@ -0,0 +1,164 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	srv := newTestServer()
+	srv.devType = "metricstype"
+	srv.getPreferredAllocation = func(*pluginapi.PreferredAllocationRequest, map[string]DeviceInfo) (*pluginapi.PreferredAllocationResponse, error) {
+		return nil, errFake
+	}
+
+	rqt := &pluginapi.AllocateRequest{
+		ContainerRequests: []*pluginapi.ContainerAllocateRequest{{DevicesIds: []string{"dev1"}}},
+	}
+
+	_, _ = srv.Allocate(context.Background(), rqt)
+
+	rqt.ContainerRequests[0].DevicesIds = []string{"missing"}
+	_, _ = srv.Allocate(context.Background(), rqt)
+
+	_, _ = srv.GetPreferredAllocation(context.Background(), &pluginapi.PreferredAllocationRequest{})
+
+	if v := testutil.ToFloat64(requestsTotal.WithLabelValues("metricstype", methodAllocate)); v != 2 {
+		t.Errorf("expected 2 Allocate requests, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(requestErrorsTotal.WithLabelValues("metricstype", methodAllocate)); v != 1 {
+		t.Errorf("expected 1 failed Allocate request, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(requestErrorsTotal.WithLabelValues("metricstype", methodGetPreferredAllocation)); v != 1 {
+		t.Errorf("expected 1 failed GetPreferredAllocation request, got %v", v)
+	}
+
+	if n := testutil.CollectAndCount(requestDuration, metricsNamespace+"_request_duration_seconds"); n == 0 {
+		t.Error("no request latencies recorded")
+	}
+}
+
+func TestDeviceMetrics(t *testing.T) {
+	srv := newTestServer()
+	srv.devType = "devicestype"
+
+	srv.Update(map[string]DeviceInfo{
+		"dev1": {state: pluginapi.Healthy},
+		"dev2": {state: pluginapi.Healthy},
+		"dev3": {state: pluginapi.Unhealthy},
+	})
+
+	if v := testutil.ToFloat64(devicesAdvertised.WithLabelValues("devicestype")); v != 3 {
+		t.Errorf("expected 3 advertised devices, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(devicesByHealth.WithLabelValues("devicestype", pluginapi.Unhealthy)); v != 1 {
+		t.Errorf("expected 1 unhealthy device, got %v", v)
+	}
+
+	forgetDevices("devicestype")
+
+	if n := testutil.CollectAndCount(devicesAdvertised, metricsNamespace+"_devices_advertised"); n != 0 {
+		t.Errorf("expected no advertised device metrics after forgetting, got %d", n)
+	}
+}
+
+func TestDeviceHealthMetrics(t *testing.T) {
+	filter := newHealthFilter(1, 1)
+
+	tree := NewDeviceTree()
+	info := DeviceInfo{state: pluginapi.Unhealthy}
+	info.SetHealthReason("Wedged")
+	tree.AddDevice("healthtype", "card0", info)
+
+	filter.filter(tree, time.Now())
+
+	if v := testutil.ToFloat64(devicesUnhealthy.WithLabelValues("healthtype", "card0", "Wedged")); v != 1 {
+		t.Errorf("expected card0 to be unhealthy with the reason Wedged, got %v", v)
+	}
+
+	tree = NewDeviceTree()
+	tree.AddDevice("healthtype", "card0", DeviceInfo{state: pluginapi.Healthy})
+
+	filter.filter(tree, time.Now())
+
+	// The metric of card0 is deleted when it recovers.
+	if devicesUnhealthy.DeleteLabelValues("healthtype", "card0", "Wedged") {
+		t.Error("card0 still unhealthy after recovery")
+	}
+}
+
+func TestScanMetrics(t *testing.T) {
+	before := testutil.ToFloat64(scansTotal)
+
+	scan := &scanTracer{next: &treeRecorder{}}
+	scan.Notify(NewDeviceTree())
+
+	scan.lastNotify = scan.lastNotify.Add(-time.Second)
+	scan.Notify(NewDeviceTree())
+
+	if v := testutil.ToFloat64(scansTotal); v != before+2 {
+		t.Errorf("expected %v scans, got %v", before+2, v)
+	}
+
+	if n := testutil.CollectAndCount(scanInterval, metricsNamespace+"_scan_interval_seconds"); n != 1 {
+		t.Errorf("expected the scan interval histogram, got %d metrics", n)
+	}
+
+	if testutil.ToFloat64(lastScanTimestamp) == 0 {
+		t.Error("last scan timestamp not set")
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	registrationsTotal.WithLabelValues("handlertype").Inc()
+
+	ts := httptest.NewServer(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+	defer ts.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(body), `intel_device_plugin_kubelet_registrations_total{resource="handlertype"} 1`) {
+		t.Errorf("registration counter missing from metrics output")
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"k8s.io/klog/v2"
+)
+
+var (
+	resourceRulesFile = flag.String("resource-rules", "",
+		"YAML or JSON file with rules renaming the device types, i.e. the resources registered in the plugin's namespace, by device type, PCI ID, driver and NUMA node")
+
+	resourceNameRegexp = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	pciIDRegexp        = regexp.MustCompile(`^0x[0-9a-f]{4}$`)
+)
+
+// resourceRuleMatch selects the devices a rule applies to. All the given
+// fields must match. A list matches when any of its values does.
+type resourceRuleMatch struct {
+	// Device attributes and their values as text.
+	Attributes map[string]string `yaml:"attributes"`
+	Type       string            `yaml:"type"`
+	PCIIDs     []string          `yaml:"pciIDs"`
+	Drivers    []string          `yaml:"drivers"`
+	NUMANodes  []int64           `yaml:"numaNodes"`
+}
+
+// resourceRule gives the devices it matches a new device type.
+type resourceRule struct {
+	Rename string            `yaml:"rename"`
+	Match  resourceRuleMatch `yaml:"match"`
+}
+
+// deviceAttributes are the properties of a device the rules match on.
+type deviceAttributes struct {
+	pciIDs    []string
+	drivers   []string
+	numaNodes []int64
+}
+
+// resourceRules is a Notifier passing the device trees to the next Notifier
+// with the devices moved to the device type given by the first rule they
+// match. Rules matching a part of the devices of a type split it and rules
+// giving several types the same name merge them.
+type resourceRules struct {
+	next Notifier
+	// Devices dropped because of duplicate IDs in merged types.
+	dropped  map[string]struct{}
+	sysfsDir string
+	rules    []resourceRule
+}
+
+// loadResourceRules reads the rules from file.
+func loadResourceRules(file string) (*resourceRules, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read resource rules")
+	}
+
+	var rules []resourceRule
+	if err = yaml.UnmarshalStrict(data, &rules); err != nil {
+		return nil, errors.Wrapf(err, "invalid resource rules in %s", file)
+	}
+
+	for i := range rules {
+		if err = rules[i].validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid resource rule %d in %s", i+1, file)
+		}
+	}
+
+	return &resourceRules{
+		rules:    rules,
+		sysfsDir: "/sys",
+		dropped:  make(map[string]struct{}),
+	}, nil
+}
+
+func (r *resourceRule) validate() error {
+	if !resourceNameRegexp.MatchString(r.Rename) {
+		return errors.Errorf("invalid resource name %q", r.Rename)
+	}
+
+	for i, id := range r.Match.PCIIDs {
+		r.Match.PCIIDs[i] = strings.ToLower(id)
+
+		if !pciIDRegexp.MatchString(r.Match.PCIIDs[i]) {
+			return errors.Errorf("invalid PCI ID %q", id)
+		}
+	}
+
+	if slices.Contains(r.Match.Drivers, "") {
+		return errors.New("empty driver")
+	}
+
+	for _, node := range r.Match.NUMANodes {
+		if node < 0 {
+			return errors.Errorf("invalid NUMA node %d", node)
+		}
+	}
+
+	return nil
+}
+
+// Notify implements the Notifier interface.
+func (r *resourceRules) Notify(tree DeviceTree) {
+	r.next.Notify(r.apply(tree))
+}
+
+// apply returns a copy of tree with the devices renamed. Of the devices
+// having the same ID in a merged type, the one of the first type in name
+// order is kept.
+func (r *resourceRules) apply(tree DeviceTree) DeviceTree {
+	renamed := NewDeviceTree()
+
+	for _, devType := range slices.Sorted(maps.Keys(tree)) {
+		for _, id := range slices.Sorted(maps.Keys(tree[devType])) {
+			info := tree[devType][id]
+			name := r.rename(devType, id, &info)
+
+			if _, found := renamed[name][id]; found {
+				if _, found = r.dropped[devType+"/"+id]; !found {
+					klog.Warningf("Device %s/%s dropped, device %s/%s already exists", devType, id, name, id)
+
+					r.dropped[devType+"/"+id] = struct{}{}
+				}
+
+				continue
+			}
+
+			renamed.AddDevice(name, id, info)
+		}
+	}
+
+	return renamed
+}
+
+// rename returns the device type given to a device by the rules.
+func (r *resourceRules) rename(devType, id string, info *DeviceInfo) string {
+	var attrs *deviceAttributes
+
+	for i := range r.rules {
+		match := &r.rules[i].Match
+
+		if (match.Type != "" && match.Type != devType) || !matchesAttributes(match.Attributes, info.attributes) {
+			continue
+		}
+
+		if len(match.PCIIDs) > 0 || len(match.Drivers) > 0 || len(match.NUMANodes) > 0 {
+			if attrs == nil {
+				attrs = r.attributes(id, info)
+			}
+
+			if !matchesAny(match.PCIIDs, attrs.pciIDs) ||
+				!matchesAny(match.Drivers, attrs.drivers) ||
+				!matchesAny(match.NUMANodes, attrs.numaNodes) {
+				continue
+			}
+		}
+
+		return r.rules[i].Rename
+	}
+
+	return devType
+}
+
+// matchesAttributes tells if the device has all the wanted attribute values.
+func matchesAttributes(wanted map[string]string, attrs DeviceAttributes) bool {
+	for name, value := range wanted {
+		attr, found := attrs[name]
+		if !found || attr.String() != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesAny tells if any of values is wanted. No wanted values match all.
+func matchesAny[T comparable](wanted, values []T) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if slices.Contains(wanted, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attributes returns the attributes of a device. The PCI device ID and the
+// driver are taken from the device attributes set by the plugin and the
+// NUMA nodes from the topology of the device. The ones missing are read from
+// the PCI devices of the device, see pciAddresses.
+func (r *resourceRules) attributes(id string, info *DeviceInfo) *deviceAttributes {
+	attrs := infoAttributes(info)
+	if len(attrs.pciIDs) > 0 && len(attrs.drivers) > 0 && len(attrs.numaNodes) > 0 {
+		return attrs
+	}
+
+	sysfs := r.sysfsAttributes(pciAddresses(r.sysfsDir, id, info))
+
+	if len(attrs.pciIDs) == 0 {
+		attrs.pciIDs = sysfs.pciIDs
+	}
+
+	if len(attrs.drivers) == 0 {
+		attrs.drivers = sysfs.drivers
+	}
+
+	if len(attrs.numaNodes) == 0 {
+		attrs.numaNodes = sysfs.numaNodes
+	}
+
+	return attrs
+}
+
+// infoAttributes returns the attributes of a device known from its DeviceInfo.
+func infoAttributes(info *DeviceInfo) *deviceAttributes {
+	attrs := &deviceAttributes{}
+
+	if pciID, found := info.attributes.StringValue(AttributePCIDeviceID); found {
+		attrs.pciIDs = append(attrs.pciIDs, strings.ToLower(pciID))
+	}
+
+	if driver, found := info.attributes.StringValue(AttributeDriver); found {
+		attrs.drivers = append(attrs.drivers, driver)
+	}
+
+	if info.topology != nil {
+		for _, node := range info.topology.Nodes {
+			attrs.numaNodes = append(attrs.numaNodes, node.ID)
+		}
+	}
+
+	return attrs
+}
+
+// sysfsAttributes returns the attributes of the PCI devices at addrs read from sysfs.
+func (r *resourceRules) sysfsAttributes(addrs []string) *deviceAttributes {
+	attrs := &deviceAttributes{}
+
+	for _, addr := range addrs {
+		dir := filepath.Join(r.sysfsDir, "bus", "pci", "devices", addr)
+
+		if data, err := os.ReadFile(filepath.Join(dir, "device")); err == nil {
+			attrs.pciIDs = append(attrs.pciIDs, strings.TrimSpace(string(data)))
+		}
+
+		if link, err := filepath.EvalSymlinks(filepath.Join(dir, "driver")); err == nil {
+			attrs.drivers = append(attrs.drivers, filepath.Base(link))
+		}
+
+		if data, err := os.ReadFile(filepath.Join(dir, "numa_node")); err == nil {
+			if node, parseErr := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); parseErr == nil && node >= 0 {
+				attrs.numaNodes = append(attrs.numaNodes, node)
+			}
+		}
+	}
+
+	return attrs
+}
+
+// pciAddresses returns the addresses of the PCI devices of a device: the
+// PCI address attribute set by the plugin, the PCI devices of the device
+// nodes or the device ID if it is a PCI address.
+func pciAddresses(sysfsDir, id string, info *DeviceInfo) []string {
+	if addr, found := info.attributes.StringValue(AttributePCIAddress); found {
+		return []string{addr}
+	}
+
+	addrs := []string{}
+
+	for i := range info.nodes {
+		if addr, err := pciAddressForNode(sysfsDir, info.nodes[i].HostPath); err == nil {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if len(addrs) == 0 && id != "" && pciAddressRegexp.FindString(id) == id {
+		addrs = append(addrs, id)
+	}
+
+	return addrs
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// createNamingSysfs creates a sysfs with two PCI devices, /dev/null (1:3)
+// being a device node of the first one.
+func createNamingSysfs(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	devices := map[string]map[string]string{
+		"0000:00:02.0": {"device": "0x56a0\n", "numa_node": "1\n", "driver": "i915"},
+		"0000:00:03.0": {"device": "0x56c0\n", "numa_node": "0\n", "driver": "xe"},
+	}
+
+	for addr, files := range devices {
+		dir := filepath.Join(root, "devices", "pci0000:00", addr)
+		if err := os.MkdirAll(filepath.Join(dir, "drm", "card0"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{"device", "numa_node"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(files[name]), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		driverDir := filepath.Join(root, "bus", "pci", "drivers", files["driver"])
+		if err := os.MkdirAll(driverDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Symlink(driverDir, filepath.Join(dir, "driver")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.MkdirAll(filepath.Join(root, "bus", "pci", "devices"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Symlink(dir, filepath.Join(root, "bus", "pci", "devices", addr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "dev", "char"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	card := filepath.Join(root, "devices", "pci0000:00", "0000:00:02.0", "drm", "card0")
+	if err := os.Symlink(card, filepath.Join(root, "dev", "char", "1:3")); err != nil {
+		t.Fatal(err)
+	}
+
+	return root
+}
+
+func namingTree() DeviceTree {
+	tree := NewDeviceTree()
+	tree.AddDevice("i915", "card0-0", NewDeviceInfo(pluginapi.Healthy, []pluginapi.DeviceSpec{{
+		HostPath:      "/dev/null",
+		ContainerPath: "/dev/dri/card0",
+		Permissions:   "rw",
+	}}, nil, nil, nil, nil))
+	tree.AddDevice("i915", "card1-0", NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil))
+	tree.AddDevice("xe", "card1-0", NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil))
+	tree.AddDevice("qat", "0000:00:03.0", NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil))
+	vf := NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil)
+	vf.SetAttribute(AttributeDriver, StringAttribute("vfio-pci"))
+	vf.SetAttribute(AttributePCIDeviceID, StringAttribute("0x4941"))
+	vf.SetAttribute(AttributeTiles, IntAttribute(2))
+	tree.AddDevice("qat", "0000:00:04.0", vf)
+	tree.AddDevice("dsa", "wq0", NewDeviceInfoWithTopologyHints(pluginapi.Healthy, nil, nil, nil, nil,
+		&pluginapi.TopologyInfo{Nodes: []*pluginapi.NUMANode{{ID: 0}}}, nil))
+
+	return tree
+}
+
+func treeDevices(tree DeviceTree) []string {
+	devices := []string{}
+
+	for devType, infos := range tree {
+		for id := range infos {
+			devices = append(devices, devType+"/"+id)
+		}
+	}
+
+	sort.Strings(devices)
+
+	return devices
+}
+
+func TestResourceRules(t *testing.T) {
+	tcases := []struct {
+		name     string
+		rules    string
+		expected []string
+	}{
+		{
+			name:  "split by PCI ID",
+			rules: "- rename: i915-flex170\n  match:\n    type: i915\n    pciIDs: [0x56A0]\n",
+			expected: []string{"dsa/wq0", "i915-flex170/card0-0", "i915/card1-0",
+				"qat/0000:00:03.0", "qat/0000:00:04.0", "xe/card1-0"},
+		},
+		{
+			name:  "driver of the device ID",
+			rules: "- rename: legacy\n  match:\n    drivers: [xe]\n",
+			expected: []string{"dsa/wq0", "i915/card0-0", "i915/card1-0",
+				"legacy/0000:00:03.0", "qat/0000:00:04.0", "xe/card1-0"},
+		},
+		{
+			name:  "NUMA node from sysfs and topology",
+			rules: "- rename: numa0\n  match:\n    numaNodes: [0]\n",
+			expected: []string{"i915/card0-0", "i915/card1-0", "numa0/0000:00:03.0",
+				"numa0/wq0", "qat/0000:00:04.0", "xe/card1-0"},
+		},
+		{
+			name:  "merge types",
+			rules: "- rename: accel\n  match:\n    type: qat\n- rename: accel\n  match:\n    type: dsa\n",
+			expected: []string{"accel/0000:00:03.0", "accel/0000:00:04.0", "accel/wq0",
+				"i915/card0-0", "i915/card1-0", "xe/card1-0"},
+		},
+		{
+			name:  "merge with duplicate IDs",
+			rules: `[{"rename": "gpu", "match": {"type": "i915"}}, {"rename": "gpu", "match": {"type": "xe"}}]`,
+			expected: []string{"dsa/wq0", "gpu/card0-0", "gpu/card1-0",
+				"qat/0000:00:03.0", "qat/0000:00:04.0"},
+		},
+		{
+			name:  "first matching rule",
+			rules: "- rename: numa1\n  match:\n    type: i915\n    numaNodes: [1]\n- rename: gpu\n  match:\n    type: i915\n",
+			expected: []string{"dsa/wq0", "gpu/card1-0", "numa1/card0-0",
+				"qat/0000:00:03.0", "qat/0000:00:04.0", "xe/card1-0"},
+		},
+		{
+			name:  "PCI ID and driver from attributes",
+			rules: "- rename: vf\n  match:\n    pciIDs: [0x4941]\n    drivers: [vfio-pci]\n",
+			expected: []string{"dsa/wq0", "i915/card0-0", "i915/card1-0",
+				"qat/0000:00:03.0", "vf/0000:00:04.0", "xe/card1-0"},
+		},
+		{
+			name:  "attribute values",
+			rules: "- rename: dual\n  match:\n    attributes:\n      tiles: 2\n- rename: none\n  match:\n    attributes:\n      tiles: 1\n",
+			expected: []string{"dsa/wq0", "dual/0000:00:04.0", "i915/card0-0", "i915/card1-0",
+				"qat/0000:00:03.0", "xe/card1-0"},
+		},
+		{
+			name:  "all fields must match",
+			rules: "- rename: none\n  match:\n    pciIDs: [0x56a0]\n    drivers: [xe]\n",
+			expected: []string{"dsa/wq0", "i915/card0-0", "i915/card1-0",
+				"qat/0000:00:03.0", "qat/0000:00:04.0", "xe/card1-0"},
+		},
+	}
+
+	sysfs := createNamingSysfs(t)
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "rules.yaml")
+			if err := os.WriteFile(file, []byte(tc.rules), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			rules, err := loadResourceRules(file)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			recorder := &treeRecorder{}
+			rules.sysfsDir = sysfs
+			rules.next = recorder
+
+			rules.Notify(namingTree())
+
+			if devices := treeDevices(recorder.trees[0]); !reflect.DeepEqual(devices, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, devices)
+			}
+		})
+	}
+}
+
+func TestLoadResourceRulesInvalid(t *testing.T) {
+	tcases := map[string]string{
+		"invalid name":     "- rename: gpu.intel.com/i915\n",
+		"missing name":     "- match:\n    type: i915\n",
+		"invalid PCI ID":   "- rename: gpu\n  match:\n    pciIDs: [0x56a]\n",
+		"empty driver":     "- rename: gpu\n  match:\n    drivers: ['']\n",
+		"negative NUMA":    "- rename: gpu\n  match:\n    numaNodes: [-1]\n",
+		"unknown field":    "- rename: gpu\n  match:\n    vendor: 0x8086\n",
+		"not a list":       "rename: gpu\n",
+		"invalid document": "- rename: [gpu\n",
+	}
+
+	for name, content := range tcases {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "rules.yaml")
+			if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := loadResourceRules(file); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := loadResourceRules(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"k8s.io/klog/v2"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+const (
+	// KubeletPodResourcesSocket is the default socket of the kubelet PodResources API.
+	KubeletPodResourcesSocket = "/var/lib/kubelet/pod-resources/kubelet.sock"
+
+	podResourcesTimeout = 10 * time.Second
+	// Device owners older than this are refreshed when queried.
+	podResourcesMaxAge = 2 * time.Second
+
+	deviceOwnersPath = "/debug/device-owners"
+)
+
+var podResourcesSocket = flag.String("pod-resources-socket", "",
+	"kubelet PodResources API socket (e.g. "+KubeletPodResourcesSocket+") for mapping allocated devices to pods. Not used when empty")
+
+// DeviceOwner identifies the container a device is allocated to.
+type DeviceOwner struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+}
+
+// DeviceOwners maps the devices allocated by kubelet to the containers
+// holding them. The mapping is read from the kubelet PodResources API.
+type DeviceOwners struct {
+	updated time.Time
+	client  podresourcesapi.PodResourcesListerClient
+	conn    *grpc.ClientConn
+	// Resource name -> device ID -> owner.
+	owners    map[string]map[string]DeviceOwner
+	namespace string
+	mutex     sync.Mutex
+}
+
+// NewDeviceOwners creates DeviceOwners for the resources of the namespace,
+// e.g. "gpu.intel.com", using the PodResources API at socket.
+func NewDeviceOwners(socket, namespace string) (*DeviceOwners, error) {
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create PodResources client for %s", socket)
+	}
+
+	return &DeviceOwners{
+		client:    podresourcesapi.NewPodResourcesListerClient(conn),
+		conn:      conn,
+		namespace: namespace,
+		owners:    make(map[string]map[string]DeviceOwner),
+	}, nil
+}
+
+// Close closes the connection to kubelet.
+func (o *DeviceOwners) Close() error {
+	return errors.WithStack(o.conn.Close())
+}
+
+// Refresh reads the current device allocations from kubelet. The
+// allocations are listed without holding the lock, so that a slow kubelet
+// doesn't block the queries of the owners read earlier.
+func (o *DeviceOwners) Refresh(ctx context.Context) error {
+	started := time.Now()
+
+	owners, err := o.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	// A concurrent refresh started later has newer owners.
+	if started.After(o.updated) {
+		o.owners = owners
+		o.updated = started
+	}
+
+	return nil
+}
+
+func (o *DeviceOwners) list(ctx context.Context) (map[string]map[string]DeviceOwner, error) {
+	ctx, cancel := context.WithTimeout(ctx, podResourcesTimeout)
+	defer cancel()
+
+	resp, err := o.client.List(ctx, &podresourcesapi.ListPodResourcesRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pod resources")
+	}
+
+	owners := make(map[string]map[string]DeviceOwner)
+	prefix := o.namespace + "/"
+
+	for _, pod := range resp.GetPodResources() {
+		for _, container := range pod.GetContainers() {
+			owner := DeviceOwner{
+				Namespace: pod.GetNamespace(),
+				Pod:       pod.GetName(),
+				Container: container.GetName(),
+			}
+
+			for _, devices := range container.GetDevices() {
+				resource := devices.GetResourceName()
+				if !strings.HasPrefix(resource, prefix) {
+					continue
+				}
+
+				if _, ok := owners[resource]; !ok {
+					owners[resource] = make(map[string]DeviceOwner)
+				}
+
+				for _, id := range devices.GetDeviceIds() {
+					owners[resource][id] = owner
+				}
+			}
+		}
+	}
+
+	return owners, nil
+}
+
+func (o *DeviceOwners) refreshIfStale(ctx context.Context) error {
+	o.mutex.Lock()
+	stale := time.Since(o.updated) >= podResourcesMaxAge
+	o.mutex.Unlock()
+
+	if !stale {
+		return nil
+	}
+
+	return o.Refresh(ctx)
+}
+
+// Owners returns the owners of the allocated devices of the resource,
+// e.g. "gpu.intel.com/i915", keyed by device ID. The mapping is refreshed
+// from kubelet if it's older than a couple of seconds.
+func (o *DeviceOwners) Owners(ctx context.Context, resourceName string) (map[string]DeviceOwner, error) {
+	if err := o.refreshIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return maps.Clone(o.owners[resourceName]), nil
+}
+
+// ServeHTTP serves the owners of all allocated devices as JSON.
+func (o *DeviceOwners) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := o.refreshIfStale(r.Context())
+
+	o.mutex.Lock()
+	data, jsonErr := json.MarshalIndent(o.owners, "", "  ")
+	o.mutex.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+
+		return
+	}
+
+	if jsonErr != nil {
+		http.Error(w, jsonErr.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err = w.Write(data); err != nil {
+		klog.V(4).Infof("Failed to write device owners: %v", err)
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	podresourcesapi "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+type podResourcesStub struct {
+	podresourcesapi.UnimplementedPodResourcesListerServer
+	resp *podresourcesapi.ListPodResourcesResponse
+	// List calls wait for release when blocked.
+	release chan struct{}
+	calls   atomic.Int32
+	blocked atomic.Bool
+}
+
+func (s *podResourcesStub) List(context.Context, *podresourcesapi.ListPodResourcesRequest) (*podresourcesapi.ListPodResourcesResponse, error) {
+	s.calls.Add(1)
+
+	if s.blocked.Load() {
+		<-s.release
+	}
+
+	return s.resp, nil
+}
+
+func newPodResourcesStub(t *testing.T) (*podResourcesStub, string) {
+	t.Helper()
+
+	// Unix socket paths are limited in length, so don't use t.TempDir().
+	tmpDir, err := os.MkdirTemp("/tmp", "podresources")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	socket := filepath.Join(tmpDir, "kubelet.sock")
+
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub := &podResourcesStub{
+		release: make(chan struct{}),
+		resp: &podresourcesapi.ListPodResourcesResponse{
+			PodResources: []*podresourcesapi.PodResources{
+				{
+					Name:      "pod1",
+					Namespace: "default",
+					Containers: []*podresourcesapi.ContainerResources{
+						{
+							Name: "ctr1",
+							Devices: []*podresourcesapi.ContainerDevices{
+								{ResourceName: "gpu.intel.com/i915", DeviceIds: []string{"card1-3", "card0-0"}},
+								{ResourceName: "other.com/dev", DeviceIds: []string{"dev0"}},
+							},
+						},
+						{
+							Name: "ctr2",
+							Devices: []*podresourcesapi.ContainerDevices{
+								{ResourceName: "gpu.intel.com/xe", DeviceIds: []string{"card2-0"}},
+							},
+						},
+					},
+				},
+				{
+					Name:      "pod2",
+					Namespace: "ns2",
+					Containers: []*podresourcesapi.ContainerResources{
+						{Name: "ctr1"},
+					},
+				},
+			},
+		},
+	}
+
+	srv := grpc.NewServer()
+	podresourcesapi.RegisterPodResourcesListerServer(srv, stub)
+
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	t.Cleanup(func() {
+		srv.Stop()
+		os.RemoveAll(tmpDir)
+	})
+
+	return stub, socket
+}
+
+func TestDeviceOwners(t *testing.T) {
+	stub, socket := newPodResourcesStub(t)
+
+	owners, err := NewDeviceOwners(socket, "gpu.intel.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer owners.Close()
+
+	i915, err := owners.Owners(context.Background(), "gpu.intel.com/i915")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := DeviceOwner{Namespace: "default", Pod: "pod1", Container: "ctr1"}
+	if len(i915) != 2 || i915["card1-3"] != expected || i915["card0-0"] != expected {
+		t.Errorf("unexpected i915 owners: %+v", i915)
+	}
+
+	xe, err := owners.Owners(context.Background(), "gpu.intel.com/xe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if xe["card2-0"].Container != "ctr2" {
+		t.Errorf("unexpected xe owners: %+v", xe)
+	}
+
+	if other, _ := owners.Owners(context.Background(), "other.com/dev"); len(other) != 0 {
+		t.Errorf("resources of other namespaces should be ignored, got %+v", other)
+	}
+
+	// Fresh data is served from the cache.
+	if calls := stub.calls.Load(); calls != 1 {
+		t.Errorf("expected 1 List call, got %d", calls)
+	}
+
+	owners.updated = owners.updated.Add(-time.Minute)
+
+	if err = owners.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls := stub.calls.Load(); calls != 2 {
+		t.Errorf("expected 2 List calls, got %d", calls)
+	}
+}
+
+func TestDeviceOwnersSlowRefresh(t *testing.T) {
+	stub, socket := newPodResourcesStub(t)
+
+	owners, err := NewDeviceOwners(socket, "gpu.intel.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer owners.Close()
+
+	if err = owners.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stub.blocked.Store(true)
+
+	refreshed := make(chan error, 1)
+
+	go func() {
+		refreshed <- owners.Refresh(context.Background())
+	}()
+
+	for stub.calls.Load() != 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The owners read earlier are served while kubelet is slow to answer.
+	served := make(chan map[string]DeviceOwner, 1)
+
+	go func() {
+		i915, _ := owners.Owners(context.Background(), "gpu.intel.com/i915")
+		served <- i915
+	}()
+
+	select {
+	case i915 := <-served:
+		if len(i915) != 2 {
+			t.Errorf("unexpected i915 owners: %+v", i915)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("owners blocked by the refresh")
+	}
+
+	close(stub.release)
+
+	if err = <-refreshed; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeviceOwnersHandler(t *testing.T) {
+	_, socket := newPodResourcesStub(t)
+
+	owners, err := NewDeviceOwners(socket, "gpu.intel.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer owners.Close()
+
+	rec := httptest.NewRecorder()
+	owners.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, deviceOwnersPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var all map[string]map[string]DeviceOwner
+	if err = json.Unmarshal(rec.Body.Bytes(), &all); err != nil {
+		t.Fatal(err)
+	}
+
+	if all["gpu.intel.com/i915"]["card1-3"].Pod != "pod1" || len(all) != 2 {
+		t.Errorf("unexpected device owners: %+v", all)
+	}
+}
+
+func TestDeviceOwnersUnavailable(t *testing.T) {
+	owners, err := NewDeviceOwners("/tmp/nonexistent-podresources.sock", "gpu.intel.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer owners.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err = owners.Owners(ctx, "gpu.intel.com/i915"); err == nil {
+		t.Error("expected an error")
+	}
+
+	rec := httptest.NewRecorder()
+	owners.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, deviceOwnersPath, nil).WithContext(ctx))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+}
+
+type deviceOwnersUserStub struct {
+	devicePluginStub
+	owners *DeviceOwners
+}
+
+func (s *deviceOwnersUserStub) SetDeviceOwners(owners *DeviceOwners) {
+	s.owners = owners
+}
+
+func TestSetupDeviceOwners(t *testing.T) {
+	_, socket := newPodResourcesStub(t)
+
+	plugin := &deviceOwnersUserStub{}
+	m := &Manager{
+		devicePlugin:       plugin,
+		namespace:          "gpu.intel.com",
+		podResourcesSocket: socket,
+	}
+
+	if _, err := m.setupDeviceOwners(); err != nil {
+		t.Fatal(err)
+	}
+
+	if plugin.owners == nil {
+		t.Fatal("device owners not handed to the plugin")
+	}
+
+	defer plugin.owners.Close()
+
+	if owners, err := plugin.owners.Owners(context.Background(), "gpu.intel.com/i915"); err != nil || len(owners) != 2 {
+		t.Errorf("unexpected owners: %+v, %v", owners, err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+
+	"github.com/pkg/errors"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+	"sigs.k8s.io/yaml"
+	cdispec "tags.cncf.io/container-device-interface/specs-go"
+)
+
+// Output formats of the scan-and-print mode.
+const (
+	PrintFormatJSON = "json"
+	PrintFormatYAML = "yaml"
+)
+
+var scanAndPrint = flag.String("scan-and-print", "",
+	"run a single device scan, print the found devices in the given format (json or yaml) and exit without registering with kubelet")
+
+// printedDevice is the printed form of DeviceInfo.
+type printedDevice struct {
+	Topology    *pluginapi.TopologyInfo `json:"topology,omitempty"`
+	Envs        map[string]string       `json:"envs,omitempty"`
+	Annotations map[string]string       `json:"annotations,omitempty"`
+	Attributes  DeviceAttributes        `json:"attributes,omitempty"`
+	Health      string                  `json:"health"`
+	Reason      string                  `json:"reason,omitempty"`
+	Nodes       []pluginapi.DeviceSpec  `json:"nodes,omitempty"`
+	Mounts      []pluginapi.Mount       `json:"mounts,omitempty"`
+	CDISpecs    []*cdispec.Spec         `json:"cdiSpecs,omitempty"`
+}
+
+// firstTree is a Notifier keeping the first device tree it gets.
+type firstTree chan DeviceTree
+
+// Notify implements the Notifier interface.
+func (c firstTree) Notify(tree DeviceTree) {
+	select {
+	case c <- tree:
+	default:
+	}
+}
+
+// scanAndPrint runs the Scanner until it reports the devices for the first
+// time and writes them to w.
+func (m *Manager) scanAndPrint(ctx context.Context, w io.Writer) error {
+	if m.printFormat != PrintFormatJSON && m.printFormat != PrintFormatYAML {
+		return errors.Errorf("unsupported output format: %s", m.printFormat)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	trees := make(firstTree, 1)
+	scanErr := make(chan error, 1)
+
+	go func() {
+		scanErr <- m.devicePlugin.Scan(ctx, trees)
+	}()
+
+	var tree DeviceTree
+
+	select {
+	case tree = <-trees:
+		cancel()
+		waitForScan(scanErr)
+	case err := <-scanErr:
+		if err != nil {
+			return errors.Wrap(err, "device scan failed")
+		}
+
+		// The scan ended, it may still have reported the devices.
+		select {
+		case tree = <-trees:
+		default:
+			tree = NewDeviceTree()
+		}
+	case <-ctx.Done():
+		return nil
+	}
+
+	if m.resourceRulesFile != "" {
+		rules, err := loadResourceRules(m.resourceRulesFile)
+		if err != nil {
+			return err
+		}
+
+		tree = rules.apply(tree)
+	}
+
+	data, err := marshalDeviceTree(tree, m.printFormat)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return errors.WithStack(err)
+}
+
+// marshalDeviceTree returns tree in JSON or YAML.
+func marshalDeviceTree(tree DeviceTree, format string) ([]byte, error) {
+	printed := make(map[string]map[string]printedDevice)
+
+	for devType, devices := range tree {
+		printed[devType] = make(map[string]printedDevice)
+
+		for id, info := range devices {
+			printed[devType][id] = printedDevice{
+				Health:      info.state,
+				Reason:      info.healthReason,
+				Nodes:       info.nodes,
+				Mounts:      info.mounts,
+				Envs:        info.envs,
+				Annotations: info.annotations,
+				Attributes:  info.attributes,
+				Topology:    info.topology,
+				CDISpecs:    info.cdiSpecList(),
+			}
+		}
+	}
+
+	if format == PrintFormatYAML {
+		data, err := yaml.Marshal(printed)
+
+		return data, errors.WithStack(err)
+	}
+
+	data, err := json.MarshalIndent(printed, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return append(data, '\n'), nil
+}
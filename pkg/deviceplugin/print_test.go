@@ -0,0 +1,169 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// printScannerStub reports a device with a CDI spec and returns.
+type printScannerStub struct{}
+
+func (*printScannerStub) Scan(ctx context.Context, n Notifier) error {
+	info := testCdiDevice("dev1")
+	info.SetHealthReason("Testing")
+	info.state = pluginapi.Unhealthy
+	info.nodes = []pluginapi.DeviceSpec{{HostPath: "/dev/dev1", ContainerPath: "/dev/dev1", Permissions: "rw"}}
+
+	tree := NewDeviceTree()
+	tree.AddDevice("test", "dev1", info)
+	n.Notify(tree)
+
+	return nil
+}
+
+// emptyScannerStub returns without reporting any devices.
+type emptyScannerStub struct{}
+
+func (*emptyScannerStub) Scan(context.Context, Notifier) error {
+	return nil
+}
+
+func TestScanAndPrint(t *testing.T) {
+	tcases := []struct {
+		scanner     Scanner
+		name        string
+		format      string
+		expectedIDs []string
+		expectedErr bool
+	}{
+		{
+			name:        "json",
+			scanner:     &printScannerStub{},
+			format:      PrintFormatJSON,
+			expectedIDs: []string{"dev1"},
+		},
+		{
+			name:        "yaml",
+			scanner:     &printScannerStub{},
+			format:      PrintFormatYAML,
+			expectedIDs: []string{"dev1"},
+		},
+		{
+			name:        "scan keeps running",
+			scanner:     &blockingScannerStub{},
+			format:      PrintFormatJSON,
+			expectedIDs: []string{"dev1"},
+		},
+		{
+			name:    "no devices",
+			scanner: &emptyScannerStub{},
+			format:  PrintFormatYAML,
+		},
+		{
+			name:        "scan fails",
+			scanner:     &failingScannerStub{},
+			format:      PrintFormatJSON,
+			expectedErr: true,
+		},
+		{
+			name:        "unsupported format",
+			scanner:     &printScannerStub{},
+			format:      "xml",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mgr := NewManager("testnamespace", tc.scanner)
+			mgr.printFormat = tc.format
+
+			var out bytes.Buffer
+
+			err := mgr.scanAndPrint(context.Background(), &out)
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			data := out.Bytes()
+			if tc.format == PrintFormatYAML {
+				if data, err = yaml.YAMLToJSON(data); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var printed map[string]map[string]printedDevice
+			if err = json.Unmarshal(data, &printed); err != nil {
+				t.Fatalf("invalid output %q: %v", out.String(), err)
+			}
+
+			ids := []string{}
+			for _, devices := range printed {
+				for id := range devices {
+					ids = append(ids, id)
+				}
+			}
+
+			if len(ids) != len(tc.expectedIDs) || (len(ids) > 0 && ids[0] != tc.expectedIDs[0]) {
+				t.Errorf("expected devices %v, got %v", tc.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestMarshalDeviceTree(t *testing.T) {
+	scanned := make(firstTree, 1)
+	if err := (&printScannerStub{}).Scan(context.Background(), scanned); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := marshalDeviceTree(<-scanned, PrintFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var printed map[string]map[string]printedDevice
+	if err = json.Unmarshal(data, &printed); err != nil {
+		t.Fatal(err)
+	}
+
+	dev := printed["test"]["dev1"]
+	if dev.Health != pluginapi.Unhealthy || dev.Reason != "Testing" {
+		t.Errorf("unexpected health: %+v", dev)
+	}
+
+	if len(dev.Nodes) != 1 || dev.Nodes[0].HostPath != "/dev/dev1" {
+		t.Errorf("unexpected device nodes: %+v", dev.Nodes)
+	}
+
+	if len(dev.CDISpecs) != 1 || dev.CDISpecs[0].Devices[0].Name != "dev1" {
+		t.Errorf("unexpected CDI specs: %+v", dev.CDISpecs)
+	}
+}
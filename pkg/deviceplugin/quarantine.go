@@ -0,0 +1,254 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// QuarantineReason is the health reason of quarantined devices.
+const QuarantineReason = "Quarantined"
+
+var (
+	quarantineFile = flag.String("quarantine-file", "",
+		"file listing device types, device IDs or PCI addresses, one per line, to report unhealthy to kubelet")
+
+	pciAddressRegexp      = regexp.MustCompile(`[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]`)
+	shortPCIAddressRegexp = regexp.MustCompile(`^[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+)
+
+// quarantine is a Notifier passing the device trees to the next Notifier
+// with the devices listed in a file marked unhealthy. The last tree is
+// passed again when the file changes so that quarantined devices get
+// released without waiting for the Scanner.
+type quarantine struct {
+	next Notifier
+	// Last tree received from the Scanner.
+	tree DeviceTree
+	// Device types, device IDs and PCI addresses to quarantine.
+	entries map[string]struct{}
+	// Device type -> IDs of the quarantined devices.
+	quarantined map[string]map[string]struct{}
+	file        string
+	sysfsDir    string
+	mutex       sync.Mutex
+}
+
+func newQuarantine(file string) *quarantine {
+	return &quarantine{
+		file:        file,
+		sysfsDir:    "/sys",
+		entries:     make(map[string]struct{}),
+		quarantined: make(map[string]map[string]struct{}),
+	}
+}
+
+// Notify implements the Notifier interface.
+func (q *quarantine) Notify(tree DeviceTree) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.tree = tree
+	q.next.Notify(q.apply(tree))
+}
+
+// load reads the quarantine file and passes the last tree again when the
+// entries have changed. A missing file quarantines nothing.
+func (q *quarantine) load() error {
+	entries, err := readQuarantineFile(q.file)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if reflect.DeepEqual(entries, q.entries) {
+		return nil
+	}
+
+	klog.V(2).Infof("Quarantine entries: %v", entries)
+
+	q.entries = entries
+
+	if q.tree != nil {
+		q.next.Notify(q.apply(q.tree))
+	}
+
+	return nil
+}
+
+func readQuarantineFile(file string) (map[string]struct{}, error) {
+	entries := make(map[string]struct{})
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read quarantine file")
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		entry := strings.ToLower(strings.TrimSpace(line))
+		if shortPCIAddressRegexp.MatchString(entry) {
+			entry = "0000:" + entry
+		}
+
+		if entry != "" {
+			entries[entry] = struct{}{}
+		}
+	}
+
+	return entries, nil
+}
+
+// watch reloads the quarantine file when it changes until ctx is canceled.
+func (q *quarantine) watch(ctx context.Context) {
+	watchDir(ctx, q.file, func() {
+		if err := q.load(); err != nil {
+			klog.Warningf("Unable to reload quarantine file: %+v", err)
+		}
+	})
+}
+
+// apply returns a copy of tree with the quarantined devices marked unhealthy.
+func (q *quarantine) apply(tree DeviceTree) DeviceTree {
+	if len(q.entries) == 0 && len(q.quarantined) == 0 {
+		return tree
+	}
+
+	applied := NewDeviceTree()
+	quarantined := make(map[string]map[string]struct{})
+
+	for devType, devices := range tree {
+		for id, info := range devices {
+			if q.matches(devType, id, &info) {
+				if _, found := q.quarantined[devType][id]; !found {
+					klog.Warningf("Device %s/%s quarantined", devType, id)
+				}
+
+				if quarantined[devType] == nil {
+					quarantined[devType] = make(map[string]struct{})
+				}
+
+				quarantined[devType][id] = struct{}{}
+
+				info.state = pluginapi.Unhealthy
+				info.healthReason = QuarantineReason
+			}
+
+			applied.AddDevice(devType, id, info)
+		}
+	}
+
+	for devType, ids := range q.quarantined {
+		for id := range ids {
+			if _, found := quarantined[devType][id]; !found {
+				klog.Infof("Device %s/%s released from quarantine", devType, id)
+			}
+		}
+	}
+
+	q.quarantined = quarantined
+
+	return applied
+}
+
+func (q *quarantine) matches(devType, id string, info *DeviceInfo) bool {
+	for _, entry := range []string{devType, id} {
+		if _, found := q.entries[strings.ToLower(entry)]; found {
+			return true
+		}
+	}
+
+	if !q.hasPCIAddresses() {
+		return false
+	}
+
+	addrs := pciAddresses(q.sysfsDir, id, info)
+	if pf, found := info.attributes.StringValue(AttributePhysicalFunction); found {
+		addrs = append(addrs, pf)
+	}
+
+	for _, addr := range addrs {
+		if _, found := q.entries[strings.ToLower(addr)]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *quarantine) hasPCIAddresses() bool {
+	for entry := range q.entries {
+		if pciAddressRegexp.MatchString(entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pciAddressForNode returns the address of the PCI device a device node
+// belongs to using the sysfs mounted at sysfsDir. Only the device nodes
+// in /sys/dev/char have one, e.g. not the VFIO group nodes, so the PCI
+// address attribute of a device is preferred.
+func pciAddressForNode(sysfsDir, devNode string) (string, error) {
+	fi, err := os.Stat(devNode)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if fi.Mode()&os.ModeCharDevice == 0 {
+		return "", errors.Errorf("%s is not a character device", devNode)
+	}
+
+	rdev := fi.Sys().(*syscall.Stat_t).Rdev
+	link := filepath.Join(sysfsDir, "dev", "char", fmt.Sprintf("%d:%d", unix.Major(rdev), unix.Minor(rdev)))
+
+	path, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	// Bridges precede the device itself in the path.
+	addrs := pciAddressRegexp.FindAllString(path, -1)
+	if len(addrs) == 0 {
+		return "", errors.Errorf("%s is not a PCI device", path)
+	}
+
+	return addrs[len(addrs)-1], nil
+}
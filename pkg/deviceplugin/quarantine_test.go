@@ -0,0 +1,256 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func quarantineTree() DeviceTree {
+	tree := NewDeviceTree()
+	// /dev/null is used as a device node with a known device number (1:3).
+	tree.AddDevice("gpu", "card0-0", NewDeviceInfo(pluginapi.Healthy, []pluginapi.DeviceSpec{{
+		HostPath:      "/dev/null",
+		ContainerPath: "/dev/dri/card0",
+		Permissions:   "rw",
+	}}, nil, nil, nil, nil))
+	tree.AddDevice("gpu", "card1-0", NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil))
+	tree.AddDevice("qat", "0000:3d:01.0", NewDeviceInfo(pluginapi.Healthy, nil, nil, nil, nil, nil))
+
+	// VFIO group nodes have no PCI device in /sys/dev/char.
+	vf := NewDeviceInfo(pluginapi.Healthy, []pluginapi.DeviceSpec{{
+		HostPath:      "/dev/vfio/12",
+		ContainerPath: "/dev/vfio/12",
+		Permissions:   "rw",
+	}}, nil, nil, nil, nil)
+	vf.SetAttribute(AttributePCIAddress, StringAttribute("0000:4b:00.1"))
+	vf.SetAttribute(AttributePhysicalFunction, StringAttribute("0000:4b:00.0"))
+	tree.AddDevice("vfio", "12", vf)
+
+	return tree
+}
+
+func unhealthyDevices(tree DeviceTree) []string {
+	devices := []string{}
+
+	for devType, infos := range tree {
+		for id, info := range infos {
+			if info.state != pluginapi.Healthy {
+				devices = append(devices, devType+"/"+id)
+			}
+		}
+	}
+
+	sort.Strings(devices)
+
+	return devices
+}
+
+func newTestQuarantine(t *testing.T, content string) (*quarantine, *treeRecorder) {
+	t.Helper()
+
+	root := t.TempDir()
+
+	devDir := filepath.Join(root, "devices", "pci0000:00", "0000:00:02.0", "drm", "card0")
+	if err := os.MkdirAll(devDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	charDir := filepath.Join(root, "dev", "char")
+	if err := os.MkdirAll(charDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(devDir, filepath.Join(charDir, "1:3")); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(root, "quarantine")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &treeRecorder{}
+
+	q := newQuarantine(file)
+	q.sysfsDir = root
+	q.next = recorder
+
+	if err := q.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	return q, recorder
+}
+
+func TestQuarantine(t *testing.T) {
+	tcases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "empty file",
+			expected: []string{},
+		},
+		{
+			name:     "device type",
+			content:  "gpu\n",
+			expected: []string{"gpu/card0-0", "gpu/card1-0"},
+		},
+		{
+			name:     "device ID",
+			content:  "# broken fan\ncard1-0\n",
+			expected: []string{"gpu/card1-0"},
+		},
+		{
+			name:     "PCI address of a device node",
+			content:  "00:02.0 # short form\n",
+			expected: []string{"gpu/card0-0"},
+		},
+		{
+			name:     "PCI address as device ID",
+			content:  "0000:3D:01.0\n",
+			expected: []string{"qat/0000:3d:01.0"},
+		},
+		{
+			name:     "PCI address attribute",
+			content:  "4b:00.1\n",
+			expected: []string{"vfio/12"},
+		},
+		{
+			name:     "physical function attribute",
+			content:  "0000:4b:00.0\n",
+			expected: []string{"vfio/12"},
+		},
+		{
+			name:     "unknown devices",
+			content:  "card7\n0000:01:00.0\n",
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, recorder := newTestQuarantine(t, tc.content)
+
+			q.Notify(quarantineTree())
+
+			if len(recorder.trees) != 1 {
+				t.Fatalf("expected one tree, got %d", len(recorder.trees))
+			}
+
+			if devices := unhealthyDevices(recorder.trees[0]); !reflect.DeepEqual(devices, tc.expected) {
+				t.Errorf("expected %v to be quarantined, got %v", tc.expected, devices)
+			}
+
+			for _, devType := range recorder.trees[0] {
+				for id, info := range devType {
+					if info.state != pluginapi.Healthy && info.healthReason != QuarantineReason {
+						t.Errorf("unexpected health reason of %s: %q", id, info.healthReason)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestQuarantineReload(t *testing.T) {
+	q, recorder := newTestQuarantine(t, "card1-0\n")
+
+	// Nothing to pass on before the Scanner has found any devices.
+	if err := os.WriteFile(q.file, []byte("card0-0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	q.Notify(quarantineTree())
+
+	if err := os.Remove(q.file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged entries don't pass the tree again.
+	if err := q.load(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{{"gpu/card0-0"}, {}}
+	devices := [][]string{}
+
+	for _, tree := range recorder.trees {
+		devices = append(devices, unhealthyDevices(tree))
+	}
+
+	if !reflect.DeepEqual(devices, expected) {
+		t.Errorf("expected %v to be quarantined, got %v", expected, devices)
+	}
+}
+
+func TestQuarantineWatch(t *testing.T) {
+	q, _ := newTestQuarantine(t, "")
+
+	updates := make(chan DeviceTree, 10)
+	q.next = notifierFunc(func(tree DeviceTree) { updates <- tree })
+
+	q.Notify(quarantineTree())
+	<-updates
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go q.watch(ctx)
+
+	// Wait for the watch to start.
+	for i := 0; ; i++ {
+		if err := os.WriteFile(q.file, []byte("card1-0\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case tree := <-updates:
+			if devices := unhealthyDevices(tree); !reflect.DeepEqual(devices, []string{"gpu/card1-0"}) {
+				t.Errorf("unexpected quarantined devices: %v", devices)
+			}
+
+			return
+		case <-time.After(100 * time.Millisecond):
+			if i == 20 {
+				t.Fatal("quarantine file change not noticed")
+			}
+		}
+	}
+}
+
+type notifierFunc func(DeviceTree)
+
+func (f notifierFunc) Notify(tree DeviceTree) {
+	f(tree)
+}
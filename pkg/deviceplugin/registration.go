@@ -0,0 +1,180 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Registration states of the resources.
+const (
+	// RegistrationPending means that the resource is not registered with
+	// kubelet yet or its registration failed and is retried.
+	RegistrationPending = "Pending"
+	// RegistrationRegistered means that the resource is registered, but
+	// kubelet has not called ListAndWatch yet.
+	RegistrationRegistered = "Registered"
+	// RegistrationWatched means that kubelet is watching the devices.
+	RegistrationWatched = "Watched"
+)
+
+const (
+	registrationHealthPath = "/healthz"
+
+	// Initial and maximum delays between failed registrations.
+	registerBackoff    = time.Second
+	maxRegisterBackoff = 30 * time.Second
+	// Time kubelet has for calling ListAndWatch after a registration
+	// before the resource is registered again.
+	listAndWatchTimeout = 30 * time.Second
+	// Time a resource may go without being watched by kubelet before the
+	// liveness probe fails.
+	maxUnwatched = 2 * time.Minute
+)
+
+var healthProbeBindAddress = flag.String("health-probe-bind-address", "",
+	"address (e.g. :8082) for serving the kubelet registration states of the resources at "+registrationHealthPath+
+		" for liveness probes. Not served when empty")
+
+// registrationStatus is the registration state of a resource.
+type registrationStatus struct {
+	// Time of the latest state change.
+	Since time.Time `json:"since"`
+	// Time since kubelet is not watching the devices.
+	unwatchedSince time.Time
+	State          string `json:"state"`
+	// Error of the latest failed registration.
+	Error         string `json:"error,omitempty"`
+	Registrations int    `json:"registrations"`
+}
+
+// registrationTracker keeps the registration states of the resources.
+type registrationTracker struct {
+	statuses map[string]*registrationStatus
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+// kubeletRegistrations has the registration states of the resources served
+// by the process.
+var kubeletRegistrations = newRegistrationTracker()
+
+func newRegistrationTracker() *registrationTracker {
+	return &registrationTracker{
+		statuses: make(map[string]*registrationStatus),
+		now:      time.Now,
+	}
+}
+
+// set records the registration state of a resource and err of a failed
+// registration.
+func (r *registrationTracker) set(devType, state string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+
+	status, found := r.statuses[devType]
+	if !found {
+		status = &registrationStatus{State: RegistrationPending, Since: now, unwatchedSince: now}
+		r.statuses[devType] = status
+	}
+
+	if status.State != state {
+		status.Since = now
+
+		if status.State == RegistrationWatched {
+			status.unwatchedSince = now
+		}
+	}
+
+	if state == RegistrationRegistered {
+		status.Registrations++
+	}
+
+	status.State = state
+	status.Error = ""
+
+	if err != nil {
+		status.Error = err.Error()
+	}
+}
+
+// forget removes the state of a resource that is no longer served.
+func (r *registrationTracker) forget(devType string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.statuses, devType)
+}
+
+// healthy tells if kubelet is watching the devices of all resources or has
+// not been doing it only for a short while.
+func (r *registrationTracker) healthy() bool {
+	now := r.now()
+
+	for _, status := range r.statuses {
+		if status.State != RegistrationWatched && now.Sub(status.unwatchedSince) > maxUnwatched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ServeHTTP serves the registration states of all resources as JSON. The
+// status is 503 Service Unavailable when a resource has not been watched
+// by kubelet for a while.
+func (r *registrationTracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	healthy := r.healthy()
+	data, err := json.MarshalIndent(r.statuses, "", "  ")
+	r.mutex.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if _, err = w.Write(data); err != nil {
+		klog.V(4).Infof("Failed to write registration states: %v", err)
+	}
+}
+
+// serveHealthProbe serves the registration states at addr until ctx is canceled.
+func serveHealthProbe(ctx context.Context, addr string, registrations http.Handler) {
+	mux := http.NewServeMux()
+	mux.Handle(registrationHealthPath, registrations)
+
+	klog.V(1).Infof("Serving health probe at %s%s", addr, registrationHealthPath)
+
+	if err := serveHTTP(ctx, addr, mux); err != nil {
+		klog.Errorf("Health probe server failed: %+v", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestRegistrationTracker(t *testing.T) {
+	now := time.Now()
+
+	r := newRegistrationTracker()
+	r.now = func() time.Time { return now }
+
+	check := func(expectedCode int, expected map[string]registrationStatus) {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, registrationHealthPath, nil))
+
+		if rec.Code != expectedCode {
+			t.Errorf("expected status %d, got %d: %s", expectedCode, rec.Code, rec.Body.String())
+		}
+
+		var statuses map[string]registrationStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &statuses); err != nil {
+			t.Fatal(err)
+		}
+
+		for devType, status := range statuses {
+			if e := expected[devType]; status.State != e.State || status.Error != e.Error || status.Registrations != e.Registrations {
+				t.Errorf("expected %s status %+v, got %+v", devType, e, status)
+			}
+		}
+
+		if len(statuses) != len(expected) {
+			t.Errorf("expected %d statuses, got %+v", len(expected), statuses)
+		}
+	}
+
+	r.set("gpu", RegistrationPending, errors.New("kubelet not running"))
+	check(http.StatusOK, map[string]registrationStatus{
+		"gpu": {State: RegistrationPending, Error: "kubelet not running"},
+	})
+
+	now = now.Add(maxUnwatched + time.Second)
+	r.set("gpu", RegistrationRegistered, nil)
+	check(http.StatusServiceUnavailable, map[string]registrationStatus{
+		"gpu": {State: RegistrationRegistered, Registrations: 1},
+	})
+
+	r.set("gpu", RegistrationWatched, nil)
+	r.set("vpu", RegistrationRegistered, nil)
+	check(http.StatusOK, map[string]registrationStatus{
+		"gpu": {State: RegistrationWatched, Registrations: 1},
+		"vpu": {State: RegistrationRegistered, Registrations: 1},
+	})
+
+	// Unwatched time starts from the end of the watch.
+	now = now.Add(maxUnwatched)
+	r.set("gpu", RegistrationPending, nil)
+	r.set("vpu", RegistrationWatched, nil)
+	now = now.Add(time.Minute)
+	check(http.StatusOK, map[string]registrationStatus{
+		"gpu": {State: RegistrationPending, Registrations: 1},
+		"vpu": {State: RegistrationWatched, Registrations: 1},
+	})
+
+	r.forget("gpu")
+	r.forget("vpu")
+	check(http.StatusOK, map[string]registrationStatus{})
+}
+
+// waitForRegistration returns the registration state of the test server
+// once cond is true for it.
+func waitForRegistration(t *testing.T, cond func(*registrationStatus) bool) registrationStatus {
+	t.Helper()
+
+	for range 200 {
+		kubeletRegistrations.mutex.Lock()
+		status, found := kubeletRegistrations.statuses["testtype"]
+
+		if found && cond(status) {
+			defer kubeletRegistrations.mutex.Unlock()
+
+			return *status
+		}
+		kubeletRegistrations.mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatal("registration state not reached")
+
+	return registrationStatus{}
+}
+
+func TestRegisterAndWatch(t *testing.T) {
+	dir, err := os.MkdirTemp("/tmp", "registration-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	kubeletSock := filepath.Join(dir, "kubelet.sock")
+
+	srv := newTestServer()
+	srv.listAndWatchTimeout = 200 * time.Millisecond
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- srv.setupAndServe(namespace, dir, kubeletSock)
+	}()
+
+	// Kubelet is not running yet.
+	waitForRegistration(t, func(status *registrationStatus) bool {
+		return status.State == RegistrationPending && status.Error != ""
+	})
+
+	// Kubelet gets started, but never calls ListAndWatch.
+	kubelet := newKubeletStub(kubeletSock)
+	if err = kubelet.start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer kubelet.server.Stop()
+
+	waitForRegistration(t, func(status *registrationStatus) bool {
+		return status.Registrations >= 2
+	})
+
+	conn, err := grpc.NewClient("unix://"+filepath.Join(dir, namespace+"-testtype.sock"),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	stream, err := pluginapi.NewDevicePluginClient(conn).ListAndWatch(context.Background(), &pluginapi.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	watched := waitForRegistration(t, func(status *registrationStatus) bool {
+		return status.State == RegistrationWatched
+	})
+
+	time.Sleep(2 * srv.listAndWatchTimeout)
+
+	if status := waitForRegistration(t, func(*registrationStatus) bool { return true }); status.Registrations != watched.Registrations {
+		t.Errorf("registered again while watched: %+v", status)
+	}
+
+	if err = srv.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err = <-served:
+		if err != nil {
+			t.Errorf("unexpected serve error: %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server not stopped")
+	}
+
+	kubeletRegistrations.mutex.Lock()
+	defer kubeletRegistrations.mutex.Unlock()
+
+	if _, found := kubeletRegistrations.statuses["testtype"]; found {
+		t.Error("registration state not forgotten")
+	}
+}
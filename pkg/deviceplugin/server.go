@@ -22,6 +22,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -55,7 +56,7 @@ const (
 // pluginapi.PluginInterfaceServer interfaces.
 // This internal unexposed interface simplifies unit testing.
 type devicePluginServer interface {
-	Serve(namespace string) error
+	Serve(namespace, devicePluginPath string) error
 	Stop() error
 	Update(devices map[string]DeviceInfo)
 }
@@ -70,18 +71,33 @@ type server struct {
 	postAllocate           postAllocateFunc
 	preStartContainer      preStartContainerFunc
 	getPreferredAllocation getPreferredAllocationFunc
-	devType                string
-	cdiDir                 string
-	state                  serverState
-	stateMutex             sync.Mutex
+	cdiSpecs               *cdiSpecs
+	events                 *nodeEvents
+	// watched receives a value when kubelet calls ListAndWatch.
+	watched chan struct{}
+	// done is closed when the server is stopped.
+	done    chan struct{}
+	devType string
+	socket  string
+	// Initial delay between failed registrations and the time kubelet has
+	// for calling ListAndWatch after a registration.
+	registerBackoff     time.Duration
+	listAndWatchTimeout time.Duration
+	// Number of successful registrations.
+	registrations int
+	state         serverState
+	// stateMutex protects state, grpcServer and socket.
+	stateMutex sync.Mutex
 }
 
 // newServer creates a new server satisfying the devicePluginServer interface.
 func newServer(devType string,
+	cdiSpecs *cdiSpecs,
 	postAllocate postAllocateFunc,
 	preStartContainer preStartContainerFunc,
 	getPreferredAllocation getPreferredAllocationFunc,
-	allocate allocateFunc) devicePluginServer {
+	allocate allocateFunc,
+	events *nodeEvents) devicePluginServer {
 	return &server{
 		devType:                devType,
 		updatesCh:              make(chan map[string]DeviceInfo, 1), // TODO: is 1 needed?
@@ -90,8 +106,13 @@ func newServer(devType string,
 		postAllocate:           postAllocate,
 		preStartContainer:      preStartContainer,
 		getPreferredAllocation: getPreferredAllocation,
+		cdiSpecs:               cdiSpecs,
+		events:                 events,
+		watched:                make(chan struct{}, 1),
+		done:                   make(chan struct{}),
+		registerBackoff:        registerBackoff,
+		listAndWatchTimeout:    listAndWatchTimeout,
 		state:                  uninitialized,
-		cdiDir:                 CDIDir,
 	}
 }
 
@@ -129,6 +150,11 @@ func (srv *server) sendDevices(stream pluginapi.DevicePlugin_ListAndWatchServer)
 func (srv *server) ListAndWatch(empty *pluginapi.Empty, stream pluginapi.DevicePlugin_ListAndWatchServer) error {
 	klog.V(4).Info("Started ListAndWatch for", srv.devType)
 
+	select {
+	case srv.watched <- struct{}{}:
+	default:
+	}
+
 	if err := srv.sendDevices(stream); err != nil {
 		return err
 	}
@@ -143,68 +169,187 @@ func (srv *server) ListAndWatch(empty *pluginapi.Empty, stream pluginapi.DeviceP
 }
 
 func (srv *server) Allocate(ctx context.Context, rqt *pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
+	start := time.Now()
+
+	ctx, span := startSpan(ctx, spanAllocate, allocateAttributes(srv.devType, rqt)...)
+	response, err := srv.allocateDevices(ctx, rqt)
+
+	endSpan(span, err)
+	observeRequest(srv.devType, methodAllocate, start, err)
+
+	if err != nil {
+		srv.events.allocationFailed(srv.devType, err)
+	}
+
+	return response, err
+}
+
+func (srv *server) allocateDevices(ctx context.Context, rqt *pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
 	if srv.allocate != nil {
+		_, span := startSpan(ctx, spanPluginAllocate, allocateAttributes(srv.devType, rqt)...)
 		response, err := srv.allocate(rqt)
 
 		if _, ok := err.(*UseDefaultMethodError); !ok {
+			endSpan(span, err)
+
 			return response, err
 		}
+
+		span.End()
 	}
 
 	response := new(pluginapi.AllocateResponse)
 
 	for _, crqt := range rqt.ContainerRequests {
-		cresp := new(pluginapi.ContainerAllocateResponse)
+		var (
+			cresp *pluginapi.ContainerAllocateResponse
+			err   error
+		)
 
-		cresp.Envs = map[string]string{}
-		cresp.Annotations = map[string]string{}
-		cresp.CdiDevices = []*pluginapi.CDIDevice{}
+		if srv.cdiSpecs.isStatic() {
+			cresp, err = allocateCdiReferences(srv.devType, srv.devices, crqt.DevicesIds, srv.cdiSpecs.staticKind)
+		} else {
+			cresp, err = allocateContainer(ctx, srv.devices, crqt.DevicesIds, srv.cdiSpecs)
+		}
 
-		for _, id := range crqt.DevicesIds {
-			dev, ok := srv.devices[id]
-			if !ok {
-				return nil, errors.Errorf("Invalid allocation request with non-existing device %s", id)
-			}
+		if err != nil {
+			return nil, err
+		}
 
-			if dev.state != pluginapi.Healthy {
-				return nil, errors.Errorf("Invalid allocation request with unhealthy device %s", id)
-			}
+		response.ContainerResponses = append(response.ContainerResponses, cresp)
+	}
 
-			for i := range dev.nodes {
-				cresp.Devices = append(cresp.Devices, &dev.nodes[i])
-			}
+	if srv.postAllocate != nil {
+		_, span := startSpan(ctx, spanPostAllocate,
+			attrResource.String(srv.devType), attrContainers.Int(len(response.ContainerResponses)))
+		err := srv.postAllocate(response)
 
-			for i := range dev.mounts {
-				cresp.Mounts = append(cresp.Mounts, &dev.mounts[i])
-			}
+		endSpan(span, err)
 
-			maps.Copy(cresp.Envs, dev.envs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// allocateContainer collects device nodes, mounts, envs, annotations and CDI devices
+// of the given devices into a single container allocation response.
+func allocateContainer(ctx context.Context, devices map[string]DeviceInfo, ids []string, cdiSpecs *cdiSpecs) (*pluginapi.ContainerAllocateResponse, error) {
+	cresp := new(pluginapi.ContainerAllocateResponse)
+
+	cresp.Envs = map[string]string{}
+	cresp.Annotations = map[string]string{}
+	cresp.CdiDevices = []*pluginapi.CDIDevice{}
+
+	for _, id := range ids {
+		dev, err := allocatableDevice(devices, id)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range dev.nodes {
+			cresp.Devices = append(cresp.Devices, &dev.nodes[i])
+		}
+
+		for i := range dev.mounts {
+			cresp.Mounts = append(cresp.Mounts, &dev.mounts[i])
+		}
 
-			maps.Copy(cresp.Annotations, dev.annotations)
+		maps.Copy(cresp.Envs, dev.envs)
 
-			if names, err := writeCdiSpecToFilesystem(dev.cdiSpec, srv.cdiDir); err == nil {
-				cresp.CdiDevices = append(cresp.CdiDevices, names...)
-			} else {
+		maps.Copy(cresp.Annotations, dev.annotations)
+
+		for _, spec := range dev.cdiSpecList() {
+			names, err := cdiSpecs.write(ctx, spec)
+			if err != nil {
 				klog.Errorf("CDI spec write failed: %+v", err)
+
+				continue
 			}
-		}
 
-		response.ContainerResponses = append(response.ContainerResponses, cresp)
+			cresp.CdiDevices = appendCdiDevices(cresp.CdiDevices, names)
+		}
 	}
 
-	if srv.postAllocate != nil {
-		err := srv.postAllocate(response)
+	return cresp, nil
+}
+
+// allocateCdiReferences collects the static CDI devices, envs and annotations
+// of the given devices into a container allocation response. The static CDI
+// specs hold the device nodes and mounts.
+func allocateCdiReferences(devType string, devices map[string]DeviceInfo, ids []string, kind string) (*pluginapi.ContainerAllocateResponse, error) {
+	cresp := new(pluginapi.ContainerAllocateResponse)
+
+	cresp.Envs = map[string]string{}
+	cresp.Annotations = map[string]string{}
+	cresp.CdiDevices = []*pluginapi.CDIDevice{}
+
+	for _, id := range ids {
+		dev, err := allocatableDevice(devices, id)
 		if err != nil {
 			return nil, err
 		}
+
+		maps.Copy(cresp.Envs, dev.envs)
+		maps.Copy(cresp.Annotations, dev.annotations)
+
+		names := []*pluginapi.CDIDevice{}
+
+		if cdiDev, ok := staticCdiDevice(devType, id, &dev); ok {
+			names = append(names, &pluginapi.CDIDevice{Name: kind + "=" + cdiDev.Name})
+		}
+
+		for _, spec := range dev.cdiSpecList() {
+			for _, cdiDev := range spec.Devices {
+				names = append(names, &pluginapi.CDIDevice{Name: spec.Kind + "=" + cdiDev.Name})
+			}
+		}
+
+		cresp.CdiDevices = appendCdiDevices(cresp.CdiDevices, names)
 	}
 
-	return response, nil
+	return cresp, nil
+}
+
+func allocatableDevice(devices map[string]DeviceInfo, id string) (DeviceInfo, error) {
+	dev, ok := devices[id]
+	if !ok {
+		return dev, errors.Errorf("Invalid allocation request with non-existing device %s", id)
+	}
+
+	if dev.state != pluginapi.Healthy {
+		return dev, errors.Errorf("Invalid allocation request with unhealthy device %s", id)
+	}
+
+	return dev, nil
+}
+
+// appendCdiDevices appends the CDI devices not yet in the list. Devices
+// sharing a CDI spec refer to the same CDI devices.
+func appendCdiDevices(devices, names []*pluginapi.CDIDevice) []*pluginapi.CDIDevice {
+	for _, name := range names {
+		if !slices.ContainsFunc(devices, func(dev *pluginapi.CDIDevice) bool { return dev.Name == name.Name }) {
+			devices = append(devices, name)
+		}
+	}
+
+	return devices
 }
 
 func (srv *server) PreStartContainer(ctx context.Context, rqt *pluginapi.PreStartContainerRequest) (*pluginapi.PreStartContainerResponse, error) {
 	if srv.preStartContainer != nil {
-		return new(pluginapi.PreStartContainerResponse), srv.preStartContainer(rqt)
+		start := time.Now()
+
+		_, span := startSpan(ctx, spanPreStartContainer,
+			attrResource.String(srv.devType), attrDevices.Int(len(rqt.GetDevicesIds())))
+		err := srv.preStartContainer(rqt)
+
+		endSpan(span, err)
+		observeRequest(srv.devType, methodPreStartContainer, start, err)
+
+		return new(pluginapi.PreStartContainerResponse), err
 	}
 
 	return nil, errors.New("PreStartContainer() should not be called as this device plugin doesn't implement it")
@@ -212,39 +357,68 @@ func (srv *server) PreStartContainer(ctx context.Context, rqt *pluginapi.PreStar
 
 func (srv *server) GetPreferredAllocation(ctx context.Context, rqt *pluginapi.PreferredAllocationRequest) (*pluginapi.PreferredAllocationResponse, error) {
 	if srv.getPreferredAllocation != nil {
-		return srv.getPreferredAllocation(rqt)
+		start := time.Now()
+
+		_, span := startSpan(ctx, spanGetPreferredAllocation,
+			attrResource.String(srv.devType), attrContainers.Int(len(rqt.GetContainerRequests())))
+		response, err := srv.getPreferredAllocation(rqt, srv.devices)
+
+		endSpan(span, err)
+		observeRequest(srv.devType, methodGetPreferredAllocation, start, err)
+
+		return response, err
 	}
 
 	return nil, errors.New("GetPreferredAllocation should not be called as this device plugin doesn't implement it")
 }
 
 // Serve starts a gRPC server to serve pluginapi.PluginInterfaceServer interface.
-func (srv *server) Serve(namespace string) error {
-	return srv.setupAndServe(namespace, pluginapi.DevicePluginPath, pluginapi.KubeletSocket)
+// The plugin socket is created in devicePluginPath and registered with the
+// kubelet socket found there.
+func (srv *server) Serve(namespace, devicePluginPath string) error {
+	return srv.setupAndServe(namespace, devicePluginPath, path.Join(devicePluginPath, path.Base(pluginapi.KubeletSocket)))
 }
 
-// Stop stops serving pluginapi.PluginInterfaceServer interface.
+// Stop stops serving pluginapi.PluginInterfaceServer interface and removes
+// the plugin socket.
 func (srv *server) Stop() error {
-	if srv.grpcServer == nil {
+	srv.stateMutex.Lock()
+	grpcServer, socket := srv.grpcServer, srv.socket
+	stopped := srv.state == terminating
+	srv.state = terminating
+
+	if !stopped && srv.done != nil {
+		close(srv.done)
+	}
+	srv.stateMutex.Unlock()
+
+	if grpcServer == nil {
 		return errors.New("Can't stop non-existing gRPC server. Calling Stop() before Serve()?")
 	}
 
-	srv.setState(terminating)
-	srv.grpcServer.Stop()
+	if stopped {
+		return nil
+	}
+
+	grpcServer.Stop()
 	close(srv.updatesCh)
 
+	// Closing the listener normally removes the socket already.
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		klog.Warningf("Failed to remove %s: %v", socket, err)
+	}
+
+	forgetDevices(srv.devType)
+	kubeletRegistrations.forget(srv.devType)
+
 	return nil
 }
 
 // Update sends updates from Manager to ListAndWatch's event loop.
 func (srv *server) Update(devices map[string]DeviceInfo) {
-	srv.updatesCh <- devices
-}
+	observeDevices(srv.devType, devices)
 
-func (srv *server) setState(state serverState) {
-	srv.stateMutex.Lock()
-	defer srv.stateMutex.Unlock()
-	srv.state = state
+	srv.updatesCh <- devices
 }
 
 func (srv *server) getState() serverState {
@@ -258,59 +432,47 @@ func (srv *server) getState() serverState {
 func (srv *server) setupAndServe(namespace string, devicePluginPath string, kubeletSocket string) error {
 	resourceName := namespace + "/" + srv.devType
 	pluginPrefix := namespace + "-" + srv.devType
-	srv.setState(serving)
+
+	srv.stateMutex.Lock()
+	if srv.state == terminating {
+		// Stopped before it was served.
+		srv.stateMutex.Unlock()
+
+		return nil
+	}
+
+	srv.state = serving
+	srv.stateMutex.Unlock()
 
 	for srv.getState() == serving {
 		pluginEndpoint := pluginPrefix + ".sock"
 		pluginSocket := path.Join(devicePluginPath, pluginEndpoint)
 
-		if err := waitForServer(pluginSocket, time.Second); err == nil {
-			return errors.Errorf("Socket %s is already in use", pluginSocket)
+		grpcServer, err := srv.startGrpcServer(pluginSocket)
+		if err != nil || grpcServer == nil {
+			return err
 		}
-		// We don't care if the plugin's socket file doesn't exist.
-		_ = os.Remove(pluginSocket)
 
-		var lc net.ListenConfig
-
-		lis, err := lc.Listen(context.Background(), "unix", pluginSocket)
+		// The directory is watched from before the registration so that
+		// no kubelet restart goes unnoticed.
+		watcher, err := fsnotify.NewWatcher()
 		if err != nil {
-			return errors.Wrap(err, "Failed to listen to plugin socket")
-		}
-
-		srv.grpcServer = grpc.NewServer()
-		pluginapi.RegisterDevicePluginServer(srv.grpcServer, srv)
-
-		// Starts device plugin service.
-		go func() {
-			klog.V(1).Infof("Start server for %s at: %s", srv.devType, pluginSocket)
-
-			if serveErr := srv.grpcServer.Serve(lis); serveErr != nil {
-				klog.Errorf("unable to start gRPC server: %+v", serveErr)
-			}
-		}()
-
-		// Wait for the server to start
-		if err = waitForServer(pluginSocket, 10*time.Second); err != nil {
-			return err
+			return errors.Wrapf(err, "Failed to create watcher for %s", devicePluginPath)
 		}
 
-		// Register with Kubelet.
-		err = srv.registerWithKubelet(kubeletSocket, pluginEndpoint, resourceName)
-		if err != nil {
-			return err
+		if err = watcher.Add(devicePluginPath); err == nil {
+			err = srv.registerAndWatch(watcher, kubeletSocket, pluginSocket, pluginEndpoint, resourceName)
 		}
 
-		klog.V(1).Infof("Device plugin for %s registered", srv.devType)
+		_ = watcher.Close()
 
-		// Kubelet removes plugin socket when it (re)starts
-		// plugin must restart in this case
-		if err = watchFile(pluginSocket); err != nil {
-			return err
+		if err != nil {
+			return errors.Wrapf(err, "Failed to watch %s", devicePluginPath)
 		}
 
 		if srv.getState() == serving {
-			srv.grpcServer.Stop()
-			klog.V(1).Infof("Socket %s removed, restarting", pluginSocket)
+			grpcServer.Stop()
+			klog.V(1).Infof("Restarting %s", pluginSocket)
 		} else {
 			klog.V(1).Infof("Socket %s shut down", pluginSocket)
 		}
@@ -319,26 +481,135 @@ func (srv *server) setupAndServe(namespace string, devicePluginPath string, kube
 	return nil
 }
 
-func watchFile(file string) error {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return errors.Wrapf(err, "Failed to create watcher for %s", file)
+// startGrpcServer starts serving at pluginSocket. The returned server is
+// nil if the server was stopped meanwhile.
+func (srv *server) startGrpcServer(pluginSocket string) (*grpc.Server, error) {
+	if err := waitForServer(pluginSocket, time.Second); err == nil {
+		return nil, errors.Errorf("Socket %s is already in use", pluginSocket)
 	}
-	defer watcher.Close()
+	// We don't care if the plugin's socket file doesn't exist.
+	_ = os.Remove(pluginSocket)
+
+	var lc net.ListenConfig
 
-	err = watcher.Add(filepath.Dir(file))
+	lis, err := lc.Listen(context.Background(), "unix", pluginSocket)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to add %s to watcher", file)
+		return nil, errors.Wrap(err, "Failed to listen to plugin socket")
+	}
+
+	grpcServer := grpc.NewServer()
+	pluginapi.RegisterDevicePluginServer(grpcServer, srv)
+
+	srv.stateMutex.Lock()
+	srv.grpcServer = grpcServer
+	srv.socket = pluginSocket
+	stopped := srv.state == terminating
+	srv.stateMutex.Unlock()
+
+	if stopped {
+		_ = lis.Close()
+
+		return nil, nil
+	}
+
+	// Starts device plugin service.
+	go func() {
+		klog.V(1).Infof("Start server for %s at: %s", srv.devType, pluginSocket)
+
+		if serveErr := grpcServer.Serve(lis); serveErr != nil {
+			klog.Errorf("unable to start gRPC server: %+v", serveErr)
+		}
+	}()
+
+	// Wait for the server to start
+	if err = waitForServer(pluginSocket, 10*time.Second); err != nil {
+		return nil, err
 	}
 
+	return grpcServer, nil
+}
+
+// registerAndWatch registers the plugin with kubelet until the plugin needs
+// a restart or the server is stopped. Failed registrations are retried with
+// an exponential backoff and registrations not followed by a ListAndWatch
+// call from kubelet are done again.
+func (srv *server) registerAndWatch(watcher *fsnotify.Watcher, kubeletSocket, pluginSocket, pluginEndpoint, resourceName string) error {
+	backoff := srv.registerBackoff
+
 	for {
+		// Only ListAndWatch calls after the registration count.
 		select {
+		case <-srv.watched:
+		default:
+		}
+
+		timeout := srv.listAndWatchTimeout
+
+		err := srv.registerWithKubelet(kubeletSocket, pluginEndpoint, resourceName)
+		if err != nil {
+			klog.Warningf("Registration of %s failed, retrying in %v: %+v", resourceName, backoff, err)
+			kubeletRegistrations.set(srv.devType, RegistrationPending, err)
+
+			timeout = backoff
+			backoff = min(2*backoff, maxRegisterBackoff)
+		} else {
+			klog.V(1).Infof("Device plugin for %s registered", srv.devType)
+			kubeletRegistrations.set(srv.devType, RegistrationRegistered, nil)
+			registrationsTotal.WithLabelValues(srv.devType).Inc()
+
+			srv.registrations++
+			srv.events.registered(srv.devType, srv.registrations)
+
+			backoff = srv.registerBackoff
+		}
+
+		restart, watchErr := srv.waitForKubelet(watcher, kubeletSocket, pluginSocket, timeout)
+		if watchErr != nil || restart {
+			return watchErr
+		}
+
+		if err == nil {
+			klog.Warningf("Kubelet did not call ListAndWatch for %s in %v, registering again", resourceName, timeout)
+		}
+	}
+}
+
+// waitForKubelet returns true when the plugin socket is removed or the
+// kubelet socket is created, which happen when kubelet restarts, or when
+// the server is stopped. It returns false after timeout unless kubelet has
+// called ListAndWatch.
+func (srv *server) waitForKubelet(watcher *fsnotify.Watcher, kubeletSocket, pluginSocket string, timeout time.Duration) (bool, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	watched := srv.watched
+
+	for {
+		select {
+		case <-srv.done:
+			return true, nil
 		case ev := <-watcher.Events:
-			if (ev.Op == fsnotify.Remove || ev.Op == fsnotify.Rename) && ev.Name == file {
-				return nil
+			if ev.Has(fsnotify.Remove|fsnotify.Rename) && ev.Name == pluginSocket {
+				klog.V(1).Infof("Socket %s removed", pluginSocket)
+
+				return true, nil
+			}
+
+			if ev.Has(fsnotify.Create) && ev.Name == kubeletSocket {
+				klog.V(1).Infof("Kubelet socket %s created", kubeletSocket)
+
+				return true, nil
 			}
 		case err := <-watcher.Errors:
-			return errors.WithStack(err)
+			return false, errors.WithStack(err)
+		case <-watched:
+			kubeletRegistrations.set(srv.devType, RegistrationWatched, nil)
+
+			timer.Stop()
+
+			watched = nil
+		case <-timer.C:
+			return false, nil
 		}
 	}
 }
@@ -401,45 +672,62 @@ func waitForServer(socket string, timeout time.Duration) error {
 	}
 }
 
-// Writes CDI spec to filesystem if not found from the CDI cache.
-// Returns a list of CDI device names.
-func writeCdiSpecToFilesystem(spec *cdispec.Spec, cdiDir string) ([]*pluginapi.CDIDevice, error) {
-	names := make([]*pluginapi.CDIDevice, 0, 1)
+// cdiSpecFileName generates a spec filename with '/' and '=' replaced with '-'.
+func cdiSpecFileName(kind, deviceName string) string {
+	return fmt.Sprintf("%s-%s.yaml", strings.ReplaceAll(kind, "/", "-"), deviceName)
+}
 
+// Writes CDI spec to filesystem unless all its devices are found from the
+// CDI cache. Returns the fully-qualified names of the devices of the spec.
+func writeCdiSpecToFilesystem(ctx context.Context, spec *cdispec.Spec, cdiDir string) (names []*pluginapi.CDIDevice, err error) {
 	if spec == nil {
-		return names, nil
+		return []*pluginapi.CDIDevice{}, nil
 	}
 
+	_, span := startSpan(ctx, spanWriteCdiSpec, attrCdiKind.String(spec.Kind), attrDevices.Int(len(spec.Devices)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	if len(spec.Devices) == 0 {
+		return nil, os.ErrNotExist
+	}
+
+	names = make([]*pluginapi.CDIDevice, 0, len(spec.Devices))
+	missing := false
+
 	cache, err := cdi.NewCache(cdi.WithAutoRefresh(false), cdi.WithSpecDirs(cdiDir))
 	if err != nil {
 		return nil, err
 	}
 
-	// It's expected to have one device per spec
-	if len(spec.Devices) != 1 {
-		return nil, os.ErrNotExist
-	}
+	for _, dev := range spec.Devices {
+		fqName := fmt.Sprintf("%s=%s", spec.Kind, dev.Name)
+
+		names = append(names, &pluginapi.CDIDevice{Name: fqName})
 
-	deviceName := spec.Devices[0].Name
-	fqName := fmt.Sprintf("%s=%s", spec.Kind, deviceName)
+		if cache.GetDevice(fqName) == nil {
+			missing = true
+		}
+	}
 
-	names = append(names, &pluginapi.CDIDevice{Name: fqName})
+	span.SetAttributes(attrCdiWritten.Bool(missing))
 
-	// The device is found in the cache.
-	if cache.GetDevice(fqName) != nil {
+	// All the devices are found in the cache.
+	if !missing {
 		return names, nil
 	}
 
-	// Generate filename with '/' and '=' replaced with '-'.
-	specFileName := fmt.Sprintf("%s-%s.yaml", strings.ReplaceAll(spec.Kind, "/", "-"), deviceName)
+	// The spec file is named after its first device.
+	specFileName := cdiSpecFileName(spec.Kind, spec.Devices[0].Name)
 
 	// Write spec to filesystem.
-	if err := cache.WriteSpec(spec, specFileName); err != nil {
+	if err = cache.WriteSpec(spec, specFileName); err != nil {
 		return nil, err
 	}
 
 	// Fix access issues due to: https://github.com/cncf-tags/container-device-interface/issues/224
-	if err := os.Chmod(filepath.Join(cdiDir, specFileName), 0o644); err != nil {
+	if err = os.Chmod(filepath.Join(cdiDir, specFileName), 0o644); err != nil {
 		return nil, err
 	}
 
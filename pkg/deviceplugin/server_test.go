@@ -82,7 +82,11 @@ func newTestServer() *server {
 				state: pluginapi.Healthy,
 			},
 		},
-		updatesCh: make(chan map[string]DeviceInfo, 1),
+		updatesCh:           make(chan map[string]DeviceInfo, 1),
+		watched:             make(chan struct{}, 1),
+		done:                make(chan struct{}),
+		registerBackoff:     10 * time.Millisecond,
+		listAndWatchTimeout: time.Minute,
 	}
 }
 
@@ -262,6 +266,29 @@ func TestStop(t *testing.T) {
 	}
 }
 
+func TestStopCleanup(t *testing.T) {
+	srv := newTestServer()
+	srv.socket = filepath.Join(t.TempDir(), "plugin.sock")
+
+	if err := os.WriteFile(srv.socket, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.grpcServer = grpc.NewServer()
+
+	if err := srv.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(srv.socket); !os.IsNotExist(err) {
+		t.Errorf("%s not removed: %v", srv.socket, err)
+	}
+
+	if err := srv.Stop(); err != nil {
+		t.Errorf("second Stop failed: %+v", err)
+	}
+}
+
 func TestAllocate(t *testing.T) {
 	srv := newTestServer()
 
@@ -272,7 +299,7 @@ func TestAllocate(t *testing.T) {
 
 	defer os.RemoveAll(tmpRoot)
 
-	srv.cdiDir = tmpRoot
+	srv.cdiSpecs = newCdiSpecs(tmpRoot, "test")
 
 	tcases := []struct {
 		devices           map[string]DeviceInfo
@@ -590,6 +617,210 @@ func TestAllocate(t *testing.T) {
 	}
 }
 
+func TestAllocateChangedRequest(t *testing.T) {
+	srv := newTestServer()
+	srv.cdiSpecs = newCdiSpecs(t.TempDir(), "test")
+
+	for _, name := range []string{"dev1", "dev2"} {
+		srv.devices[name] = DeviceInfo{
+			state: pluginapi.Healthy,
+			nodes: []pluginapi.DeviceSpec{{HostPath: "/dev/" + name, ContainerPath: "/dev/" + name, Permissions: "rw"}},
+			envs:  map[string]string{"DEVICE": name},
+		}
+	}
+
+	// The plugin allocates another device of the same type by default.
+	srv.allocate = func(rqt *pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
+		rqt.ContainerRequests[0].DevicesIds = []string{"dev2"}
+
+		return nil, &UseDefaultMethodError{}
+	}
+
+	resp, err := srv.Allocate(context.Background(), &pluginapi.AllocateRequest{
+		ContainerRequests: []*pluginapi.ContainerAllocateRequest{{DevicesIds: []string{"dev1"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cresp := resp.ContainerResponses[0]
+	if len(cresp.Devices) != 1 || cresp.Devices[0].HostPath != "/dev/dev2" || cresp.Envs["DEVICE"] != "dev2" {
+		t.Errorf("expected the default allocation of dev2, got %+v", cresp)
+	}
+}
+
+func TestAllocateCdiDevices(t *testing.T) {
+	common := &cdispec.Spec{
+		Version: CDIVersion,
+		Kind:    CDIVendor + "/gpu",
+		Devices: []cdispec.Device{{
+			Name: "by-path",
+			ContainerEdits: cdispec.ContainerEdits{
+				Mounts: []*cdispec.Mount{{HostPath: "/dev/dri/by-path", ContainerPath: "/dev/dri/by-path", Options: []string{"bind", "ro"}}},
+			},
+		}},
+	}
+
+	gpuSpec := func(card int) *cdispec.Spec {
+		return &cdispec.Spec{
+			Version: CDIVersion,
+			Kind:    CDIVendor + "/gpu",
+			Devices: []cdispec.Device{
+				{
+					Name: fmt.Sprintf("card%d", card),
+					ContainerEdits: cdispec.ContainerEdits{
+						DeviceNodes: []*cdispec.DeviceNode{{Path: fmt.Sprintf("/dev/dri/card%d", card)}},
+					},
+				},
+				{
+					Name: fmt.Sprintf("renderD%d", 128+card),
+					ContainerEdits: cdispec.ContainerEdits{
+						DeviceNodes: []*cdispec.DeviceNode{{Path: fmt.Sprintf("/dev/dri/renderD%d", 128+card)}},
+					},
+				},
+			},
+			ContainerEdits: cdispec.ContainerEdits{
+				Env: []string{fmt.Sprintf("CARD%d=1", card)},
+			},
+		}
+	}
+
+	tcases := []struct {
+		name          string
+		ids           []string
+		expectedNames []string
+		expectedFiles []string
+	}{
+		{
+			name: "multiple CDI devices",
+			ids:  []string{"card0"},
+			expectedNames: []string{
+				CDIVendor + "/gpu=card0",
+				CDIVendor + "/gpu=renderD128",
+				CDIVendor + "/gpu=by-path",
+			},
+			expectedFiles: []string{"intel.cdi.k8s.io-gpu-card0.yaml", "intel.cdi.k8s.io-gpu-by-path.yaml"},
+		},
+		{
+			name: "shared common spec",
+			ids:  []string{"card0", "card1"},
+			expectedNames: []string{
+				CDIVendor + "/gpu=card0",
+				CDIVendor + "/gpu=renderD128",
+				CDIVendor + "/gpu=by-path",
+				CDIVendor + "/gpu=card1",
+				CDIVendor + "/gpu=renderD129",
+			},
+			expectedFiles: []string{"intel.cdi.k8s.io-gpu-card0.yaml", "intel.cdi.k8s.io-gpu-card1.yaml", "intel.cdi.k8s.io-gpu-by-path.yaml"},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			tree := NewDeviceTree()
+
+			for card := range 2 {
+				info := NewDeviceInfoWithTopologyHints(pluginapi.Healthy, nil, nil, nil, nil, nil, gpuSpec(card))
+				info.SetCdiCommonSpec(common)
+				tree.AddDevice("i915", fmt.Sprintf("card%d", card), info)
+			}
+
+			srv := newTestServer()
+			srv.devices = tree["i915"]
+			srv.cdiSpecs = newCdiSpecs(tmpDir, "gpu.intel.com")
+
+			resp, err := srv.Allocate(context.Background(), &pluginapi.AllocateRequest{
+				ContainerRequests: []*pluginapi.ContainerAllocateRequest{{DevicesIds: tc.ids}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			names := []string{}
+			for _, dev := range resp.ContainerResponses[0].CdiDevices {
+				names = append(names, dev.Name)
+			}
+
+			if !reflect.DeepEqual(names, tc.expectedNames) {
+				t.Errorf("expected CDI devices %v, got %v", tc.expectedNames, names)
+			}
+
+			for _, name := range tc.expectedFiles {
+				if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+					t.Errorf("CDI spec %s not written: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocateStaticCdi(t *testing.T) {
+	srv := newTestServer()
+	srv.devType = "vf"
+	srv.cdiSpecs = newCdiSpecs(t.TempDir(), "test.intel.com")
+	srv.cdiSpecs.staticKind = CDIVendor + "/test"
+
+	own := testCdiDevice("own")
+	own.SetCdiCommonSpec(testCdiDevice("common").cdiSpec)
+
+	srv.devices = map[string]DeviceInfo{
+		"dev1": {
+			state:       pluginapi.Healthy,
+			nodes:       []pluginapi.DeviceSpec{{HostPath: "/dev/dev1", ContainerPath: "/dev/dev1", Permissions: "rw"}},
+			mounts:      []pluginapi.Mount{{HostPath: "/mnt", ContainerPath: "/mnt"}},
+			envs:        map[string]string{"FOO": "bar"},
+			annotations: map[string]string{"foo": "bar"},
+		},
+		"dev2": own,
+	}
+
+	// PostAllocate gets the envs of the devices.
+	srv.postAllocate = func(resp *pluginapi.AllocateResponse) error {
+		envs := resp.ContainerResponses[0].Envs
+		envs["BAR"] = envs["FOO"]
+		delete(envs, "FOO")
+
+		return nil
+	}
+
+	resp, err := srv.Allocate(context.Background(), &pluginapi.AllocateRequest{
+		ContainerRequests: []*pluginapi.ContainerAllocateRequest{{DevicesIds: []string{"dev1", "dev2"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cresp := resp.ContainerResponses[0]
+
+	if len(cresp.Devices) != 0 || len(cresp.Mounts) != 0 {
+		t.Errorf("static CDI allocation should only refer to CDI devices: %+v", cresp)
+	}
+
+	if !reflect.DeepEqual(cresp.Envs, map[string]string{"BAR": "bar"}) {
+		t.Errorf("envs not post-allocated: %+v", cresp.Envs)
+	}
+
+	if cresp.Annotations["foo"] != "bar" {
+		t.Errorf("annotations missing: %+v", cresp.Annotations)
+	}
+
+	names := []string{}
+	for _, dev := range cresp.CdiDevices {
+		names = append(names, dev.Name)
+	}
+
+	expected := []string{CDIVendor + "/test=vf-dev1", CDIVendor + "/test=own", CDIVendor + "/test=common"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected CDI devices %v, got %v", expected, names)
+	}
+
+	if files, _ := os.ReadDir(srv.cdiSpecs.dir); len(files) != 0 {
+		t.Errorf("static CDI allocation should not write files, found %d", len(files))
+	}
+}
+
 // Minimal implementation of pluginapi.DevicePlugin_ListAndWatchServer.
 type listAndWatchServerStub struct {
 	cdata       chan []*pluginapi.Device
@@ -760,7 +991,7 @@ func TestGetPreferredAllocation(t *testing.T) {
 	}{
 		{
 			name: "success",
-			getPreferredAllocation: func(*pluginapi.PreferredAllocationRequest) (*pluginapi.PreferredAllocationResponse, error) {
+			getPreferredAllocation: func(*pluginapi.PreferredAllocationRequest, map[string]DeviceInfo) (*pluginapi.PreferredAllocationResponse, error) {
 				return nil, nil
 			},
 		},
@@ -785,7 +1016,7 @@ func TestGetPreferredAllocation(t *testing.T) {
 }
 
 func TestNewServer(t *testing.T) {
-	_ = newServer("test", nil, nil, nil, nil)
+	_ = newServer("test", nil, nil, nil, nil, nil, nil)
 }
 
 func TestUpdate(t *testing.T) {
@@ -0,0 +1,176 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	tracerName = "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+
+	tracingShutdownTimeout = 5 * time.Second
+
+	spanAllocate               = "deviceplugin.Allocate"
+	spanPluginAllocate         = "deviceplugin.plugin.Allocate"
+	spanPostAllocate           = "deviceplugin.plugin.PostAllocate"
+	spanPreStartContainer      = "deviceplugin.plugin.PreStartContainer"
+	spanGetPreferredAllocation = "deviceplugin.plugin.GetPreferredAllocation"
+	spanWriteCdiSpec           = "deviceplugin.WriteCDISpec"
+	spanScan                   = "deviceplugin.Scan"
+)
+
+// Attributes of the spans.
+const (
+	attrResource    = attribute.Key("deviceplugin.resource")
+	attrContainers  = attribute.Key("deviceplugin.containers")
+	attrDevices     = attribute.Key("deviceplugin.devices")
+	attrDeviceTypes = attribute.Key("deviceplugin.device_types")
+	attrCdiKind     = attribute.Key("deviceplugin.cdi.kind")
+	attrCdiWritten  = attribute.Key("deviceplugin.cdi.written")
+)
+
+var (
+	tracingEndpoint = flag.String("tracing-endpoint", "",
+		"OTLP gRPC endpoint (e.g. localhost:4317) of a collector to export the traces of the allocation path and scans to. Not exported when empty")
+	tracingFile = flag.String("tracing-file", "",
+		"file to write the traces of the allocation path and scans to as JSON for offline analysis. Not written when empty")
+)
+
+// setupTracing starts exporting the spans of the framework to an OTLP
+// collector at endpoint and to file, whichever are given. The returned
+// function flushes the pending spans and stops the exports.
+func setupTracing(ctx context.Context, serviceName, endpoint, file string) (func(), error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	}
+
+	var output *os.File
+
+	if endpoint != "" {
+		exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create OTLP trace exporter")
+		}
+
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	if file != "" {
+		var err error
+
+		output, err = os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open trace file")
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(output))
+		if err != nil {
+			_ = output.Close()
+
+			return nil, errors.Wrap(err, "failed to create file trace exporter")
+		}
+
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	klog.V(1).Infof("Exporting traces to %q %q", endpoint, file)
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := provider.Shutdown(shutdownCtx); err != nil {
+			klog.Warningf("Failed to flush traces: %+v", err)
+		}
+
+		if output != nil {
+			_ = output.Close()
+		}
+	}, nil
+}
+
+// startSpan starts a span with attrs as a child of the span in ctx.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span with err as its status.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// allocateAttributes returns the span attributes of an allocation request.
+func allocateAttributes(devType string, rqt *pluginapi.AllocateRequest) []attribute.KeyValue {
+	devices := 0
+	for _, crqt := range rqt.GetContainerRequests() {
+		devices += len(crqt.GetDevicesIds())
+	}
+
+	return []attribute.KeyValue{
+		attrResource.String(devType),
+		attrContainers.Int(len(rqt.GetContainerRequests())),
+		attrDevices.Int(devices),
+	}
+}
+
+// scanTracer is a Notifier passing the device trees to the next Notifier
+// in a span, which covers the handling of the tree by the framework. It
+// also records the scan metrics.
+type scanTracer struct {
+	next       Notifier
+	lastNotify time.Time
+}
+
+// Notify implements the Notifier interface.
+func (s *scanTracer) Notify(tree DeviceTree) {
+	now := time.Now()
+	observeScan(s.lastNotify, now)
+	s.lastNotify = now
+
+	devices := 0
+	for _, ids := range tree {
+		devices += len(ids)
+	}
+
+	_, span := startSpan(context.Background(), spanScan,
+		attrDeviceTypes.Int(len(tree)), attrDevices.Int(devices))
+	defer span.End()
+
+	s.next.Notify(tree)
+}
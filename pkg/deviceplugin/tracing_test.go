@@ -0,0 +1,199 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deviceplugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// recordSpans records the spans ended during the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+// spanSummary returns the names of the spans in the order they ended with
+// the names of their parents, attributes and failures.
+func spanSummary(spans []sdktrace.ReadOnlySpan) []string {
+	names := make(map[string]string)
+	for _, span := range spans {
+		names[span.SpanContext().SpanID().String()] = span.Name()
+	}
+
+	summary := []string{}
+
+	for _, span := range spans {
+		line := span.Name()
+
+		if parent, found := names[span.Parent().SpanID().String()]; found {
+			line = parent + " > " + line
+		}
+
+		attrs := []string{}
+		for _, attr := range span.Attributes() {
+			attrs = append(attrs, string(attr.Key)+"="+attr.Value.Emit())
+		}
+
+		line += " " + strings.Join(attrs, ",")
+
+		if span.Status().Code == codes.Error {
+			line += " failed"
+		}
+
+		summary = append(summary, line)
+	}
+
+	return summary
+}
+
+func TestAllocateSpans(t *testing.T) {
+	tcases := []struct {
+		postAllocate postAllocateFunc
+		name         string
+		expected     []string
+	}{
+		{
+			name: "allocated",
+			expected: []string{
+				"deviceplugin.Allocate > deviceplugin.plugin.Allocate deviceplugin.resource=test,deviceplugin.containers=1,deviceplugin.devices=2",
+				"deviceplugin.Allocate > deviceplugin.WriteCDISpec deviceplugin.cdi.kind=intel.cdi.k8s.io/test,deviceplugin.devices=1,deviceplugin.cdi.written=true",
+				"deviceplugin.Allocate > deviceplugin.WriteCDISpec deviceplugin.cdi.kind=intel.cdi.k8s.io/test,deviceplugin.devices=1,deviceplugin.cdi.written=true",
+				"deviceplugin.Allocate > deviceplugin.plugin.PostAllocate deviceplugin.resource=test,deviceplugin.containers=1",
+				"deviceplugin.Allocate deviceplugin.resource=test,deviceplugin.containers=1,deviceplugin.devices=2",
+			},
+		},
+		{
+			name: "post allocate failed",
+			postAllocate: func(*pluginapi.AllocateResponse) error {
+				return errors.New("no resources")
+			},
+			expected: []string{
+				"deviceplugin.Allocate > deviceplugin.plugin.Allocate deviceplugin.resource=test,deviceplugin.containers=1,deviceplugin.devices=2",
+				"deviceplugin.Allocate > deviceplugin.WriteCDISpec deviceplugin.cdi.kind=intel.cdi.k8s.io/test,deviceplugin.devices=1,deviceplugin.cdi.written=true",
+				"deviceplugin.Allocate > deviceplugin.WriteCDISpec deviceplugin.cdi.kind=intel.cdi.k8s.io/test,deviceplugin.devices=1,deviceplugin.cdi.written=true",
+				"deviceplugin.Allocate > deviceplugin.plugin.PostAllocate deviceplugin.resource=test,deviceplugin.containers=1 failed",
+				"deviceplugin.Allocate deviceplugin.resource=test,deviceplugin.containers=1,deviceplugin.devices=2 failed",
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := recordSpans(t)
+
+			postAllocate := tc.postAllocate
+			if postAllocate == nil {
+				postAllocate = func(*pluginapi.AllocateResponse) error { return nil }
+			}
+
+			srv := newServer("test", newCdiSpecs(t.TempDir(), "intel.com"), postAllocate, nil, nil,
+				func(*pluginapi.AllocateRequest) (*pluginapi.AllocateResponse, error) {
+					return nil, &UseDefaultMethodError{}
+				}, nil).(*server)
+			srv.devices = testCdiTree("dev1", "dev2")["test"]
+
+			_, _ = srv.Allocate(context.Background(), &pluginapi.AllocateRequest{
+				ContainerRequests: []*pluginapi.ContainerAllocateRequest{{DevicesIds: []string{"dev1", "dev2"}}},
+			})
+
+			if summary := spanSummary(recorder.Ended()); !reflect.DeepEqual(summary, tc.expected) {
+				t.Errorf("expected spans\n%s\ngot\n%s", strings.Join(tc.expected, "\n"), strings.Join(summary, "\n"))
+			}
+		})
+	}
+}
+
+func TestPluginSpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	srv := newServer("test", nil, nil,
+		func(*pluginapi.PreStartContainerRequest) error { return errors.New("device busy") },
+		func(*pluginapi.PreferredAllocationRequest, map[string]DeviceInfo) (*pluginapi.PreferredAllocationResponse, error) {
+			return &pluginapi.PreferredAllocationResponse{}, nil
+		}, nil, nil).(*server)
+
+	_, _ = srv.PreStartContainer(context.Background(), &pluginapi.PreStartContainerRequest{DevicesIds: []string{"dev1"}})
+	_, _ = srv.GetPreferredAllocation(context.Background(), &pluginapi.PreferredAllocationRequest{
+		ContainerRequests: []*pluginapi.ContainerPreferredAllocationRequest{{}, {}},
+	})
+
+	scan := &scanTracer{next: &treeRecorder{}}
+	scan.Notify(testCdiTree("dev1", "dev2"))
+
+	expected := []string{
+		"deviceplugin.plugin.PreStartContainer deviceplugin.resource=test,deviceplugin.devices=1 failed",
+		"deviceplugin.plugin.GetPreferredAllocation deviceplugin.resource=test,deviceplugin.containers=2",
+		"deviceplugin.Scan deviceplugin.device_types=1,deviceplugin.devices=2",
+	}
+
+	if summary := spanSummary(recorder.Ended()); !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected spans\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(summary, "\n"))
+	}
+
+	if trees := scan.next.(*treeRecorder).trees; len(trees) != 1 {
+		t.Errorf("expected the scanned tree to be passed on, got %d trees", len(trees))
+	}
+}
+
+func TestTracingFile(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	file := filepath.Join(t.TempDir(), "traces.json")
+
+	stop, err := setupTracing(context.Background(), "test-device-plugin", "", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := startSpan(context.Background(), spanAllocate, attrResource.String("test"))
+	span.End()
+
+	stop()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{spanAllocate, string(attrResource), "test-device-plugin"} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("%q not found in the trace file: %s", expected, data)
+		}
+	}
+
+	if _, err = setupTracing(context.Background(), "test-device-plugin", "", filepath.Join(file, "missing", "traces.json")); err == nil {
+		t.Error("expected an error for a trace file that can't be created")
+	}
+}
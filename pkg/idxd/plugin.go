@@ -15,6 +15,7 @@
 package idxd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -24,6 +25,8 @@ import (
 	"time"
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/uevent"
 	"github.com/pkg/errors"
 	"golang.org/x/sys/unix"
 	"k8s.io/klog/v2"
@@ -31,10 +34,8 @@ import (
 )
 
 const (
-	// Character devices directory.
-	charDevDir = "/dev/char"
-	// Frequency of device scans.
-	scanFrequency = 5 * time.Second
+	// Frequency of device rescans when no dsa uevents arrive.
+	scanFrequency = 30 * time.Second
 )
 
 // getDevNodesFunc type allows overriding filesystem APIs (os.Stat, stat.Sys, etc) in tests.
@@ -42,31 +43,30 @@ type getDevNodesFunc func(devDir, charDevDir, wqName string) ([]pluginapi.Device
 
 // DevicePlugin defines properties of the idxd device plugin.
 type DevicePlugin struct {
-	scanTicker   *time.Ticker
-	scanDone     chan bool
 	getDevNodes  getDevNodesFunc
 	statePattern string
 	devDir       string
 	charDevDir   string
+	allocation.Allocator
 	sharedDevNum int
 }
 
-// NewDevicePlugin creates DevicePlugin.
-func NewDevicePlugin(statePattern, devDir string, sharedDevNum int) *DevicePlugin {
+// NewDevicePlugin creates DevicePlugin. The device nodes of the work queues
+// are in devDir and their device numbers are resolved in charDevDir.
+func NewDevicePlugin(statePattern, devDir, charDevDir string, sharedDevNum int) *DevicePlugin {
 	return &DevicePlugin{
 		statePattern: statePattern,
 		devDir:       devDir,
 		charDevDir:   charDevDir,
 		sharedDevNum: sharedDevNum,
-		scanTicker:   time.NewTicker(scanFrequency),
-		scanDone:     make(chan bool, 1),
 		getDevNodes:  getDevNodes,
 	}
 }
 
 // Scan discovers devices and reports them to the upper level API.
-func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *DevicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
+	trigger := uevent.NewTrigger(scanFrequency, "dsa")
+	defer trigger.Stop()
 
 	for {
 		devTree, err := dp.scan()
@@ -77,9 +77,9 @@ func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
 		notifier.Notify(devTree)
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-trigger.C:
 		}
 	}
 }
@@ -15,6 +15,7 @@
 package idxd
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
@@ -64,14 +65,14 @@ func init() {
 
 // fakeNotifier implements Notifier interface.
 type fakeNotifier struct {
-	scanDone   chan bool
+	cancel     context.CancelFunc
 	deviceTree dpapi.DeviceTree
 }
 
 // Notify stops plugin Scan.
 func (n *fakeNotifier) Notify(deviceTree dpapi.DeviceTree) {
 	n.deviceTree = deviceTree
-	n.scanDone <- true
+	n.cancel()
 }
 
 type testCase struct {
@@ -223,14 +224,16 @@ func genTest(sysfs, statePattern string, tc testCase) func(t *testing.T) {
 			}
 		}
 
-		plugin := NewDevicePlugin(statePattern, "", tc.sharedDevNum)
+		plugin := NewDevicePlugin(statePattern, "", "", tc.sharedDevNum)
 		plugin.getDevNodes = getFakeDevNodes
 
+		ctx, cancel := context.WithCancel(context.Background())
+
 		notifier := &fakeNotifier{
-			scanDone: plugin.scanDone,
+			cancel: cancel,
 		}
 
-		err := plugin.Scan(notifier)
+		err := plugin.Scan(ctx, notifier)
 		if !tc.expectedError && err != nil {
 			t.Errorf("unexpected error: %+v", err)
 		}
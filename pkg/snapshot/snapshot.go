@@ -0,0 +1,496 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snapshot captures the parts of sysfs and devfs read by the device
+// plugins into a gzipped tarball and restores such snapshots into a directory.
+// A restored snapshot can be scanned by giving the directory as the prefix of
+// the sysfs and devfs paths of a plugin, e.g. with -prefix, to reproduce
+// device detection issues of a node elsewhere.
+//
+// Files, directories and symlinks are stored as they are. Device nodes are
+// stored with their device numbers and restored as device nodes when
+// permitted, otherwise as empty files.
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Larger files, e.g. PCI BARs, are not captured.
+	maxFileSize = 1 << 20
+	// Vendor of the PCI devices captured with their subdirectories.
+	intelVendor = "0x8086"
+)
+
+// DefaultPaths are the sysfs and devfs paths read by the plugins, relative
+// to the root of the captured file system. Shell patterns are allowed.
+var DefaultPaths = []string{
+	"sys/class/drm",
+	"sys/class/accel",
+	"sys/class/dlb2",
+	"sys/class/fpga",
+	"sys/class/fpga_region",
+	"sys/class/uio",
+	"sys/bus/pci/devices",
+	"sys/bus/pci/drivers",
+	"sys/bus/dsa/devices",
+	"sys/kernel/debug/qat_*",
+	"sys/kernel/debug/dri",
+	"sys/kernel/iommu_groups",
+	"sys/devices/system/node",
+	"dev/dri",
+	"dev/accel",
+	"dev/dsa",
+	"dev/iax",
+	"dev/dlb*",
+	"dev/vfio",
+	"dev/uio*",
+	"dev/sgx_enclave",
+	"dev/sgx_provision",
+	"dev/intel-fpga-*",
+	"dev/dfl-*",
+}
+
+var pciAddressRegexp = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// capturer writes the files of a snapshot to a tarball.
+type capturer struct {
+	tw *tar.Writer
+	// Added files, symlinks and device nodes.
+	added map[string]struct{}
+	// Added directories, true when added with subdirectories.
+	dirs map[string]bool
+	root string
+}
+
+// Capture writes a gzipped tarball of the files matching paths under root
+// to w. Symlinks to devices are followed: Intel PCI devices are captured
+// with all their subdirectories, the PCI devices and directories above them
+// only with their files. Unreadable files are skipped.
+func Capture(w io.Writer, root string, paths []string) error {
+	gw := gzip.NewWriter(w)
+	c := &capturer{
+		tw:    tar.NewWriter(gw),
+		root:  filepath.Clean(root),
+		added: make(map[string]struct{}),
+		dirs:  make(map[string]bool),
+	}
+
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(filepath.Join(c.root, pattern))
+		if err != nil {
+			return errors.Wrapf(err, "invalid path %s", pattern)
+		}
+
+		for _, match := range matches {
+			if err = c.add(match, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := c.tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(gw.Close())
+}
+
+// add adds path to the snapshot. Directories are added with their files and
+// symlinks, and with their subdirectories when recursive is set.
+func (c *capturer) add(path string, recursive bool) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		klog.V(4).Infof("Skipping %s: %v", path, err)
+
+		return nil
+	}
+
+	switch {
+	case fi.IsDir():
+		return c.addDir(path, fi, recursive)
+	case fi.Mode()&os.ModeSymlink != 0:
+		return c.addSymlink(path, fi)
+	case fi.Mode()&os.ModeDevice != 0:
+		return c.addDevice(path, fi)
+	case fi.Mode().IsRegular():
+		return c.addFile(path, fi)
+	}
+
+	return nil
+}
+
+func (c *capturer) header(path string, fi os.FileInfo, typeflag byte) (*tar.Header, bool) {
+	name, err := filepath.Rel(c.root, path)
+	if err != nil || strings.HasPrefix(name, "..") {
+		return nil, false
+	}
+
+	return &tar.Header{
+		Typeflag: typeflag,
+		Name:     filepath.ToSlash(name),
+		Mode:     int64(fi.Mode().Perm()),
+		ModTime:  time.Unix(0, 0),
+	}, true
+}
+
+func (c *capturer) addDir(path string, fi os.FileInfo, recursive bool) error {
+	if deep, found := c.dirs[path]; found && (deep || !recursive) {
+		return nil
+	}
+
+	if _, found := c.dirs[path]; !found {
+		hdr, ok := c.header(path, fi, tar.TypeDir)
+		if !ok {
+			return nil
+		}
+
+		hdr.Name += "/"
+
+		if err := c.tw.WriteHeader(hdr); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	c.dirs[path] = recursive
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		klog.V(4).Infof("Skipping contents of %s: %v", path, err)
+
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && !recursive {
+			continue
+		}
+
+		if err = c.add(filepath.Join(path, entry.Name()), recursive); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *capturer) addFile(path string, fi os.FileInfo) error {
+	if !c.markAdded(path) {
+		return nil
+	}
+
+	hdr, ok := c.header(path, fi, tar.TypeReg)
+	if !ok {
+		return nil
+	}
+
+	// sysfs reports the sizes of PCI BARs as the file sizes.
+	if fi.Size() > maxFileSize {
+		klog.V(4).Infof("Skipping %s: size %d", path, fi.Size())
+
+		return nil
+	}
+
+	data, err := readFile(path)
+	if err != nil {
+		// Write-only and failing attributes are common in sysfs.
+		klog.V(4).Infof("Skipping %s: %v", path, err)
+
+		return nil
+	}
+
+	hdr.Size = int64(len(data))
+
+	if err = c.tw.WriteHeader(hdr); err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = c.tw.Write(data)
+
+	return errors.WithStack(err)
+}
+
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxFileSize+1))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(data) > maxFileSize {
+		return nil, errors.Errorf("larger than %d bytes", maxFileSize)
+	}
+
+	return data, nil
+}
+
+func (c *capturer) addSymlink(path string, fi os.FileInfo) error {
+	if !c.markAdded(path) {
+		return nil
+	}
+
+	hdr, ok := c.header(path, fi, tar.TypeSymlink)
+	if !ok {
+		return nil
+	}
+
+	target, err := os.Readlink(path)
+	if err != nil {
+		klog.V(4).Infof("Skipping %s: %v", path, err)
+
+		return nil
+	}
+
+	hdr.Linkname = target
+
+	if err = c.tw.WriteHeader(hdr); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if filepath.IsAbs(target) {
+		target = filepath.Join(c.root, target)
+	} else {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+
+	return c.follow(target)
+}
+
+// addDevice adds a device node and its sysfs device.
+func (c *capturer) addDevice(path string, fi os.FileInfo) error {
+	if !c.markAdded(path) {
+		return nil
+	}
+
+	typeflag, kind := byte(tar.TypeBlock), "block"
+	if fi.Mode()&os.ModeCharDevice != 0 {
+		typeflag, kind = tar.TypeChar, "char"
+	}
+
+	hdr, ok := c.header(path, fi, typeflag)
+	if !ok {
+		return nil
+	}
+
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	hdr.Devmajor = int64(unix.Major(stat.Rdev))
+	hdr.Devminor = int64(unix.Minor(stat.Rdev))
+
+	if err := c.tw.WriteHeader(hdr); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return c.add(filepath.Join(c.root, "sys", "dev", kind, fmt.Sprintf("%d:%d", hdr.Devmajor, hdr.Devminor)), false)
+}
+
+// follow adds the target of a symlink when it's a device in sysfs.
+func (c *capturer) follow(target string) error {
+	rel, err := filepath.Rel(c.root, target)
+	if err != nil || !(strings.HasPrefix(rel, "sys/devices/pci") || strings.HasPrefix(rel, "sys/devices/virtual/")) {
+		return nil
+	}
+
+	// Capture the whole PCI device the target belongs to.
+	top := target
+	for dir := target; strings.HasPrefix(dir, filepath.Join(c.root, "sys", "devices")+"/"); dir = filepath.Dir(dir) {
+		if pciAddressRegexp.MatchString(filepath.Base(dir)) {
+			top = dir
+
+			break
+		}
+	}
+
+	devices := filepath.Join(c.root, "sys", "devices")
+
+	parents := []string{}
+	for dir := filepath.Dir(top); strings.HasPrefix(dir, devices); dir = filepath.Dir(dir) {
+		parents = append(parents, dir)
+	}
+
+	for i := len(parents) - 1; i >= 0; i-- {
+		if err = c.add(parents[i], false); err != nil {
+			return err
+		}
+	}
+
+	if !pciAddressRegexp.MatchString(filepath.Base(top)) || isIntel(top) {
+		return c.add(top, true)
+	}
+
+	// Only the files of other vendors' PCI devices, unless linked deeper.
+	if err = c.add(top, false); err != nil || top == target {
+		return err
+	}
+
+	return c.add(target, true)
+}
+
+func isIntel(pciDevice string) bool {
+	vendor, err := os.ReadFile(filepath.Join(pciDevice, "vendor"))
+
+	return err == nil && strings.TrimSpace(string(vendor)) == intelVendor
+}
+
+func (c *capturer) markAdded(path string) bool {
+	if _, found := c.added[path]; found {
+		return false
+	}
+
+	c.added[path] = struct{}{}
+
+	return true
+}
+
+// Restore extracts a snapshot written by Capture into dir. Absolute symlinks
+// are made to point into dir.
+func Restore(r io.Reader, dir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrap(err, "invalid snapshot")
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "invalid snapshot")
+		}
+
+		if err = restoreEntry(tr, hdr, dir); err != nil {
+			return err
+		}
+	}
+}
+
+func restoreEntry(tr *tar.Reader, hdr *tar.Header, dir string) error {
+	name := filepath.Clean(filepath.FromSlash(hdr.Name))
+	if !filepath.IsLocal(name) {
+		return errors.Errorf("invalid path in snapshot: %s", hdr.Name)
+	}
+
+	if err := checkParents(dir, name); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, name)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	mode := os.FileMode(hdr.Mode).Perm() | 0o600
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return errors.WithStack(os.MkdirAll(path, 0o755))
+	case tar.TypeReg:
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if _, err = io.Copy(f, io.LimitReader(tr, maxFileSize)); err != nil {
+			f.Close()
+
+			return errors.WithStack(err)
+		}
+
+		return errors.WithStack(f.Close())
+	case tar.TypeSymlink:
+		target := hdr.Linkname
+		if filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+
+		return errors.WithStack(os.Symlink(target, path))
+	case tar.TypeChar, tar.TypeBlock:
+		return restoreDevice(hdr, path, mode)
+	}
+
+	return nil
+}
+
+// checkParents fails when a parent of name in dir is a symlink. Snapshots
+// never have entries under symlinks, they could be used to write outside dir.
+func checkParents(dir, name string) error {
+	path := dir
+
+	for _, elem := range strings.Split(filepath.Dir(name), string(filepath.Separator)) {
+		path = filepath.Join(path, elem)
+
+		fi, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return errors.Errorf("%s is under a symlink", name)
+		}
+	}
+
+	return nil
+}
+
+// restoreDevice creates a device node or an empty file when that's not permitted.
+func restoreDevice(hdr *tar.Header, path string, mode os.FileMode) error {
+	devType := uint32(unix.S_IFCHR)
+	if hdr.Typeflag == tar.TypeBlock {
+		devType = unix.S_IFBLK
+	}
+
+	dev := unix.Mkdev(uint32(hdr.Devmajor), uint32(hdr.Devminor))
+	if err := unix.Mknod(path, devType|uint32(mode), int(dev)); err == nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, mode)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(f.Close())
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	gpuDir   = "sys/devices/pci0000:00/0000:00:02.0"
+	otherDir = "sys/devices/pci0000:00/0000:00:03.0"
+)
+
+func createTestRoot(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	files := map[string]string{
+		"sys/devices/pci0000:00/uevent":         "",
+		gpuDir + "/vendor":                      "0x8086\n",
+		gpuDir + "/device":                      "0x56a0\n",
+		gpuDir + "/drm/card0/dev":               "226:0\n",
+		gpuDir + "/tile0/gt0/freq":              "1000\n",
+		otherDir + "/vendor":                    "0x10de\n",
+		otherDir + "/sub/file":                  "",
+		"sys/devices/system/cpu/cpu0/online":    "1\n",
+		"sys/devices/system/node/node0/meminfo": "",
+		"dev/dri/card0":                         "",
+		"proc/cpuinfo":                          "",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		"sys/class/drm/card0":                   "../../devices/pci0000:00/0000:00:02.0/drm/card0",
+		"sys/bus/pci/devices/0000:00:03.0":      "../../../devices/pci0000:00/0000:00:03.0",
+		"sys/devices/system/node/node0/cpu0":    "../../cpu/cpu0",
+		"dev/dri/by-path/pci-0000:00:02.0-card": "../card0",
+		"dev/dri/absolute":                      "/dev/dri/card0",
+	}
+
+	for name, target := range links {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestCaptureAndRestore(t *testing.T) {
+	root := createTestRoot(t)
+
+	var buf bytes.Buffer
+	if err := Capture(&buf, root, DefaultPaths); err != nil {
+		t.Fatalf("capture failed: %+v", err)
+	}
+
+	dir := t.TempDir()
+	if err := Restore(&buf, dir); err != nil {
+		t.Fatalf("restore failed: %+v", err)
+	}
+
+	expected := map[string]bool{
+		"sys/class/drm/card0/dev":               true,
+		"sys/class/drm/card0/device/vendor":     false,
+		gpuDir + "/vendor":                      true,
+		gpuDir + "/tile0/gt0/freq":              true,
+		"sys/devices/pci0000:00/uevent":         true,
+		otherDir + "/vendor":                    true,
+		otherDir + "/sub/file":                  false,
+		"sys/devices/system/node/node0/meminfo": true,
+		"sys/devices/system/cpu/cpu0/online":    false,
+		"dev/dri/by-path/pci-0000:00:02.0-card": true,
+		"dev/dri/absolute":                      true,
+		"proc/cpuinfo":                          false,
+	}
+
+	for name, exists := range expected {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists && err != nil {
+			t.Errorf("%s not restored: %v", name, err)
+		}
+
+		if !exists && err == nil {
+			t.Errorf("%s should not be in the snapshot", name)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "sys/class/drm/card0/dev"))
+	if err != nil || string(data) != "226:0\n" {
+		t.Errorf("unexpected content %q: %v", data, err)
+	}
+
+	// Absolute symlinks point into the restored snapshot.
+	if target, _ := os.Readlink(filepath.Join(dir, "dev/dri/absolute")); target != filepath.Join(dir, "dev/dri/card0") {
+		t.Errorf("unexpected symlink target %s", target)
+	}
+}
+
+func TestCaptureDeviceNode(t *testing.T) {
+	if _, err := os.Stat("/sys/dev/char/1:3"); err != nil {
+		t.Skip("no sysfs")
+	}
+
+	var buf bytes.Buffer
+	if err := Capture(&buf, "/", []string{"dev/null"}); err != nil {
+		t.Fatalf("capture failed: %+v", err)
+	}
+
+	dir := t.TempDir()
+	if err := Restore(&buf, dir); err != nil {
+		t.Fatalf("restore failed: %+v", err)
+	}
+
+	for _, name := range []string{"dev/null", "sys/dev/char/1:3"} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not restored: %v", name, err)
+		}
+	}
+
+	// The sysfs device of the node is captured as well.
+	if _, err := os.Stat(filepath.Join(dir, "sys/dev/char/1:3/uevent")); err != nil {
+		t.Errorf("sysfs device not restored: %v", err)
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	tcases := []struct {
+		name    string
+		entries []tar.Header
+	}{
+		{
+			name:    "path outside",
+			entries: []tar.Header{{Name: "../outside", Typeflag: tar.TypeReg}},
+		},
+		{
+			name: "write through symlink",
+			entries: []tar.Header{
+				{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/.."},
+				{Name: "link/outside", Typeflag: tar.TypeReg},
+			},
+		},
+		{
+			name: "directory through symlink",
+			entries: []tar.Header{
+				{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/.."},
+				{Name: "link/dir/file", Typeflag: tar.TypeReg},
+			},
+		},
+		{
+			name: "unclean path through symlink",
+			entries: []tar.Header{
+				{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/.."},
+				{Name: "dir/../link/outside", Typeflag: tar.TypeReg},
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			gw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gw)
+
+			for i := range tc.entries {
+				if err := tw.WriteHeader(&tc.entries[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := gw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			root := t.TempDir()
+			dir := filepath.Join(root, "snapshot")
+
+			if err := Restore(&buf, dir); err == nil {
+				t.Error("expected an error")
+			}
+
+			for _, name := range []string{"outside", "dir"} {
+				if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+					t.Errorf("%s written outside of the snapshot", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package uevent watches kernel uevents so that device plugins can rescan
+// their devices as soon as devices appear, disappear or get rebound to
+// another driver instead of waiting for the next periodic scan.
+package uevent
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// Netlink multicast group of the uevents sent by the kernel. Group 2 carries
+	// the events re-broadcast by udev, which are not needed here.
+	kernelGroup = 1
+	// Large enough for any uevent message (UEVENT_BUFFER_SIZE is 2048).
+	receiveBufferSize = 8192
+	// Socket receive buffer size for absorbing bursts such as SR-IOV VF creation.
+	socketBufferSize = 1 << 20
+)
+
+var (
+	// ErrOverflow is returned by a Source when events have been lost. Subscribers
+	// should assume that anything may have changed.
+	ErrOverflow = errors.New("uevent buffer overflow")
+
+	errInvalidEvent = errors.New("invalid uevent")
+)
+
+// Event is a kernel uevent.
+type Event struct {
+	// Env holds all the KEY=value pairs of the event.
+	Env       map[string]string
+	Action    string
+	DevPath   string
+	Subsystem string
+}
+
+// Source provides kernel uevents.
+type Source interface {
+	// Receive blocks until the next event is available. It returns ErrOverflow
+	// when events have been dropped and another error when the source fails
+	// or is closed.
+	Receive() (*Event, error)
+	// Close stops the source and unblocks pending Receive calls.
+	Close() error
+}
+
+type netlinkSource struct {
+	file *os.File
+	buf  []byte
+}
+
+// NewNetlinkSource returns a Source reading kernel uevents from a netlink socket.
+func NewNetlinkSource() (Source, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create uevent socket")
+	}
+
+	if err = unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: kernelGroup}); err != nil {
+		_ = unix.Close(fd)
+
+		return nil, errors.Wrap(err, "failed to bind uevent socket")
+	}
+
+	// Best effort, the default buffer works too but overflows sooner.
+	_ = unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_RCVBUF, socketBufferSize)
+
+	// Non-blocking descriptors are handled by the runtime poller, so Close
+	// interrupts a blocked Read.
+	return &netlinkSource{
+		file: os.NewFile(uintptr(fd), "uevent"),
+		buf:  make([]byte, receiveBufferSize),
+	}, nil
+}
+
+func (s *netlinkSource) Receive() (*Event, error) {
+	for {
+		n, err := s.file.Read(s.buf)
+		if errors.Is(err, syscall.ENOBUFS) {
+			return nil, ErrOverflow
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read uevent")
+		}
+
+		if event, err := parseEvent(s.buf[:n]); err == nil {
+			return event, nil
+		}
+	}
+}
+
+func (s *netlinkSource) Close() error {
+	return errors.WithStack(s.file.Close())
+}
+
+// parseEvent parses a kernel uevent message of the form
+// "ACTION@DEVPATH\0KEY=value\0KEY=value...".
+func parseEvent(msg []byte) (*Event, error) {
+	fields := bytes.Split(bytes.TrimRight(msg, "\x00"), []byte{0})
+
+	if len(fields) < 2 || !bytes.Contains(fields[0], []byte("@")) {
+		return nil, errors.Wrapf(errInvalidEvent, "%q", fields[0])
+	}
+
+	event := &Event{
+		Env: make(map[string]string, len(fields)-1),
+	}
+
+	for _, field := range fields[1:] {
+		key, value, found := strings.Cut(string(field), "=")
+		if !found {
+			continue
+		}
+
+		event.Env[key] = value
+	}
+
+	event.Action = event.Env["ACTION"]
+	event.DevPath = event.Env["DEVPATH"]
+	event.Subsystem = event.Env["SUBSYSTEM"]
+
+	if event.Action == "" || event.Subsystem == "" {
+		return nil, errors.Wrapf(errInvalidEvent, "%q", fields[0])
+	}
+
+	return event, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uevent
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+const (
+	testResync  = time.Hour
+	testTimeout = 2 * time.Second
+)
+
+// fakeSource is a Source fed by tests.
+type fakeSource struct {
+	events chan *Event
+	errs   chan error
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		events: make(chan *Event),
+		errs:   make(chan error),
+	}
+}
+
+func (s *fakeSource) Receive() (*Event, error) {
+	select {
+	case event, ok := <-s.events:
+		if !ok {
+			return nil, io.EOF
+		}
+
+		return event, nil
+	case err := <-s.errs:
+		return nil, err
+	}
+}
+
+func (s *fakeSource) Close() error {
+	close(s.events)
+
+	return nil
+}
+
+func (s *fakeSource) send(action, subsystem string) {
+	s.events <- &Event{Action: action, Subsystem: subsystem, DevPath: "/devices/test"}
+}
+
+func newTestWatcher(t *testing.T) (*Watcher, *fakeSource) {
+	t.Helper()
+
+	source := newFakeSource()
+	w := NewWatcher(source)
+	w.settle = time.Millisecond
+
+	done := make(chan error)
+
+	go func() {
+		done <- w.Run()
+	}()
+
+	t.Cleanup(func() {
+		_ = w.Close()
+
+		if err := <-done; err == nil {
+			t.Error("expected Run to fail after Close")
+		}
+	})
+
+	return w, source
+}
+
+func expectFired(t *testing.T, trigger *Trigger, expected bool) {
+	t.Helper()
+
+	timeout := testTimeout
+	if !expected {
+		timeout = 50 * time.Millisecond
+	}
+
+	select {
+	case <-trigger.C:
+		if !expected {
+			t.Error("trigger fired unexpectedly")
+		}
+	case <-time.After(timeout):
+		if expected {
+			t.Error("trigger did not fire")
+		}
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	tcases := []struct {
+		name        string
+		msg         string
+		expectedAct string
+		expectedSub string
+		expectedDev string
+		expectedErr bool
+	}{
+		{
+			name:        "drm add",
+			msg:         "add@/devices/pci0000:00/0000:00:02.0/drm/card0\x00ACTION=add\x00DEVPATH=/devices/pci0000:00/0000:00:02.0/drm/card0\x00SUBSYSTEM=drm\x00MAJOR=226\x00MINOR=0\x00SEQNUM=1234\x00",
+			expectedAct: "add",
+			expectedSub: "drm",
+			expectedDev: "/devices/pci0000:00/0000:00:02.0/drm/card0",
+		},
+		{
+			name:        "pci bind",
+			msg:         "bind@/devices/pci0000:6b/0000:6b:00.1\x00ACTION=bind\x00DEVPATH=/devices/pci0000:6b/0000:6b:00.1\x00SUBSYSTEM=pci\x00DRIVER=vfio-pci",
+			expectedAct: "bind",
+			expectedSub: "pci",
+			expectedDev: "/devices/pci0000:6b/0000:6b:00.1",
+		},
+		{
+			name:        "udev message",
+			msg:         "libudev\x00\xfe\xed\xca\xfe",
+			expectedErr: true,
+		},
+		{
+			name:        "missing subsystem",
+			msg:         "add@/devices/foo\x00ACTION=add\x00DEVPATH=/devices/foo",
+			expectedErr: true,
+		},
+		{
+			name:        "empty",
+			msg:         "",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := parseEvent([]byte(tc.msg))
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", event)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+
+			if event.Action != tc.expectedAct || event.Subsystem != tc.expectedSub || event.DevPath != tc.expectedDev {
+				t.Errorf("unexpected event: %+v", event)
+			}
+		})
+	}
+}
+
+func TestTriggerSubsystems(t *testing.T) {
+	w, source := newTestWatcher(t)
+
+	drm := w.NewTrigger(testResync, "drm")
+	defer drm.Stop()
+
+	pci := w.NewTrigger(testResync, "pci", "dsa")
+	defer pci.Stop()
+
+	all := w.NewTrigger(testResync)
+	defer all.Stop()
+
+	source.send("add", "drm")
+
+	expectFired(t, drm, true)
+	expectFired(t, all, true)
+	expectFired(t, pci, false)
+
+	source.send("bind", "pci")
+
+	expectFired(t, pci, true)
+	expectFired(t, all, true)
+	expectFired(t, drm, false)
+}
+
+func TestTriggerCoalesces(t *testing.T) {
+	w, source := newTestWatcher(t)
+	w.settle = 100 * time.Millisecond
+
+	trigger := w.NewTrigger(testResync, "pci")
+	defer trigger.Stop()
+
+	for range 16 {
+		source.send("add", "pci")
+	}
+
+	expectFired(t, trigger, true)
+	expectFired(t, trigger, false)
+}
+
+func TestTriggerOverflow(t *testing.T) {
+	w, source := newTestWatcher(t)
+
+	trigger := w.NewTrigger(testResync, "accel")
+	defer trigger.Stop()
+
+	source.errs <- ErrOverflow
+
+	expectFired(t, trigger, true)
+}
+
+func TestTriggerStop(t *testing.T) {
+	w, source := newTestWatcher(t)
+
+	trigger := w.NewTrigger(testResync, "dlb2")
+	trigger.Stop()
+	trigger.Stop()
+
+	source.send("add", "dlb2")
+
+	expectFired(t, trigger, false)
+
+	if len(w.triggers) != 0 {
+		t.Errorf("stopped trigger still subscribed")
+	}
+}
+
+func TestTriggerResync(t *testing.T) {
+	var w *Watcher
+
+	trigger := w.NewTrigger(10 * time.Millisecond)
+	defer trigger.Stop()
+
+	expectFired(t, trigger, true)
+	expectFired(t, trigger, true)
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Intel Corporation. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uevent
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultSettleDelay is how long a Trigger waits after the first matching
+	// event before firing, so that bursts of events (e.g. SR-IOV VF creation)
+	// result in a single scan.
+	DefaultSettleDelay = 200 * time.Millisecond
+)
+
+var (
+	defaultOnce    sync.Once
+	defaultWatcher *Watcher
+)
+
+// Watcher reads uevents from a Source and dispatches them to Triggers.
+type Watcher struct {
+	source   Source
+	triggers map[*Trigger]struct{}
+	settle   time.Duration
+	mutex    sync.Mutex
+}
+
+// NewWatcher creates a Watcher for the given source. Run must be called
+// for the events to be dispatched.
+func NewWatcher(source Source) *Watcher {
+	return &Watcher{
+		source:   source,
+		triggers: make(map[*Trigger]struct{}),
+		settle:   DefaultSettleDelay,
+	}
+}
+
+// Default returns the process wide Watcher of kernel uevents, starting it
+// on the first call. It returns nil if uevents can't be received, in which
+// case Triggers fall back to periodic scans.
+func Default() *Watcher {
+	defaultOnce.Do(func() {
+		source, err := NewNetlinkSource()
+		if err != nil {
+			klog.Warningf("Kernel uevents not available, devices are only rescanned periodically: %+v", err)
+
+			return
+		}
+
+		defaultWatcher = NewWatcher(source)
+
+		go func() {
+			if err := defaultWatcher.Run(); err != nil {
+				klog.Errorf("Kernel uevent watcher stopped, devices are only rescanned periodically: %+v", err)
+			}
+		}()
+	})
+
+	return defaultWatcher
+}
+
+// Run dispatches events until the source fails or is closed.
+func (w *Watcher) Run() error {
+	for {
+		event, err := w.source.Receive()
+		if errors.Is(err, ErrOverflow) {
+			klog.V(2).Info("Kernel uevents lost, triggering all rescans")
+			w.dispatch(nil)
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		klog.V(5).Infof("uevent: %s %s (%s)", event.Action, event.DevPath, event.Subsystem)
+		w.dispatch(event)
+	}
+}
+
+// Close closes the event source, which stops Run.
+func (w *Watcher) Close() error {
+	return w.source.Close()
+}
+
+// dispatch pokes the triggers subscribed to the event's subsystem, or all
+// triggers if event is nil.
+func (w *Watcher) dispatch(event *Event) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for t := range w.triggers {
+		if event == nil || t.matches(event) {
+			t.poke()
+		}
+	}
+}
+
+func (w *Watcher) subscribe(t *Trigger) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.triggers[t] = struct{}{}
+}
+
+func (w *Watcher) unsubscribe(t *Trigger) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	delete(w.triggers, t)
+}
+
+// Trigger tells a Scanner when to rescan its devices: shortly after a uevent
+// of one of the subscribed subsystems and, as a safety net, periodically.
+type Trigger struct {
+	// C receives a value when a rescan is due. Triggers coalesce while the
+	// previous one has not been received.
+	C <-chan struct{}
+
+	c          chan struct{}
+	events     chan struct{}
+	stop       chan struct{}
+	watcher    *Watcher
+	subsystems []string
+	resync     time.Duration
+	settle     time.Duration
+	stopOnce   sync.Once
+}
+
+// NewTrigger creates a Trigger on the Default watcher. It fires after uevents
+// of the given subsystems, or of any subsystem if none are given, and every
+// resync period.
+func NewTrigger(resync time.Duration, subsystems ...string) *Trigger {
+	return Default().NewTrigger(resync, subsystems...)
+}
+
+// NewTrigger creates a Trigger subscribed to the Watcher. A nil Watcher
+// creates a Trigger that only fires periodically.
+func (w *Watcher) NewTrigger(resync time.Duration, subsystems ...string) *Trigger {
+	c := make(chan struct{}, 1)
+	t := &Trigger{
+		C:          c,
+		c:          c,
+		events:     make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		watcher:    w,
+		subsystems: subsystems,
+		resync:     resync,
+		settle:     DefaultSettleDelay,
+	}
+
+	if w != nil {
+		t.settle = w.settle
+		w.subscribe(t)
+	}
+
+	go t.run()
+
+	return t
+}
+
+// Stop unsubscribes the Trigger and stops its periodic resyncs.
+func (t *Trigger) Stop() {
+	t.stopOnce.Do(func() {
+		if t.watcher != nil {
+			t.watcher.unsubscribe(t)
+		}
+
+		close(t.stop)
+	})
+}
+
+func (t *Trigger) matches(event *Event) bool {
+	return len(t.subsystems) == 0 || slices.Contains(t.subsystems, event.Subsystem)
+}
+
+func (t *Trigger) poke() {
+	select {
+	case t.events <- struct{}{}:
+	default:
+	}
+}
+
+func (t *Trigger) fire() {
+	select {
+	case t.c <- struct{}{}:
+	default:
+	}
+}
+
+func (t *Trigger) run() {
+	ticker := time.NewTicker(t.resync)
+	defer ticker.Stop()
+
+	var settled <-chan time.Time
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.fire()
+		case <-t.events:
+			if settled == nil {
+				settled = time.After(t.settle)
+			}
+		case <-settled:
+			settled = nil
+
+			t.fire()
+			ticker.Reset(t.resync)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package vfio
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -23,6 +24,8 @@ import (
 	"time"
 
 	dpapi "github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/deviceplugin/allocation"
+	"github.com/intel/intel-device-plugins-for-kubernetes/pkg/uevent"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	pluginapi "k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
@@ -32,17 +35,16 @@ const (
 	// VFIO devices directory and control device path.
 	vfioPath     = "/dev/vfio"
 	vfioCtrlPath = "/dev/vfio/vfio"
-	// Frequency of device scans.
-	scanFrequency = 15 * time.Second
+	// Frequency of device rescans when no pci uevents arrive.
+	scanFrequency = 60 * time.Second
 	envVarPrefix  = "VFIO_BDF"
 )
 
 // DevicePlugin defines properties of the vfio device plugin.
 type DevicePlugin struct {
-	scanTicker *time.Ticker
-	scanDone   chan bool
-	devIDs     DeviceIDSet
-	devDir     string
+	devIDs DeviceIDSet
+	devDir string
+	allocation.Allocator
 }
 
 type DeviceIDSet map[string]struct{}
@@ -50,16 +52,15 @@ type DeviceIDSet map[string]struct{}
 // NewDevicePlugin creates DevicePlugin.
 func NewDevicePlugin(devDir string, devIDs DeviceIDSet) *DevicePlugin {
 	return &DevicePlugin{
-		devDir:     devDir,
-		devIDs:     devIDs,
-		scanTicker: time.NewTicker(scanFrequency),
-		scanDone:   make(chan bool, 1),
+		devDir: devDir,
+		devIDs: devIDs,
 	}
 }
 
 // Scan discovers devices and reports them to the upper level API.
-func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
-	defer dp.scanTicker.Stop()
+func (dp *DevicePlugin) Scan(ctx context.Context, notifier dpapi.Notifier) error {
+	trigger := uevent.NewTrigger(scanFrequency, "pci")
+	defer trigger.Stop()
 
 	for {
 		devTree, err := dp.scan()
@@ -70,9 +71,9 @@ func (dp *DevicePlugin) Scan(notifier dpapi.Notifier) error {
 		notifier.Notify(devTree)
 
 		select {
-		case <-dp.scanDone:
+		case <-ctx.Done():
 			return nil
-		case <-dp.scanTicker.C:
+		case <-trigger.C:
 		}
 	}
 }
@@ -174,8 +175,17 @@ func (dp *DevicePlugin) scan() (dpapi.DeviceTree, error) {
 			fmt.Sprintf("%s%d", envVarPrefix, devNum): bdf,
 		}
 
+		devInfo := dpapi.NewDeviceInfo(pluginapi.Healthy, devNodes, nil, envs, nil, nil)
+		devInfo.SetAttribute(dpapi.AttributePCIAddress, dpapi.StringAttribute(bdf))
+		devInfo.SetAttribute(dpapi.AttributePCIDeviceID, dpapi.StringAttribute(devID))
+		devInfo.SetAttribute(dpapi.AttributeDriver, dpapi.StringAttribute("vfio-pci"))
+
+		if pf, err := filepath.EvalSymlinks(filepath.Join(dpath, "physfn")); err == nil {
+			devInfo.SetAttribute(dpapi.AttributePhysicalFunction, dpapi.StringAttribute(filepath.Base(pf)))
+		}
+
 		klog.V(4).Infof("%s (ID=%s): nodes: %+v", bdf, devID, devNodes)
-		devTree.AddDevice("vfio", bdf, dpapi.NewDeviceInfo(pluginapi.Healthy, devNodes, nil, envs, nil, nil))
+		devTree.AddDevice("vfio", bdf, devInfo)
 	}
 
 	return devTree, nil
@@ -15,6 +15,7 @@
 package vfio
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path"
@@ -64,14 +65,14 @@ func createTestFiles(prefix string, dirs []string, files map[string][]byte, syml
 
 // fakeNotifier implements Notifier interface.
 type fakeNotifier struct {
-	scanDone chan bool
-	tree     dpapi.DeviceTree
+	cancel context.CancelFunc
+	tree   dpapi.DeviceTree
 }
 
 // Notify stops plugin Scan.
 func (n *fakeNotifier) Notify(newDeviceTree dpapi.DeviceTree) {
 	n.tree = newDeviceTree
-	n.scanDone <- true
+	n.cancel()
 }
 
 func TestScan(t *testing.T) {
@@ -207,11 +208,13 @@ func TestScan(t *testing.T) {
 				tt.deviceIDSet,
 			)
 
+			ctx, cancel := context.WithCancel(context.Background())
+
 			fN := fakeNotifier{
-				scanDone: dp.scanDone,
+				cancel: cancel,
 			}
 
-			err = dp.Scan(&fN)
+			err = dp.Scan(ctx, &fN)
 
 			if tt.expectedErr && err == nil {
 				t.Errorf("expected error, but got success")